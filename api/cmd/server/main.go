@@ -2,15 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/container"
+	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/tlscert"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/handler"
 	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
 )
 
@@ -18,6 +25,10 @@ import (
 type Server struct {
 	httpServer *http.Server
 	container  *container.Container
+	inFlight   *middleware.InFlightTracker
+	certs      *tlscert.Reloader // TLS 無効時は nil
+	// background はシャットダウン時に完了を待つバックグラウンド処理
+	background sync.WaitGroup
 }
 
 // NewServer は新しいサーバーインスタンスを作成する
@@ -31,8 +42,19 @@ func NewServer() (*Server, error) {
 	// HTTP サーバーを作成
 	server := &Server{
 		container: cont,
+		inFlight:  middleware.NewInFlightTracker(),
 	}
 
+	// TLS 証明書を読み込む（起動時に証明書と秘密鍵の組み合わせを検証する）
+	certs, err := newCertReloader(cont.GetConfig().TLSCertFile, cont.GetConfig().TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	server.certs = certs
+
+	// 非同期登録のワーカーを開始（停止時はキューに残ったドキュメントを送信してから終了する）
+	cont.GetAsyncIndexer().Start(&server.background)
+
 	// ルートとミドルウェアを設定
 	server.setupServer()
 
@@ -51,48 +73,269 @@ func (s *Server) setupServer() {
 	handler := s.setupMiddleware(mux)
 
 	// HTTP サーバーを設定
-	port := s.container.GetConfig().Port
+	config := s.container.GetConfig()
+	port := config.Port
 	if port == "" {
 		port = "8080" // デフォルトポート
 	}
 
 	s.httpServer = &http.Server{
-		Addr:         ":" + port,
-		Handler:      handler,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		Protocols:         serverProtocols(config.HTTP2Enabled, config.H2CEnabled),
+	}
+	if s.certs != nil {
+		s.httpServer.TLSConfig = s.certs.TLSConfig()
 	}
 }
 
+// newCertReloader は TLS 証明書を読み込む（どちらも未指定の場合は TLS を無効として nil を返す）
+func newCertReloader(certFile, keyFile string) (*tlscert.Reloader, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	return tlscert.NewReloader(certFile, keyFile)
+}
+
+// serverProtocols はサーバーが受け付けるプロトコルを返す
+// HTTP/2 でもタイムアウトはストリーム（リクエスト）単位で適用され、ミドルウェアチェーンは HTTP/1.1 と共通
+func serverProtocols(http2Enabled, h2cEnabled bool) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(http2Enabled)
+	protocols.SetUnencryptedHTTP2(h2cEnabled)
+	return protocols
+}
+
 // setupRoutes は全てのアプリケーションルートを設定する
 func (s *Server) setupRoutes(mux *http.ServeMux) {
+	config := s.container.GetConfig()
+	routes := newRouteTable(mux)
+
+	// ルートグループごとのリクエストタイムアウト（検索とドキュメント操作は短く、一括登録とエクスポートは長く）
+	standard := routes.Group(middleware.RequestTimeoutMiddleware(config.RequestTimeout))
+	documents := routes.Group(middleware.RequestTimeoutMiddleware(config.DocumentRequestTimeout))
+	search := routes.Group(middleware.RequestTimeoutMiddleware(config.SearchRequestTimeout))
+	long := routes.Group(middleware.RequestTimeoutMiddleware(config.LongRequestTimeout))
+
 	// コンテナからハンドラーを取得
 	documentHandler := s.container.GetDocumentHandler()
 	searchHandler := s.container.GetSearchHandler()
 	healthHandler := s.container.GetHealthHandler()
+	infoHandler := s.container.GetInfoHandler()
+	indexHandler := s.container.GetIndexHandler()
+	metricsHandler := s.container.GetMetricsHandler()
+	cacheHandler := s.container.GetCacheHandler()
 
 	// ドキュメントルート
-	mux.HandleFunc("POST /documents", documentHandler.CreateDocument)
-	mux.HandleFunc("GET /documents/{index}/{id}", documentHandler.GetDocument)
-	mux.HandleFunc("PUT /documents/{index}/{id}", documentHandler.UpdateDocument)
-	mux.HandleFunc("DELETE /documents/{index}/{id}", documentHandler.DeleteDocument)
-	mux.HandleFunc("OPTIONS /documents", documentHandler.OptionsHandler)
-	mux.HandleFunc("OPTIONS /documents/{index}/{id}", documentHandler.OptionsHandler)
+	documents.HandleFunc("POST /documents", documentHandler.CreateDocument)
+	long.HandleFunc("POST /documents/_bulk", documentHandler.BulkIndexDocuments)
+	documents.HandleFunc("GET /documents/{index}/{id}", documentHandler.GetDocument)
+	documents.HandleFunc("PUT /documents/{index}/{id}", documentHandler.UpdateDocument)
+	documents.HandleFunc("DELETE /documents/{index}/{id}", documentHandler.DeleteDocument)
+	documents.HandleFunc("POST /documents/{index}/{id}/_diff", documentHandler.DiffDocument)
+	documents.HandleFunc("OPTIONS /documents", documentHandler.OptionsHandler)
+	long.HandleFunc("OPTIONS /documents/_bulk", documentHandler.OptionsHandler)
+	search.HandleFunc("GET /documents/{index}", searchHandler.ListDocuments)
+	search.HandleFunc("GET /documents/{index}/_terms", searchHandler.CountByField)
+	search.HandleFunc("OPTIONS /documents/{index}/_terms", searchHandler.OptionsHandler)
+	search.HandleFunc("OPTIONS /documents/{index}", documentHandler.OptionsHandler)
+	documents.HandleFunc("OPTIONS /documents/{index}/{id}", documentHandler.OptionsHandler)
+	documents.HandleFunc("OPTIONS /documents/{index}/{id}/_diff", documentHandler.OptionsHandler)
 
 	// 検索ルート
-	mux.HandleFunc("GET /search", searchHandler.Search)
-	mux.HandleFunc("POST /search", searchHandler.AdvancedSearch)
-	mux.HandleFunc("OPTIONS /search", searchHandler.OptionsHandler)
+	search.HandleFunc("GET /search", searchHandler.Search)
+	search.HandleFunc("POST /search", searchHandler.AdvancedSearch)
+	search.HandleFunc("OPTIONS /search", searchHandler.OptionsHandler)
+	search.HandleFunc("GET /search/suggest", searchHandler.Suggest)
+	search.HandleFunc("OPTIONS /search/suggest", searchHandler.OptionsHandler)
+	search.HandleFunc("POST /search/more_like_this", searchHandler.MoreLikeThis)
+	search.HandleFunc("OPTIONS /search/more_like_this", searchHandler.OptionsHandler)
+	search.HandleFunc("POST /search/_validate", searchHandler.ValidateQuery)
+	search.HandleFunc("OPTIONS /search/_validate", searchHandler.OptionsHandler)
+
+	// デバッグ用のクエリ表示ルート（オプトイン。ADMIN_TOKEN 設定時は管理者のみ）
+	if config.DebugRenderQuery {
+		search.HandleFunc("POST /search/_render", requireAdmin(config.AdminToken, searchHandler.RenderQuery))
+		search.HandleFunc("OPTIONS /search/_render", searchHandler.OptionsHandler)
+	}
+
+	// インデックスルート
+	standard.HandleFunc("GET /indices/{index}/_stats", indexHandler.GetIndexStats)
+	standard.HandleFunc("OPTIONS /indices/{index}/_stats", indexHandler.OptionsHandler)
+	long.HandleFunc("GET /indices/{index}/_export", requireAdmin(config.AdminToken, indexHandler.ExportDocuments))
+	long.HandleFunc("OPTIONS /indices/{index}/_export", indexHandler.OptionsHandler)
+
+	// 管理者用ルート（ADMIN_TOKEN 設定時のみ、Bearerトークンで保護）
+	if token := config.AdminToken; token != "" {
+		adminOnly := middleware.AdminAuthMiddleware(token)
+		standard.HandleFunc("POST /indices", adminOnly(http.HandlerFunc(indexHandler.CreateIndex)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /indices", indexHandler.OptionsHandler)
+		standard.HandleFunc("POST /indices/{index}/_open", adminOnly(http.HandlerFunc(indexHandler.OpenIndex)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /indices/{index}/_open", indexHandler.OptionsHandler)
+		standard.HandleFunc("POST /indices/{index}/_close", adminOnly(http.HandlerFunc(indexHandler.CloseIndex)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /indices/{index}/_close", indexHandler.OptionsHandler)
+		standard.HandleFunc("POST /indices/{index}/_forcemerge", adminOnly(http.HandlerFunc(indexHandler.ForceMerge)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /indices/{index}/_forcemerge", indexHandler.OptionsHandler)
+		standard.HandleFunc("POST /indices/{index}/_refresh", adminOnly(http.HandlerFunc(indexHandler.RefreshIndex)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /indices/{index}/_refresh", indexHandler.OptionsHandler)
+		standard.HandleFunc("DELETE /indices/{index}", adminOnly(http.HandlerFunc(indexHandler.DeleteIndex)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /indices/{index}", indexHandler.OptionsHandler)
+
+		// プロセス内キャッシュの削除
+		standard.HandleFunc("POST /admin/cache/clear", adminOnly(http.HandlerFunc(cacheHandler.ClearCaches)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /admin/cache/clear", cacheHandler.OptionsHandler)
+		standard.HandleFunc("POST /admin/cache/clear/{index}", adminOnly(http.HandlerFunc(cacheHandler.ClearCaches)).ServeHTTP)
+		standard.HandleFunc("OPTIONS /admin/cache/clear/{index}", cacheHandler.OptionsHandler)
+	}
 
 	// ヘルスルート
-	mux.HandleFunc("GET /health", healthHandler.HealthCheck)
-	mux.HandleFunc("OPTIONS /health", healthHandler.OptionsHandler)
+	standard.HandleFunc("GET /health", healthHandler.HealthCheck)
+	standard.HandleFunc("OPTIONS /health", healthHandler.OptionsHandler)
+
+	// 情報ルート（クラスターとビルドの情報を含むため、ADMIN_TOKEN 設定時は管理者のみ）
+	standard.HandleFunc("GET /info", requireAdmin(config.AdminToken, infoHandler.Info))
+	standard.HandleFunc("OPTIONS /info", infoHandler.OptionsHandler)
+
+	// メトリクスルート
+	standard.HandleFunc("GET /metrics", metricsHandler.Metrics)
+	standard.HandleFunc("OPTIONS /metrics", metricsHandler.OptionsHandler)
+
+	// 既知のパスへの未対応メソッドには405を返す
+	routes.registerMethodNotAllowed()
+
+	// どのルートにも一致しないリクエストには JSON の404を返す
+	mux.HandleFunc("/", handler.NotFound)
+}
+
+// requireAdmin は ADMIN_TOKEN が設定されている場合に管理者認証を要求するハンドラーを返す
+// 未設定の場合は h をそのまま返し、ルートは公開される
+func requireAdmin(token string, h http.HandlerFunc) http.HandlerFunc {
+	if token == "" {
+		return h
+	}
+	return middleware.AdminAuthMiddleware(token)(h).ServeHTTP
+}
+
+// routeTable はルートを登録し、パスごとに許可されたメソッドを記録する
+type routeTable struct {
+	mux     *http.ServeMux
+	methods map[string][]string
+	paths   []string
+}
+
+// newRouteTable は新しい routeTable を作成する
+func newRouteTable(mux *http.ServeMux) *routeTable {
+	return &routeTable{
+		mux:     mux,
+		methods: make(map[string][]string),
+	}
+}
+
+// HandleFunc は "METHOD /path" 形式のパターンでハンドラーを登録する
+func (t *routeTable) HandleFunc(pattern string, h http.HandlerFunc) {
+	t.mux.HandleFunc(pattern, h)
+
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		return
+	}
+	if _, exists := t.methods[path]; !exists {
+		t.paths = append(t.paths, path)
+	}
+	t.methods[path] = append(t.methods[path], method)
+	if method == http.MethodGet {
+		// ServeMux は GET パターンで HEAD も処理する
+		t.methods[path] = append(t.methods[path], http.MethodHead)
+	}
+}
+
+// routeGroup は共通のミドルウェア（タイムアウトなど）を適用してルートを登録する
+type routeGroup struct {
+	table      *routeTable
+	middleware func(http.Handler) http.Handler
+}
+
+// Group は middleware を適用してルートを登録するグループを返す
+func (t *routeTable) Group(middleware func(http.Handler) http.Handler) *routeGroup {
+	return &routeGroup{table: t, middleware: middleware}
+}
+
+// HandleFunc は "METHOD /path" 形式のパターンで、グループのミドルウェアを適用したハンドラーを登録する
+func (g *routeGroup) HandleFunc(pattern string, h http.HandlerFunc) {
+	g.table.HandleFunc(pattern, g.middleware(h).ServeHTTP)
+}
+
+// fallbackMethods は405のフォールバックを登録する標準メソッド
+var fallbackMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// registerMethodNotAllowed は登録済みの各パスに、未対応の標準メソッドごとのフォールバックを登録する
+// メソッド指定なしのパターンは "GET /documents/{index}" のような他のパスのワイルドカードと
+// 競合するため、メソッドごとに登録する（標準外のメソッドは404のフォールバックに到達する）
+func (t *routeTable) registerMethodNotAllowed() {
+	for _, path := range t.paths {
+		allowed := t.methods[path]
+		sort.Strings(allowed)
+		notAllowed := handler.MethodNotAllowed(allowed)
+		for _, method := range fallbackMethods {
+			if !slices.Contains(allowed, method) {
+				t.mux.HandleFunc(method+" "+path, notAllowed)
+			}
+		}
+	}
 }
 
 // setupMiddleware はミドルウェアチェーンを設定する
 func (s *Server) setupMiddleware(handler http.Handler) http.Handler {
 	logger := s.container.GetLogger()
+	config := s.container.GetConfig()
+
+	// ボディログ設定（センシティブフィールドは常にマスク）
+	bodyLogConfig := middleware.DefaultBodyLogConfig()
+	bodyLogConfig.Enabled = config.DebugBodyLogging
+	bodyLogConfig.AllowDebugHeader = config.DebugBodyHeader
+	bodyLogConfig.MaxBodySize = config.DebugBodyMaxSize
+	bodyLogConfig.Routes = config.DebugBodyRoutes
+	bodyLogConfig.RedactFields = append(bodyLogConfig.RedactFields, config.DebugBodyRedactExtra...)
+	bodyLogConfig.LogHeaders = config.DebugBodyHeaders
+	bodyLogConfig.RedactHeaders = config.LogRedactHeaders
+
+	// gzipリクエストの展開（無効時は何もしない）
+	decompression := func(next http.Handler) http.Handler { return next }
+	if config.RequestDecompression {
+		decompression = middleware.RequestDecompressionMiddleware(config.RequestMaxDecompressedSize)
+	}
+
+	// レスポンス圧縮（このビルドで利用できないエンコーディングは無視される）
+	compressionConfig := middleware.DefaultCompressionConfig()
+	compressionConfig.Encodings = config.CompressionEncodings
+	compressionConfig.MinSize = config.CompressionMinSize
+
+	// 信頼済みの呼び出し元の判定（APIキー未設定時は何もしない）
+	trustedCaller := func(next http.Handler) http.Handler { return next }
+	if len(config.TrustedAPIKeys) > 0 {
+		trustedCaller = middleware.TrustedCallerMiddleware(config.TrustedAPIKeys)
+	}
+
+	// テナントの解決（マルチテナンシー無効時は何もしない）
+	tenant := func(next http.Handler) http.Handler { return next }
+	if config.TenancyEnabled {
+		tenant = middleware.TenantMiddleware(middleware.TenantConfig{
+			Header:      config.TenantHeader,
+			APIKeys:     config.TenantAPIKeys,
+			TrustHeader: config.TenantHeaderTrusted,
+		})
+	}
 
 	// ミドルウェアチェーンを作成
 	middlewares := []func(http.Handler) http.Handler{
@@ -105,29 +348,58 @@ func (s *Server) setupMiddleware(handler http.Handler) http.Handler {
 		// セキュリティミドルウェア
 		middleware.SecurityMiddleware(middleware.DefaultSecurityConfig()),
 
-		// リクエストサイズ制限（10MB）
-		middleware.RequestSizeLimitMiddleware(10 * 1024 * 1024),
+		// リクエストサイズ制限（デフォルト10MB）
+		middleware.RequestSizeLimitMiddleware(config.RequestMaxBodySize),
+
+		// gzipリクエストの展開（圧縮後のサイズ制限の後に配置）
+		decompression,
 
 		// レート制限
 		middleware.SimpleRateLimitMiddleware(middleware.DefaultRateLimitConfig()),
 
-		// リクエストタイムアウト（30秒）
-		middleware.RequestTimeoutMiddleware(30),
+		// 信頼済みの呼び出し元の判定（検索のサニタイズと許可リストを緩和する）
+		trustedCaller,
 
 		// ログミドルウェア（リカバリー後、ビジネスロジック前に配置）
 		middleware.StructuredLogMiddleware(logger),
 
+		// 処理中リクエストの追跡（シャットダウン時のログ用、リクエストID付与後に配置）
+		s.inFlight.Middleware,
+
 		// エラーログミドルウェア
 		middleware.ErrorLogMiddleware(logger),
 
-		// 圧縮ミドルウェア
-		middleware.CompressionMiddleware,
+		// テナントの解決（テナントを解決できないリクエストは403で拒否する）
+		tenant,
+
+		// ボディログミドルウェア（オプトイン）
+		middleware.BodyLogMiddleware(logger, bodyLogConfig),
+
+		// 圧縮ミドルウェア（Accept-Encoding に応じて br または gzip で圧縮する）
+		middleware.CompressionMiddlewareWithConfig(compressionConfig),
+
+		// JSONの整形出力（?pretty=true または X-Pretty ヘッダー。ハンドラーの直前に配置）
+		middleware.PrettyJSONMiddleware,
 	}
 
 	// ミドルウェアチェーンを適用
 	return middleware.ChainMiddleware(middlewares...)(handler)
 }
 
+// compressionEncodings はレスポンス圧縮で実際に使うエンコーディングを表示用に返す
+func compressionEncodings(configured []string) string {
+	var available []string
+	for _, encoding := range configured {
+		if middleware.AvailableEncoding(encoding) {
+			available = append(available, encoding)
+		}
+	}
+	if len(available) == 0 {
+		return "disabled"
+	}
+	return strings.Join(available, ", ")
+}
+
 // Start は HTTP サーバーを開始する
 func (s *Server) Start() error {
 	logger := s.container.GetLogger()
@@ -136,10 +408,20 @@ func (s *Server) Start() error {
 	config := s.container.GetConfig()
 	logger.Printf("Starting server on port %s", s.httpServer.Addr)
 	logger.Printf("Environment: %s", config.Environment)
+	logger.Printf("Protocols: %s", s.httpServer.Protocols)
 	logger.Printf("Elasticsearch URL: %s", config.ElasticsearchURL)
-
-	// サーバーを開始
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	logger.Printf("Response compression: %s", compressionEncodings(config.CompressionEncodings))
+	logger.Printf("Elasticsearch compression: requests=%t, responses=%t", config.ElasticsearchCompressRequests, config.ElasticsearchCompressResponses)
+
+	// サーバーを開始（証明書は TLSConfig から取得するためファイル名は渡さない）
+	var err error
+	if s.certs != nil {
+		logger.Printf("TLS enabled with certificate %s", config.TLSCertFile)
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 
@@ -152,19 +434,83 @@ func (s *Server) Stop(ctx context.Context) error {
 	logger.Println("Shutting down server...")
 
 	// HTTP サーバーをシャットダウン
+	var shutdownErr error
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown server: %w", err)
+		// タイムアウトまでに完了しなかったリクエストを記録してから強制終了する
+		s.logInFlightRequests()
+		if closeErr := s.httpServer.Close(); closeErr != nil {
+			logger.Printf("Failed to force close server: %v", closeErr)
+		}
+		shutdownErr = fmt.Errorf("failed to shutdown server: %w", err)
+	}
+
+	// 新しいリクエストが来なくなってから、キューに残ったドキュメントを送信する
+	// シャットダウンに失敗した場合も受け付けを停止し、ctx の残り時間で送信して失われた件数を記録する
+	if err := s.drainBackground(ctx); err != nil {
+		return errors.Join(shutdownErr, err)
 	}
 
 	// コンテナリソースをクリーンアップ
 	if err := s.container.Cleanup(); err != nil {
-		return fmt.Errorf("failed to cleanup container: %w", err)
+		return errors.Join(shutdownErr, fmt.Errorf("failed to cleanup container: %w", err))
+	}
+	if shutdownErr != nil {
+		return shutdownErr
 	}
 
 	logger.Println("Server stopped successfully")
 	return nil
 }
 
+// drainBackground は非同期登録の受け付けを停止し、キューが空になるまで待つ
+// ctx の期限までに完了しなかった場合は、送信できなかった件数を記録してエラーを返す
+func (s *Server) drainBackground(ctx context.Context) error {
+	indexer := s.container.GetAsyncIndexer()
+	indexer.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// キューに残ったものと送信中のバッチの両方が失われる
+		stats := indexer.Stats()
+		lost := stats.Enqueued - stats.Indexed - stats.Failed
+		s.container.GetLogger().Printf("Async indexer did not drain before shutdown timeout: %d documents were not indexed (%d still queued)", lost, stats.QueueDepth)
+		return fmt.Errorf("failed to drain async indexer: %w", ctx.Err())
+	}
+}
+
+// ReloadCertificates は TLS 証明書をファイルから読み込み直す（TLS 無効時は何もしない）
+// 失敗した場合は以前の証明書で待ち受けを続ける
+func (s *Server) ReloadCertificates() {
+	if s.certs == nil {
+		return
+	}
+	logger := s.container.GetLogger()
+	if err := s.certs.Reload(); err != nil {
+		logger.Printf("Failed to reload TLS certificate: %v", err)
+		return
+	}
+	logger.Println("TLS certificate reloaded")
+}
+
+// logInFlightRequests はシャットダウン時に処理中だったリクエストをログ出力する
+func (s *Server) logInFlightRequests() {
+	logger := s.container.GetLogger()
+	requests := s.inFlight.Snapshot()
+	logger.Printf("Shutdown timed out with %d request(s) still in flight", len(requests))
+	for _, req := range requests {
+		logger.Printf("In-flight request: id=%s method=%s path=%s elapsed=%s",
+			req.RequestID, req.Method, req.Path, time.Since(req.StartedAt).Round(time.Millisecond))
+	}
+}
+
 // main はアプリケーションのエントリーポイント
 func main() {
 	// サーバーを作成
@@ -184,7 +530,7 @@ func main() {
 		log.Println("Received shutdown signal")
 
 		// タイムアウト付きのシャットダウンコンテキストを作成
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), server.container.GetConfig().ShutdownTimeout)
 		defer cancel()
 
 		// サーバーを停止
@@ -196,6 +542,15 @@ func main() {
 		os.Exit(0)
 	}()
 
+	// SIGHUP で TLS 証明書を再読み込みする
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			server.ReloadCertificates()
+		}
+	}()
+
 	// サーバーを開始
 	log.Println("Starting Elasticsearch API server...")
 	if err := server.Start(); err != nil {
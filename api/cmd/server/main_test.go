@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+)
+
+func TestRouteGroupsApplyTheirTimeouts(t *testing.T) {
+	mux := http.NewServeMux()
+	routes := newRouteTable(mux)
+	search := routes.Group(middleware.RequestTimeoutMiddleware(10 * time.Second))
+	long := routes.Group(middleware.RequestTimeoutMiddleware(5 * time.Minute))
+
+	remaining := func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Remaining", time.Until(deadline).Round(time.Second).String())
+	}
+	search.HandleFunc("GET /search", remaining)
+	long.HandleFunc("POST /documents/_bulk", remaining)
+	routes.registerMethodNotAllowed()
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		want       string
+	}{
+		{method: http.MethodGet, path: "/search", wantStatus: http.StatusOK, want: "10s"},
+		{method: http.MethodPost, path: "/documents/_bulk", wantStatus: http.StatusOK, want: "5m0s"},
+		{method: http.MethodDelete, path: "/search", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("X-Remaining"); got != tt.want {
+				t.Errorf("remaining time = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "no token configured", wantStatus: http.StatusOK},
+		{name: "missing credentials", token: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "admin token", token: "secret", authorization: "Bearer secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/indices/articles/_export", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			requireAdmin(tt.token, ok)(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FieldBoosts はインデックスごとのフィールドブースト設定を表す（インデックス名 → フィールド名 → ブースト値）
+// 環境変数では "articles:title^3,summary^2;products:name^2" の形式で指定する
+type FieldBoosts map[string]map[string]float64
+
+// UnmarshalText は環境変数の値を解析する。ブースト値は正の数でなければならない
+func (b *FieldBoosts) UnmarshalText(text []byte) error {
+	boosts := FieldBoosts{}
+	for entry := range strings.SplitSeq(string(text), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		index, fields, ok := strings.Cut(entry, ":")
+		index = strings.TrimSpace(index)
+		if !ok || index == "" {
+			return fmt.Errorf("invalid field boost entry %q: expected index:field^boost,...", entry)
+		}
+
+		indexBoosts := map[string]float64{}
+		for field := range strings.SplitSeq(fields, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(field), "^")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return fmt.Errorf("invalid field boost %q for index %s: expected field^boost", field, index)
+			}
+			boost, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || math.IsNaN(boost) || math.IsInf(boost, 0) || boost <= 0 {
+				return fmt.Errorf("invalid boost for %s.%s: must be a positive number", index, name)
+			}
+			indexBoosts[name] = boost
+		}
+		boosts[index] = indexBoosts
+	}
+
+	*b = boosts
+	return nil
+}
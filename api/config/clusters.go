@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClusterEndpoints は追加のElasticsearchクラスターごとのエンドポイントを表す（クラスター名 → URL の一覧）
+// 環境変数では "replica=http://es-replica:9200|http://es-replica-2:9200;dr=http://es-dr:9200" の形式で指定する
+type ClusterEndpoints map[string][]string
+
+// UnmarshalText は環境変数の値を解析する。クラスター名ごとに1つ以上のURLが必要
+func (c *ClusterEndpoints) UnmarshalText(text []byte) error {
+	clusters := ClusterEndpoints{}
+	for entry := range strings.SplitSeq(string(text), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, urls, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid cluster entry %q: expected name=url|url...", entry)
+		}
+		if _, exists := clusters[name]; exists {
+			return fmt.Errorf("duplicate cluster %s", name)
+		}
+
+		var addresses []string
+		for url := range strings.SplitSeq(urls, "|") {
+			if url = strings.TrimSpace(url); url != "" {
+				addresses = append(addresses, url)
+			}
+		}
+		if len(addresses) == 0 {
+			return fmt.Errorf("cluster %s has no URLs", name)
+		}
+		clusters[name] = addresses
+	}
+
+	*c = clusters
+	return nil
+}
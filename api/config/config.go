@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
@@ -10,6 +11,189 @@ type Config struct {
 	Port             string `env:"PORT" envDefault:"8080"`
 	Environment      string `env:"ENVIRONMENT" envDefault:"development"`
 	ElasticsearchURL string `env:"ELASTICSEARCH_URL" envDefault:"http://localhost:9200"`
+
+	// 監視対象の追加クラスター（レプリカクラスターなど）。リクエストは ELASTICSEARCH_URL のクラスター（primary）のみが処理し、
+	// 追加クラスターはヘルスチェックで状態を報告する（例: "replica=http://es-replica:9200"）
+	ElasticsearchClusters ClusterEndpoints `env:"ELASTICSEARCH_CLUSTERS"`
+
+	// Elasticsearchのレスポンスの数値を json.Number として解析する（2^53 を超える整数の精度を保つ）
+	ElasticsearchUseNumber bool `env:"ELASTICSEARCH_USE_NUMBER" envDefault:"true"`
+
+	// Elasticsearchへのリクエストボディを gzip で圧縮する（一括登録や大きな検索の転送量を減らす代わりにCPUを使う）
+	ElasticsearchCompressRequests bool `env:"ELASTICSEARCH_COMPRESS_REQUESTS" envDefault:"false"`
+	// Elasticsearchに gzip で圧縮したレスポンスを要求する（Accept-Encoding: gzip。展開は自動で行われる）
+	ElasticsearchCompressResponses bool `env:"ELASTICSEARCH_COMPRESS_RESPONSES" envDefault:"true"`
+
+	// ログレベル（"debug"、"info"、"warn"、"error"）。これより低いレベルのログは出力しない
+	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+
+	// HTTPサーバーのタイムアウト設定（"30s" や "2m" などの期間形式）
+	ReadTimeout       time.Duration `env:"READ_TIMEOUT" envDefault:"30s"`
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" envDefault:"10s"`
+	WriteTimeout      time.Duration `env:"WRITE_TIMEOUT" envDefault:"30s"`
+	IdleTimeout       time.Duration `env:"IDLE_TIMEOUT" envDefault:"120s"`
+
+	// HTTP/2 設定（HTTP2_ENABLED は TLS 接続での HTTP/2、H2C_ENABLED は平文での HTTP/2 (h2c)）
+	// h2c はリバースプロキシや gRPC-gateway などの内部通信向けで、HTTP/1.1 のクライアントも引き続き受け付ける
+	HTTP2Enabled bool `env:"HTTP2_ENABLED" envDefault:"true"`
+	H2CEnabled   bool `env:"H2C_ENABLED" envDefault:"false"`
+
+	// TLS 設定（両方を指定した場合は HTTPS で待ち受ける。SIGHUP で証明書を再読み込みする）
+	TLSCertFile string `env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE"`
+
+	// ルートグループごとのリクエストタイムアウト（Elasticsearch への処理を含むリクエスト全体の期限）
+	// 検索とドキュメント操作は短く、一括登録やエクスポートは長くする。その他のルートは REQUEST_TIMEOUT
+	// 期限に合わせて接続の読み書きの期限も変更するため、READ_TIMEOUT/WRITE_TIMEOUT より長い値も有効
+	RequestTimeout         time.Duration `env:"REQUEST_TIMEOUT" envDefault:"30s"`
+	SearchRequestTimeout   time.Duration `env:"SEARCH_REQUEST_TIMEOUT" envDefault:"10s"`
+	DocumentRequestTimeout time.Duration `env:"DOCUMENT_REQUEST_TIMEOUT" envDefault:"10s"`
+	LongRequestTimeout     time.Duration `env:"LONG_REQUEST_TIMEOUT" envDefault:"5m"`
+
+	// グレースフルシャットダウンで処理中のリクエストの完了を待つ最大時間
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+
+	// リクエストでインデックスが省略された場合に検索・ドキュメント作成で使用するインデックス
+	DefaultIndex string `env:"DEFAULT_INDEX"`
+
+	// 検索設定
+	SearchDefaultSize     int      `env:"SEARCH_DEFAULT_SIZE" envDefault:"10"`
+	SearchMaxSize         int      `env:"SEARCH_MAX_SIZE" envDefault:"1000"`
+	SearchDefaultOperator string   `env:"SEARCH_DEFAULT_OPERATOR" envDefault:"or"`
+	SearchQueryFields     []string `env:"SEARCH_QUERY_FIELDS" envSeparator:"," envDefault:"*"`
+
+	// 公開トラフィックの検索語から取り除く文字（全モード）と、query_string / simple_query_string でエスケープする文字
+	// 例: SEARCH_QUERY_STRIP_CHARS="<>"、SEARCH_QUERY_ESCAPE_CHARS="*?"（ワイルドカードを文字として扱う）
+	SearchQueryStripChars  string `env:"SEARCH_QUERY_STRIP_CHARS"`
+	SearchQueryEscapeChars string `env:"SEARCH_QUERY_ESCAPE_CHARS"`
+
+	// リクエストの analyzer で指定できるアナライザー（カスタムアナライザーを使う場合は追加する。空の場合は指定を受け付けない）
+	SearchAllowedAnalyzers []string `env:"SEARCH_ALLOWED_ANALYZERS" envSeparator:"," envDefault:"standard,simple,whitespace,keyword"`
+
+	// インデックスごとのフィールドブースト（例: "articles:title^3,summary^2;products:name^2"）
+	// リクエストで fields を指定しない検索に適用する
+	SearchFieldBoosts FieldBoosts `env:"SEARCH_FIELD_BOOSTS"`
+
+	// インデックスごとのデフォルトソート（例: "logs:@timestamp:desc;articles:date:desc,_score:desc"）
+	// リクエストで sort を指定しない検索に適用する（未設定のインデックスはスコア順）
+	SearchDefaultSorts DefaultSorts `env:"SEARCH_DEFAULT_SORTS"`
+
+	// 全ての検索のソートの最後に追加する一意な値のフィールド（doc_values のある keyword など）
+	// 設定した場合のみ、任意のソートの検索で next_cursor を発行する（未設定の場合は sort が _id で終わる検索のみ）
+	SearchCursorTiebreaker string `env:"SEARCH_CURSOR_TIEBREAKER"`
+
+	// インデックスの max_result_window をキャッシュする期間（0の場合は検索のたびに取得する）
+	SearchResultWindowCacheTTL time.Duration `env:"SEARCH_RESULT_WINDOW_CACHE_TTL" envDefault:"1m"`
+
+	// 単一インデックスの検索の前にインデックスの存在を確認し、存在しなければ404を返す（複数インデックス・ワイルドカードは対象外）
+	SearchCheckIndexExists    bool          `env:"SEARCH_CHECK_INDEX_EXISTS" envDefault:"false"`
+	SearchIndexExistsCacheTTL time.Duration `env:"SEARCH_INDEX_EXISTS_CACHE_TTL" envDefault:"1m"`
+
+	// フィルターとソートのフィールド名をマッピングに対して検証する方法（"off"、"lenient" は警告ログのみ、"strict" は400）
+	SearchFieldValidation      string        `env:"SEARCH_FIELD_VALIDATION" envDefault:"lenient"`
+	SearchFieldMappingCacheTTL time.Duration `env:"SEARCH_FIELD_MAPPING_CACHE_TTL" envDefault:"1m"`
+
+	// 検索結果をキャッシュする期間（0の場合はキャッシュしない）と、キャッシュする検索結果の上限
+	// 期間内はドキュメントの更新が検索結果に反映されない
+	SearchResultCacheTTL        time.Duration `env:"SEARCH_RESULT_CACHE_TTL" envDefault:"0s"`
+	SearchResultCacheMaxEntries int           `env:"SEARCH_RESULT_CACHE_MAX_ENTRIES" envDefault:"1000"`
+
+	// GET /search のCache-Control max-age（0の場合は毎回ETagで再検証させる）
+	SearchCacheMaxAge time.Duration `env:"SEARCH_CACHE_MAX_AGE" envDefault:"0s"`
+
+	// preference 未指定の検索に呼び出し元ごとのセッション値を使い、ページ送りの間の順序の揺れを防ぐ
+	SearchSessionPreference bool `env:"SEARCH_SESSION_PREFERENCE" envDefault:"true"`
+
+	// gzip圧縮されたリクエストボディを展開する（展開後のサイズ上限はバイト単位）
+	RequestDecompression       bool  `env:"REQUEST_DECOMPRESSION" envDefault:"true"`
+	RequestMaxDecompressedSize int64 `env:"REQUEST_MAX_DECOMPRESSED_SIZE" envDefault:"52428800"`
+
+	// インデックスごとのデフォルトインジェストパイプライン（例: "logs:geoip,access:grok"）
+	IngestPipelines map[string]string `env:"INGEST_PIPELINES" envSeparator:"," envKeyValSeparator:":"`
+
+	// レスポンス圧縮で使うエンコーディング（優先順。"br" は brotli ビルドタグ付きでビルドした場合のみ有効）
+	// 利用できるエンコーディングがない場合（例: "identity"）は圧縮しない
+	CompressionEncodings []string `env:"COMPRESSION_ENCODINGS" envSeparator:"," envDefault:"br,gzip"`
+	// これより小さいレスポンスボディ（バイト）は圧縮しない
+	CompressionMinSize int `env:"COMPRESSION_MIN_SIZE" envDefault:"1024"`
+
+	// ボディログ設定（DEBUG_BODY_HEADER は X-Debug-Body: true ヘッダーを付けたリクエストのみ記録する。信頼済みの呼び出し元のみ有効）
+	DebugBodyLogging     bool     `env:"DEBUG_BODY_LOGGING" envDefault:"false"`
+	DebugBodyHeader      bool     `env:"DEBUG_BODY_HEADER" envDefault:"false"`
+	DebugBodyMaxSize     int      `env:"DEBUG_BODY_MAX_SIZE" envDefault:"4096"`
+	DebugBodyRoutes      []string `env:"DEBUG_BODY_ROUTES" envSeparator:","`
+	DebugBodyRedactExtra []string `env:"DEBUG_BODY_REDACT_FIELDS" envSeparator:","`
+	// ボディと合わせてヘッダーも記録する（Authorization や X-API-Key などの値は常にマスク）
+	DebugBodyHeaders bool `env:"DEBUG_BODY_HEADERS" envDefault:"false"`
+	// マスクするヘッダーの追加分（デフォルトの機密ヘッダーは常にマスクされる）
+	LogRedactHeaders []string `env:"LOG_REDACT_HEADERS" envSeparator:","`
+
+	// 一括登録を分割送信する際の1リクエストあたりの上限（件数と推定バイト数）
+	BulkChunkMaxDocs  int   `env:"BULK_CHUNK_MAX_DOCS" envDefault:"1000"`
+	BulkChunkMaxBytes int64 `env:"BULK_CHUNK_MAX_BYTES" envDefault:"10485760"`
+	// 分割したリクエストを並行して送信する最大数（1の場合は順に送信する）
+	BulkConcurrency int `env:"BULK_CONCURRENCY" envDefault:"1"`
+
+	// 部分更新（upsert）が同時更新で競合した際にElasticsearch側で再試行する回数のデフォルト
+	UpdateRetryOnConflict int `env:"UPDATE_RETRY_ON_CONFLICT" envDefault:"3"`
+
+	// 書き込み後のリフレッシュの方法のデフォルト（true / false / wait_for。リクエストの refresh パラメータで上書きできる）
+	// 単一ドキュメントの書き込みはすぐに検索へ反映し、一括登録はリフレッシュを強制しない
+	WriteRefresh string `env:"WRITE_REFRESH" envDefault:"true"`
+	BulkRefresh  string `env:"BULK_REFRESH" envDefault:"false"`
+
+	// Idempotency-Key ヘッダー付きのドキュメント作成の結果を保持する期間（0の場合はヘッダーを無視する）と
+	// 保持するキー数の上限（超えた場合は古いものから破棄する。0の場合は上限なし）
+	IdempotencyKeyTTL  time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"1h"`
+	IdempotencyMaxKeys int           `env:"IDEMPOTENCY_MAX_KEYS" envDefault:"10000"`
+
+	// 非同期登録（POST /documents?async=true）のキューの上限と、バルク送信する件数・間隔
+	// キューが満杯の場合は503を返す。シャットダウン時はキューに残ったドキュメントを送信してから停止する
+	AsyncIndexQueueSize     int           `env:"ASYNC_INDEX_QUEUE_SIZE" envDefault:"10000"`
+	AsyncIndexBatchSize     int           `env:"ASYNC_INDEX_BATCH_SIZE" envDefault:"500"`
+	AsyncIndexFlushInterval time.Duration `env:"ASYNC_INDEX_FLUSH_INTERVAL" envDefault:"1s"`
+
+	// 変更操作の監査ログ（1行1件のJSON。ファイル未指定の場合は標準出力）
+	// ドキュメントの内容は AUDIT_LOG_INCLUDE_SOURCE を有効にした場合のみ記録する
+	AuditLogEnabled       bool   `env:"AUDIT_LOG_ENABLED" envDefault:"false"`
+	AuditLogFile          string `env:"AUDIT_LOG_FILE"`
+	AuditLogIncludeSource bool   `env:"AUDIT_LOG_INCLUDE_SOURCE" envDefault:"false"`
+
+	// 管理者用エンドポイント（インデックスのオープン/クローズなど）のBearerトークン
+	// 未設定の場合、管理者用エンドポイントは登録されない
+	AdminToken string `env:"ADMIN_TOKEN"`
+
+	// 信頼済みの呼び出し元（内部サービスなど）に発行するAPIキー（X-API-Key ヘッダーで送信）
+	// 一致したリクエストは検索クエリのサニタイズとソートフィールドの許可リストが適用されない
+	TrustedAPIKeys []string `env:"TRUSTED_API_KEYS" envSeparator:","`
+
+	// マルチテナンシー（有効な場合、ドキュメント・検索・インデックス操作のインデックス名にテナントのプレフィックスを付与する）
+	// テナントは認証済みのコンテキスト、なければ X-API-Key ヘッダーのテナント用APIキーから取得し、解決できないリクエストは403で拒否する
+	// TENANT_HEADER のヘッダーは解決したテナントと一致する必要があり、異なるテナントを指定したリクエストは403で拒否する
+	TenancyEnabled bool   `env:"TENANCY_ENABLED" envDefault:"false"`
+	TenantHeader   string `env:"TENANT_HEADER" envDefault:"X-Tenant-ID"`
+	// テナントごとに発行するAPIキー（例: key1:acme,key2:globex）
+	TenantAPIKeys map[string]string `env:"TENANT_API_KEYS" envSeparator:"," envKeyValSeparator:":"`
+	// true の場合はヘッダーのみでテナントを選択できる（呼び出し元を認証してヘッダーを上書きするゲートウェイの背後でのみ有効にする）
+	// マルチテナンシー有効時は TENANT_API_KEYS かこの設定のどちらかが必要
+	TenantHeaderTrusted bool `env:"TENANT_HEADER_TRUSTED" envDefault:"false"`
+	// インデックス名のプレフィックス形式（{tenant} をテナントIDに置き換える。直後には区切り文字が必要）
+	TenantIndexPrefix string `env:"TENANT_INDEX_PREFIX" envDefault:"{tenant}-"`
+
+	// POST /search/_render（構築したクエリを返すデバッグ用エンドポイント）を有効にする
+	DebugRenderQuery bool `env:"DEBUG_RENDER_QUERY" envDefault:"false"`
+
+	// ヘルスチェック設定
+	RequiredIndices []string `env:"REQUIRED_INDICES" envSeparator:","`
+	// 必須インデックスを正常とみなす最低のヘルス（"green"、またはレプリカ未割り当てを許容する "yellow"）
+	RequiredIndicesMinStatus string `env:"REQUIRED_INDICES_MIN_STATUS" envDefault:"green"`
+	// ヘルスチェック結果をキャッシュする期間（0の場合は毎回Elasticsearchに問い合わせる）
+	HealthCacheTTL time.Duration `env:"HEALTH_CACHE_TTL" envDefault:"2s"`
+
+	// リクエストボディ解析設定（RequestMaxBodySize はリクエストサイズの上限で、展開後のJSONボディの解析にも適用する）
+	RequestMaxDepth              int   `env:"REQUEST_MAX_DEPTH" envDefault:"32"`
+	RequestDisallowUnknownFields bool  `env:"REQUEST_DISALLOW_UNKNOWN_FIELDS" envDefault:"false"`
+	RequestMaxBodySize           int64 `env:"REQUEST_MAX_BODY_SIZE" envDefault:"10485760"`
 }
 
 func NewConfig() *Config {
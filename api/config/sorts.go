@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortSpec はソートフィールドと順序の組を表す
+type SortSpec struct {
+	Field string
+	Order string // "asc" または "desc"
+}
+
+// DefaultSorts はインデックスごとのデフォルトソート設定を表す（インデックス名 → ソート順）
+// 環境変数では "logs:@timestamp:desc;articles:date:desc,_score:desc" の形式で指定する
+type DefaultSorts map[string][]SortSpec
+
+// UnmarshalText は環境変数の値を解析する。順序は asc または desc でなければならない
+func (d *DefaultSorts) UnmarshalText(text []byte) error {
+	sorts := DefaultSorts{}
+	for entry := range strings.SplitSeq(string(text), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		index, fields, ok := strings.Cut(entry, ":")
+		index = strings.TrimSpace(index)
+		if !ok || index == "" {
+			return fmt.Errorf("invalid default sort entry %q: expected index:field:order,...", entry)
+		}
+
+		var specs []SortSpec
+		for field := range strings.SplitSeq(fields, ",") {
+			name, order, ok := strings.Cut(strings.TrimSpace(field), ":")
+			name = strings.TrimSpace(name)
+			order = strings.ToLower(strings.TrimSpace(order))
+			if !ok || name == "" {
+				return fmt.Errorf("invalid default sort %q for index %s: expected field:order", field, index)
+			}
+			if order != "asc" && order != "desc" {
+				return fmt.Errorf("invalid sort order for %s.%s: must be asc or desc", index, name)
+			}
+			specs = append(specs, SortSpec{Field: name, Order: order})
+		}
+		sorts[index] = specs
+	}
+
+	*d = sorts
+	return nil
+}
@@ -3,6 +3,7 @@ module github.com/Yuki-TU/elastic-search/api
 go 1.24
 
 require (
+	github.com/andybalholm/brotli v1.2.6
 	github.com/caarlos0/env/v11 v11.3.1
 	github.com/elastic/go-elasticsearch/v9 v9.0.0
 )
@@ -1,37 +1,177 @@
 package dto
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 )
 
 // CreateDocumentRequest はドキュメント作成リクエストを表す
 type CreateDocumentRequest struct {
-	Index  string         `json:"index" binding:"required"`
-	ID     string         `json:"id,omitempty"`
-	Source map[string]any `json:"source" binding:"required"`
+	Index    string         `json:"index,omitempty"` // 省略時はデフォルトインデックス
+	ID       string         `json:"id,omitempty"`
+	Source   map[string]any `json:"source" binding:"required"`
+	Routing  string         `json:"routing,omitempty"`
+	Pipeline string         `json:"pipeline,omitempty"` // 省略時はインデックスのデフォルトパイプライン
+	Refresh  string         `json:"-"`                  // ?refresh= から設定（省略時はサーバーのデフォルト）
+}
+
+// CreateRawDocumentRequest は未加工のJSONによるドキュメント作成リクエストを表す
+// Source はリクエストボディのドキュメントそのもので、その他の値はクエリパラメータから設定する
+type CreateRawDocumentRequest struct {
+	Index    string          // 省略時はデフォルトインデックス
+	Source   json.RawMessage // map に変換せずにそのまま Elasticsearch に送信する
+	Routing  string
+	Pipeline string // 省略時はインデックスのデフォルトパイプライン
+	Refresh  string // 省略時はサーバーのデフォルト
 }
 
 // UpdateDocumentRequest はドキュメント更新リクエストを表す
 type UpdateDocumentRequest struct {
-	Index  string         `json:"index" binding:"required"`
-	ID     string         `json:"id" binding:"required"`
-	Source map[string]any `json:"source" binding:"required"`
+	Index   string         `json:"index" binding:"required"`
+	ID      string         `json:"id" binding:"required"`
+	Source  map[string]any `json:"source" binding:"required"`
+	Routing string         `json:"routing,omitempty"`
+	Version int64          `json:"version,omitempty"` // 外部バージョン（指定時は version_type=external で書き込む）
+	// RetryOnConflict は部分更新（upsert）の競合時の再試行回数（未指定時はサーバーのデフォルト）
+	RetryOnConflict *int `json:"retry_on_conflict,omitempty"`
+	// Refresh は書き込み後のリフレッシュの方法（?refresh= から設定。省略時はサーバーのデフォルト）
+	Refresh string `json:"-"`
+}
+
+// DiffDocumentRequest はドキュメントの差分プレビューリクエストを表す
+type DiffDocumentRequest struct {
+	Index   string         `json:"index" binding:"required"`
+	ID      string         `json:"id" binding:"required"`
+	Source  map[string]any `json:"source" binding:"required"` // 保存しようとしているソース
+	Routing string         `json:"routing,omitempty"`
 }
 
 // DeleteDocumentRequest はドキュメント削除リクエストを表す
 type DeleteDocumentRequest struct {
-	Index string `json:"index" binding:"required"`
-	ID    string `json:"id" binding:"required"`
+	Index   string `json:"index" binding:"required"`
+	ID      string `json:"id" binding:"required"`
+	Routing string `json:"routing,omitempty"`
+	// IfSeqNo と IfPrimaryTerm を指定した場合、ドキュメントがその版のままのときのみ削除する（If-Match ヘッダーから設定）
+	IfSeqNo       *int64 `json:"-"`
+	IfPrimaryTerm *int64 `json:"-"`
+	// Refresh は削除後のリフレッシュの方法（?refresh= から設定。省略時はサーバーのデフォルト）
+	Refresh string `json:"-"`
 }
 
 // SearchRequest は検索リクエストを表す
 type SearchRequest struct {
-	Query   string            `json:"query" binding:"required"`
-	Index   string            `json:"index,omitempty"`
-	Filters map[string]string `json:"filters,omitempty"`
-	From    int               `json:"from,omitempty"`
-	Size    int               `json:"size,omitempty"`
-	Sort    []SortFieldDTO    `json:"sort,omitempty"`
+	Query            string            `json:"query" binding:"required"`
+	Mode             string            `json:"mode,omitempty"` // "match"、"query_string" または "simple_query_string"
+	Fields           []string          `json:"fields,omitempty"`
+	DefaultOperator  string            `json:"default_operator,omitempty"` // "and" または "or"
+	Analyzer         string            `json:"analyzer,omitempty"`         // 検索語の解析に使用するアナライザー（許可されたもののみ）
+	Index            string            `json:"index,omitempty"`            // カンマ区切りの複数指定やワイルドカード（例: "logs-*"）も可
+	Filters          map[string]string `json:"filters,omitempty"`
+	From             int               `json:"from,omitempty"`
+	Size             int               `json:"size,omitempty"`
+	Sort             []SortFieldDTO    `json:"sort,omitempty"`
+	Cursor           string            `json:"cursor,omitempty"` // 前ページのレスポンスの next_cursor（同じ index と sort でのみ有効。from と併用不可）
+	MinScore         float64           `json:"min_score,omitempty"`
+	Collapse         *CollapseDTO      `json:"collapse,omitempty"`
+	Nested           *NestedQueryDTO   `json:"nested,omitempty"`
+	FunctionScore    *FunctionScoreDTO `json:"function_score,omitempty"`
+	Source           *bool             `json:"source,omitempty"`  // false の場合はソースを返さず、インデックス・ID・スコアのみを返す（省略時は true）
+	Profile          bool              `json:"profile,omitempty"` // true の場合は処理時間の内訳を返す（信頼済みの呼び出し元のみ）
+	Highlight        *HighlightDTO     `json:"highlight,omitempty"`
+	StoredFields     []string          `json:"stored_fields,omitempty"`       // _source 以外に保存されたフィールド（store: true）を取得する
+	DocValueFields   []string          `json:"docvalue_fields,omitempty"`     // doc values からフィールドの値を取得する
+	Explain          bool              `json:"explain,omitempty"`             // true の場合はヒットごとにスコアの計算過程を返す（レスポンスが大きくなる）
+	Debug            bool              `json:"debug,omitempty"`               // true の場合はサービスが適用したビジネスルールを applied_rules で返す
+	SeqNoPrimaryTerm bool              `json:"seq_no_primary_term,omitempty"` // true の場合はヒットごとに seq_no と primary_term を返す（条件付き更新用）
+
+	// シャードの選択（同じ値を指定した検索は同じシャードコピーで実行され、ページ間で結果が安定する）
+	Preference string `json:"preference,omitempty"`
+	Routing    string `json:"routing,omitempty"` // 指定したルーティング値のドキュメントを持つシャードのみを検索する
+
+	// インデックスの解決方法（複数インデックスやワイルドカード指定時に使用）
+	IgnoreUnavailable bool   `json:"ignore_unavailable,omitempty"` // 存在しない・クローズ済みのインデックスを無視する
+	AllowNoIndices    *bool  `json:"allow_no_indices,omitempty"`   // ワイルドカードが何にも一致しなくてもエラーにしない（省略時は true）
+	ExpandWildcards   string `json:"expand_wildcards,omitempty"`   // "open"、"closed"、"hidden"、"all"、"none" のカンマ区切り
+}
+
+// ExpandWildcardStates は expand_wildcards を個々の値に分割する
+func (req *SearchRequest) ExpandWildcardStates() []string {
+	var states []string
+	for _, state := range strings.Split(req.ExpandWildcards, ",") {
+		if state = strings.TrimSpace(state); state != "" {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// FunctionScoreDTO はリクエスト内の関数スコア設定を表す
+type FunctionScoreDTO struct {
+	FieldValueFactor *FieldValueFactorDTO `json:"field_value_factor,omitempty"`
+	Decay            *DecayFunctionDTO    `json:"decay,omitempty"`
+	BoostMode        string               `json:"boost_mode,omitempty"` // "multiply"（デフォルト）、"sum"、"replace" など
+}
+
+// FieldValueFactorDTO はフィールド値によるスコア調整を表す
+type FieldValueFactorDTO struct {
+	Field    string   `json:"field" binding:"required"`
+	Factor   float64  `json:"factor,omitempty"`
+	Modifier string   `json:"modifier,omitempty"` // "log1p"、"sqrt" など
+	Missing  *float64 `json:"missing,omitempty"`
+}
+
+// DecayFunctionDTO は減衰関数によるスコア調整を表す
+type DecayFunctionDTO struct {
+	Function string  `json:"function" binding:"required"` // "gauss"、"linear" または "exp"
+	Field    string  `json:"field" binding:"required"`
+	Origin   string  `json:"origin,omitempty"`
+	Scale    string  `json:"scale" binding:"required"`
+	Offset   string  `json:"offset,omitempty"`
+	Decay    float64 `json:"decay,omitempty"`
+}
+
+// CollapseDTO はリクエスト内のフィールドコラプス設定を表す
+type CollapseDTO struct {
+	Field         string `json:"field" binding:"required"`
+	InnerHitsSize int    `json:"inner_hits_size,omitempty"`
+}
+
+// HighlightDTO はリクエスト内のハイライト設定を表す
+type HighlightDTO struct {
+	Fields []HighlightFieldDTO `json:"fields" binding:"required"`
+}
+
+// HighlightFieldDTO はフィールドごとのハイライト設定を表す
+type HighlightFieldDTO struct {
+	Field             string `json:"field" binding:"required"`
+	FragmentSize      *int   `json:"fragment_size,omitempty"`       // 断片の文字数（省略時は100）
+	NumberOfFragments *int   `json:"number_of_fragments,omitempty"` // 返す断片の最大数（省略時は5、0の場合はフィールド全体）
+	Order             string `json:"order,omitempty"`               // "score"（スコア順）または "none"（出現順、省略時）
+}
+
+// NestedQueryDTO はリクエスト内のネストクエリを表す
+// must と filter のキーは path を含む完全なフィールド名（例: "comments.author"）
+type NestedQueryDTO struct {
+	Path          string            `json:"path" binding:"required"`
+	Must          map[string]string `json:"must,omitempty"`   // 全文一致
+	Filter        map[string]string `json:"filter,omitempty"` // 完全一致
+	InnerHitsSize int               `json:"inner_hits_size,omitempty"`
+}
+
+// MoreLikeThisRequest はテキストによる類似検索リクエストを表す
+type MoreLikeThisRequest struct {
+	Text          string   `json:"text" binding:"required"`
+	Index         string   `json:"index,omitempty"`
+	Fields        []string `json:"fields,omitempty"` // 省略時は設定された検索対象フィールド
+	MinTermFreq   int      `json:"min_term_freq,omitempty"`
+	MaxQueryTerms int      `json:"max_query_terms,omitempty"`
+	MinDocFreq    int      `json:"min_doc_freq,omitempty"`
+	Size          int      `json:"size,omitempty"`
 }
 
 // SortFieldDTO はリクエスト内のソートフィールドを表す
@@ -43,73 +183,289 @@ type SortFieldDTO struct {
 // BulkIndexRequest はバルクインデックスリクエストを表す
 type BulkIndexRequest struct {
 	Documents []BulkDocumentRequest `json:"documents" binding:"required"`
+	Mode      string                `json:"mode,omitempty"`     // "index"（上書き、デフォルト）、"create"（既存IDは競合）または "upsert"（既存IDはマージ）
+	Pipeline  string                `json:"pipeline,omitempty"` // 全ドキュメントに適用するインジェストパイプライン
+
+	// ValidateOnly が true の場合は登録せず、全ドキュメントを検証して失敗するドキュメントのみを返す
+	ValidateOnly bool `json:"validate_only,omitempty"`
+	// Refresh は登録後のリフレッシュの方法（?refresh= から設定。省略時はサーバーのデフォルト）
+	Refresh string `json:"-"`
 }
 
 // BulkDocumentRequest はバルクリクエスト内の単一ドキュメントを表す
 type BulkDocumentRequest struct {
-	Index  string         `json:"index" binding:"required"`
-	ID     string         `json:"id,omitempty"`
-	Source map[string]any `json:"source" binding:"required"`
+	Index    string         `json:"index,omitempty"` // 省略時はデフォルトインデックス
+	ID       string         `json:"id,omitempty"`
+	Source   map[string]any `json:"source" binding:"required"`
+	Routing  string         `json:"routing,omitempty"`
+	Pipeline string         `json:"pipeline,omitempty"` // リクエスト全体のパイプラインより優先される
 }
 
 // CreateIndexRequest はインデックス作成リクエストを表す
 type CreateIndexRequest struct {
-	Index   string         `json:"index" binding:"required"`
-	Mapping map[string]any `json:"mapping,omitempty"`
+	Index    string         `json:"index" binding:"required"`
+	Mapping  map[string]any `json:"mapping,omitempty"`  // mappings に設定する内容（properties など）
+	Settings map[string]any `json:"settings,omitempty"` // シャード数、レプリカ数、アナライザーなどのインデックス設定
 }
 
 // Validate は CreateDocumentRequest を検証する
+// インデックスは省略可能で、その場合はサービス側でデフォルトインデックスが適用される
 func (req *CreateDocumentRequest) Validate() error {
+	var fields errors.FieldErrors
+	if len(req.Source) == 0 {
+		fields.Add("source", ErrSourceRequired.Message)
+	}
+	validateRefresh(&fields, req.Refresh)
+	return fields.Err()
+}
+
+// Validate は CreateRawDocumentRequest を検証する
+// ソースはサイズと構造をハンドラーで、内容をサービス側で検証する
+func (req *CreateRawDocumentRequest) Validate() error {
+	var fields errors.FieldErrors
+	validateRefresh(&fields, req.Refresh)
+	return fields.Err()
+}
+
+// Validate は DeleteDocumentRequest を検証する
+func (req *DeleteDocumentRequest) Validate() error {
+	var fields errors.FieldErrors
 	if req.Index == "" {
-		return ErrIndexRequired
+		fields.Add("index", ErrIndexRequired.Message)
 	}
-	if len(req.Source) == 0 {
-		return ErrSourceRequired
+	if req.ID == "" {
+		fields.Add("id", ErrIDRequired.Message)
+	}
+	validateRefresh(&fields, req.Refresh)
+	return fields.Err()
+}
+
+// validateRefresh はリフレッシュの方法が true、false、wait_for のいずれか（または省略）であることを検証する
+func validateRefresh(fields *errors.FieldErrors, refresh string) {
+	if !entity.RefreshPolicy(refresh).IsValid() {
+		fields.Add("refresh", ErrInvalidRefresh.Message)
 	}
-	return nil
+}
+
+// Validate は BulkIndexRequest を検証する
+// ドキュメントのインデックスは省略可能で、その場合はサービス側でデフォルトインデックスが適用される
+func (req *BulkIndexRequest) Validate() error {
+	var fields errors.FieldErrors
+	if len(req.Documents) == 0 {
+		fields.Add("documents", ErrDocumentsRequired.Message)
+	}
+	if !entity.BulkOpType(req.Mode).IsValid() {
+		fields.Add("mode", ErrInvalidBulkMode.Message)
+	}
+	for i, doc := range req.Documents {
+		if len(doc.Source) == 0 {
+			fields.Add(fmt.Sprintf("documents[%d].source", i), ErrSourceRequired.Message)
+		}
+	}
+	validateRefresh(&fields, req.Refresh)
+	return fields.Err()
 }
 
 // Validate は UpdateDocumentRequest を検証する
 func (req *UpdateDocumentRequest) Validate() error {
+	var fields errors.FieldErrors
 	if req.Index == "" {
-		return ErrIndexRequired
+		fields.Add("index", ErrIndexRequired.Message)
 	}
 	if req.ID == "" {
-		return ErrIDRequired
+		fields.Add("id", ErrIDRequired.Message)
 	}
 	if len(req.Source) == 0 {
-		return ErrSourceRequired
+		fields.Add("source", ErrSourceRequired.Message)
+	}
+	if req.Version < 0 {
+		fields.Add("version", ErrInvalidVersion.Message)
 	}
-	return nil
+	if req.RetryOnConflict != nil && *req.RetryOnConflict < 0 {
+		fields.Add("retry_on_conflict", ErrInvalidRetryOnConflict.Message)
+	}
+	validateRefresh(&fields, req.Refresh)
+	return fields.Err()
+}
+
+// Validate は DiffDocumentRequest を検証する
+func (req *DiffDocumentRequest) Validate() error {
+	var fields errors.FieldErrors
+	if req.Index == "" {
+		fields.Add("index", ErrIndexRequired.Message)
+	}
+	if req.ID == "" {
+		fields.Add("id", ErrIDRequired.Message)
+	}
+	if len(req.Source) == 0 {
+		fields.Add("source", ErrSourceRequired.Message)
+	}
+	return fields.Err()
 }
 
 // Validate は SearchRequest を検証する
 func (req *SearchRequest) Validate() error {
+	var fields errors.FieldErrors
 	if req.Query == "" {
-		return ErrQueryRequired
+		fields.Add("query", ErrQueryRequired.Message)
 	}
 	if req.Size < 0 {
-		return ErrInvalidSize
+		fields.Add("size", ErrInvalidSize.Message)
 	}
 	if req.From < 0 {
-		return ErrInvalidFrom
+		fields.Add("from", ErrInvalidFrom.Message)
+	}
+	if req.Cursor != "" && req.From > 0 {
+		fields.Add("from", ErrCursorWithFrom.Message)
+	}
+	if req.MinScore < 0 {
+		fields.Add("min_score", ErrInvalidMinScore.Message)
+	}
+	if req.Collapse != nil {
+		if req.Collapse.Field == "" {
+			fields.Add("collapse.field", ErrCollapseFieldRequired.Message)
+		}
+		if req.Collapse.InnerHitsSize < 0 {
+			fields.Add("collapse.inner_hits_size", ErrInvalidInnerHitsSize.Message)
+		}
+	}
+	if req.Nested != nil {
+		if req.Nested.Path == "" {
+			fields.Add("nested.path", ErrNestedPathRequired.Message)
+		}
+		if len(req.Nested.Must) == 0 && len(req.Nested.Filter) == 0 {
+			fields.Add("nested", ErrNestedClauseRequired.Message)
+		}
+		if req.Nested.InnerHitsSize < 0 {
+			fields.Add("nested.inner_hits_size", ErrInvalidInnerHitsSize.Message)
+		}
+	}
+	if req.FunctionScore != nil {
+		req.FunctionScore.validate(&fields)
+	}
+	if req.Highlight != nil {
+		req.Highlight.validate(&fields)
 	}
-	for _, sort := range req.Sort {
+	for i, field := range req.StoredFields {
+		if strings.TrimSpace(field) == "" {
+			fields.Add(fmt.Sprintf("stored_fields[%d]", i), ErrFieldNameRequired.Message)
+		}
+	}
+	for i, field := range req.DocValueFields {
+		if strings.TrimSpace(field) == "" {
+			fields.Add(fmt.Sprintf("docvalue_fields[%d]", i), ErrFieldNameRequired.Message)
+		}
+	}
+	if req.Index != "" {
+		for _, index := range strings.Split(req.Index, ",") {
+			if strings.TrimSpace(index) == "" {
+				fields.Add("index", ErrEmptyIndexName.Message)
+				break
+			}
+		}
+	}
+	for _, state := range req.ExpandWildcardStates() {
+		if !entity.IsValidExpandWildcards(state) {
+			fields.Add("expand_wildcards", ErrInvalidExpandWildcards.Message)
+			break
+		}
+	}
+	for i, sort := range req.Sort {
 		if sort.Field == "" {
-			return ErrSortFieldRequired
+			fields.Add(fmt.Sprintf("sort[%d].field", i), ErrSortFieldRequired.Message)
+		}
+		if !entity.IsValidSortOrder(sort.Order) {
+			fields.Add(fmt.Sprintf("sort[%d].order", i), ErrInvalidSortOrder.Message)
+		}
+	}
+	return fields.Err()
+}
+
+// validate は FunctionScoreDTO を検証してエラーを fields に追加する
+func (fs *FunctionScoreDTO) validate(fields *errors.FieldErrors) {
+	if fs.FieldValueFactor == nil && fs.Decay == nil {
+		fields.Add("function_score", ErrFunctionRequired.Message)
+	}
+	if !entity.IsValidBoostMode(fs.BoostMode) {
+		fields.Add("function_score.boost_mode", ErrInvalidBoostMode.Message)
+	}
+	if fvf := fs.FieldValueFactor; fvf != nil {
+		if fvf.Field == "" {
+			fields.Add("function_score.field_value_factor.field", ErrFieldNameRequired.Message)
+		}
+		if fvf.Factor < 0 {
+			fields.Add("function_score.field_value_factor.factor", ErrInvalidFactor.Message)
+		}
+		if !entity.IsValidFieldValueModifier(fvf.Modifier) {
+			fields.Add("function_score.field_value_factor.modifier", ErrInvalidModifier.Message)
+		}
+	}
+	if decay := fs.Decay; decay != nil {
+		if !entity.DecayFunctionType(decay.Function).IsValid() {
+			fields.Add("function_score.decay.function", ErrInvalidDecayFunction.Message)
+		}
+		if decay.Field == "" {
+			fields.Add("function_score.decay.field", ErrFieldNameRequired.Message)
 		}
-		if sort.Order != "asc" && sort.Order != "desc" {
-			return ErrInvalidSortOrder
+		if decay.Scale == "" {
+			fields.Add("function_score.decay.scale", ErrScaleRequired.Message)
+		}
+		if decay.Decay < 0 || decay.Decay >= 1 {
+			fields.Add("function_score.decay.decay", ErrInvalidDecay.Message)
 		}
 	}
-	return nil
+}
+
+// validate は HighlightDTO を検証してエラーを fields に追加する
+func (h *HighlightDTO) validate(fields *errors.FieldErrors) {
+	if len(h.Fields) == 0 {
+		fields.Add("highlight.fields", ErrHighlightFieldsRequired.Message)
+	}
+	for i, field := range h.Fields {
+		if field.Field == "" {
+			fields.Add(fmt.Sprintf("highlight.fields[%d].field", i), ErrFieldNameRequired.Message)
+		}
+		if field.FragmentSize != nil && *field.FragmentSize < 0 {
+			fields.Add(fmt.Sprintf("highlight.fields[%d].fragment_size", i), ErrInvalidFragmentSize.Message)
+		}
+		if field.NumberOfFragments != nil && *field.NumberOfFragments < 0 {
+			fields.Add(fmt.Sprintf("highlight.fields[%d].number_of_fragments", i), ErrInvalidNumberOfFragments.Message)
+		}
+		if !entity.IsValidHighlightOrder(field.Order) {
+			fields.Add(fmt.Sprintf("highlight.fields[%d].order", i), ErrInvalidHighlightOrder.Message)
+		}
+	}
+}
+
+// Validate は MoreLikeThisRequest を検証する
+func (req *MoreLikeThisRequest) Validate() error {
+	var fields errors.FieldErrors
+	if strings.TrimSpace(req.Text) == "" {
+		fields.Add("text", ErrTextRequired.Message)
+	}
+	if req.Size < 0 {
+		fields.Add("size", ErrInvalidSize.Message)
+	}
+	if req.MinTermFreq < 0 {
+		fields.Add("min_term_freq", ErrInvalidMinTermFreq.Message)
+	}
+	if req.MaxQueryTerms < 0 {
+		fields.Add("max_query_terms", ErrInvalidMaxQueryTerms.Message)
+	}
+	if req.MinDocFreq < 0 {
+		fields.Add("min_doc_freq", ErrInvalidMinDocFreq.Message)
+	}
+	for i, field := range req.Fields {
+		if field == "" {
+			fields.Add(fmt.Sprintf("fields[%d]", i), ErrFieldNameRequired.Message)
+		}
+	}
+	return fields.Err()
 }
 
 // SetDefaults は SearchRequest のデフォルト値を設定する
+// サイズのデフォルト値は設定可能なため検索サービス側で適用する
 func (req *SearchRequest) SetDefaults() {
-	if req.Size == 0 {
-		req.Size = 10
-	}
 	if req.From == 0 {
 		req.From = 0
 	}
@@ -117,14 +473,45 @@ func (req *SearchRequest) SetDefaults() {
 
 // バリデーション用のカスタムエラー
 var (
-	ErrIndexRequired     = NewValidationError("インデックスは必須です")
-	ErrIDRequired        = NewValidationError("IDは必須です")
-	ErrSourceRequired    = NewValidationError("ソースは必須です")
-	ErrQueryRequired     = NewValidationError("クエリは必須です")
-	ErrInvalidSize       = NewValidationError("サイズは非負の値である必要があります")
-	ErrInvalidFrom       = NewValidationError("fromは非負の値である必要があります")
-	ErrSortFieldRequired = NewValidationError("ソートフィールドは必須です")
-	ErrInvalidSortOrder  = NewValidationError("ソート順序は 'asc' または 'desc' である必要があります")
+	ErrIndexRequired            = NewValidationError("インデックスは必須です")
+	ErrIDRequired               = NewValidationError("IDは必須です")
+	ErrSourceRequired           = NewValidationError("ソースは必須です（登録するフィールドを1つ以上含むオブジェクトを指定してください）")
+	ErrQueryRequired            = NewValidationError("クエリは必須です")
+	ErrInvalidSize              = NewValidationError("サイズは非負の値である必要があります")
+	ErrInvalidFrom              = NewValidationError("fromは非負の値である必要があります")
+	ErrSortFieldRequired        = NewValidationError("ソートフィールドは必須です")
+	ErrInvalidSortOrder         = NewValidationError("ソート順序は 'asc' または 'desc' である必要があります")
+	ErrInvalidMinScore          = NewValidationError("min_scoreは非負の値である必要があります")
+	ErrCollapseFieldRequired    = NewValidationError("コラプスフィールドは必須です")
+	ErrInvalidInnerHitsSize     = NewValidationError("inner_hits_sizeは非負の値である必要があります")
+	ErrTextRequired             = NewValidationError("テキストは必須です")
+	ErrFieldNameRequired        = NewValidationError("フィールド名は必須です")
+	ErrInvalidMinTermFreq       = NewValidationError("min_term_freqは非負の値である必要があります")
+	ErrInvalidMaxQueryTerms     = NewValidationError("max_query_termsは非負の値である必要があります")
+	ErrInvalidMinDocFreq        = NewValidationError("min_doc_freqは非負の値である必要があります")
+	ErrDocumentsRequired        = NewValidationError("ドキュメントは1件以上必要です")
+	ErrInvalidBulkMode          = NewValidationError("モードは 'index'、'create' または 'upsert' である必要があります")
+	ErrNestedPathRequired       = NewValidationError("ネストクエリのパスは必須です")
+	ErrNestedClauseRequired     = NewValidationError("ネストクエリには must または filter が1件以上必要です")
+	ErrFunctionRequired         = NewValidationError("field_value_factor または decay のいずれかが必要です")
+	ErrInvalidBoostMode         = NewValidationError("boost_modeは 'multiply'、'replace'、'sum'、'avg'、'max'、'min' のいずれかである必要があります")
+	ErrInvalidFactor            = NewValidationError("factorは非負の値である必要があります")
+	ErrInvalidModifier          = NewValidationError("サポートされていないmodifierです")
+	ErrInvalidDecayFunction     = NewValidationError("減衰関数は 'gauss'、'linear' または 'exp' である必要があります")
+	ErrScaleRequired            = NewValidationError("scaleは必須です")
+	ErrInvalidDecay             = NewValidationError("decayは0より大きく1未満である必要があります")
+	ErrHighlightFieldsRequired  = NewValidationError("ハイライトするフィールドが1件以上必要です")
+	ErrInvalidFragmentSize      = NewValidationError("fragment_sizeは非負の値である必要があります")
+	ErrInvalidNumberOfFragments = NewValidationError("number_of_fragmentsは非負の値である必要があります")
+	ErrInvalidHighlightOrder    = NewValidationError("orderは 'score' または 'none' である必要があります")
+	ErrInvalidVersion           = NewValidationError("versionは非負の値である必要があります")
+	ErrInvalidRetryOnConflict   = NewValidationError("retry_on_conflictは非負の値である必要があります")
+	ErrInvalidRefresh           = NewValidationError("refreshは 'true'、'false' または 'wait_for' である必要があります")
+	ErrEmptyIndexName           = NewValidationError("インデックスの一覧に空の名前を含めることはできません")
+	ErrInvalidExpandWildcards   = NewValidationError("expand_wildcardsは 'open'、'closed'、'hidden'、'all'、'none' のいずれかである必要があります")
+	ErrInvalidCursor            = NewValidationError("カーソルの形式が正しくありません（レスポンスの next_cursor をそのまま指定してください）")
+	ErrCursorMismatch           = NewValidationError("カーソルは発行時と同じ index と sort の検索でのみ使用できます")
+	ErrCursorWithFrom           = NewValidationError("cursorを指定した場合、fromは0である必要があります")
 )
 
 // ValidationError はバリデーションエラーを表す
@@ -6,40 +6,291 @@ import (
 
 // DocumentDTO はレスポンス内のドキュメントを表す
 type DocumentDTO struct {
-	ID       string         `json:"id"`
-	Index    string         `json:"index"`
-	Source   map[string]any `json:"source"`
-	Version  int64          `json:"version"`
-	Created  time.Time      `json:"created"`
-	Modified time.Time      `json:"modified"`
+	ID          string         `json:"id"`
+	Index       string         `json:"index"`
+	Source      map[string]any `json:"source"`
+	Version     int64          `json:"version"`
+	SeqNo       int64          `json:"seq_no"`
+	PrimaryTerm int64          `json:"primary_term"`
+	Routing     string         `json:"routing,omitempty"`
+	Created     time.Time      `json:"created"`
+	Modified    time.Time      `json:"modified"`
+}
+
+// QueuedDocumentResponse は非同期登録のキューに積まれたドキュメントを表す
+// 登録はバックグラウンドで行われるため、直後の取得や検索には反映されていない場合がある
+type QueuedDocumentResponse struct {
+	ID     string `json:"id"`
+	Index  string `json:"index"`
+	Status string `json:"status"` // 常に "queued"
+}
+
+// CacheClearResponse はキャッシュの削除結果を表す
+type CacheClearResponse struct {
+	Index   string         `json:"index,omitempty"` // 指定した場合はそのインデックスのエントリのみを削除した
+	Evicted int            `json:"evicted"`         // 削除したエントリの合計
+	Caches  map[string]int `json:"caches"`          // キャッシュごとの削除件数
+}
+
+// MetricsResponse はサーバー内部の状態を表す
+type MetricsResponse struct {
+	AsyncIndexer AsyncIndexerMetricsDTO `json:"async_indexer"`
+}
+
+// AsyncIndexerMetricsDTO は非同期登録のキューの状態を表す
+type AsyncIndexerMetricsDTO struct {
+	QueueDepth    int   `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	Enqueued      int64 `json:"enqueued"`
+	Indexed       int64 `json:"indexed"`
+	Failed        int64 `json:"failed"`
+	Rejected      int64 `json:"rejected"`
+}
+
+// DocumentDiffResponse は現在のドキュメントと変更案のフィールド単位の差分を表す
+type DocumentDiffResponse struct {
+	Index       string           `json:"index"`
+	ID          string           `json:"id"`
+	Version     int64            `json:"version"` // 比較に使った現在のドキュメントのバージョン
+	SeqNo       int64            `json:"seq_no"`
+	PrimaryTerm int64            `json:"primary_term"`
+	HasChanges  bool             `json:"has_changes"`
+	Added       []FieldChangeDTO `json:"added"`
+	Removed     []FieldChangeDTO `json:"removed"`
+	Changed     []FieldChangeDTO `json:"changed"`
+}
+
+// FieldChangeDTO はフィールド単位の差分を表す
+type FieldChangeDTO struct {
+	Path string `json:"path"` // ドット区切りのパス（配列の要素は "tags[0]"）
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// BulkIndexResponse はバルクインデックスのレスポンスを表す
+type BulkIndexResponse struct {
+	Took      int64         `json:"took"`
+	Errors    bool          `json:"errors"`
+	Succeeded int           `json:"succeeded"`
+	Conflicts int           `json:"conflicts"`
+	Failed    int           `json:"failed"`
+	Items     []BulkItemDTO `json:"items"`
+}
+
+// BulkValidationResponse はバルク登録の事前検証（validate_only）のレスポンスを表す
+type BulkValidationResponse struct {
+	Total    int                        `json:"total"`
+	Valid    int                        `json:"valid"`
+	Invalid  int                        `json:"invalid"`
+	Failures []BulkValidationFailureDTO `json:"failures"`
+}
+
+// BulkValidationFailureDTO は検証に失敗したドキュメントと理由を表す
+type BulkValidationFailureDTO struct {
+	Position int             `json:"position"` // リクエストの documents 内の位置（0始まり）
+	Index    string          `json:"index"`
+	ID       string          `json:"id,omitempty"`
+	Errors   []FieldErrorDTO `json:"errors"`
+}
+
+// BulkProgressDTO はNDJSONでストリームする一括登録の進捗を表す（分割したチャンクの送信が完了するたびに1行）
+type BulkProgressDTO struct {
+	Type            string `json:"type"` // "progress"
+	CompletedChunks int    `json:"completed_chunks"`
+	TotalChunks     int    `json:"total_chunks"`
+	Processed       int    `json:"processed"` // 送信が完了したドキュメント数
+	Total           int    `json:"total"`
+	Errors          int    `json:"errors"` // これまでに競合または失敗したドキュメント数
+}
+
+// BulkSummaryDTO はNDJSONでストリームする一括登録の最終結果を表す（最後の1行）
+type BulkSummaryDTO struct {
+	Type string `json:"type"` // "summary"
+	*BulkIndexResponse
+}
+
+// BulkStreamErrorDTO はストリーム開始後に一括登録が失敗したことを表す（最後の1行）
+type BulkStreamErrorDTO struct {
+	Type  string   `json:"type"` // "error"
+	Error ErrorDTO `json:"error"`
+}
+
+// BulkItemDTO はバルク操作の個別アイテムの結果を表す
+type BulkItemDTO struct {
+	Index     string `json:"index"`
+	ID        string `json:"id"`
+	Status    int    `json:"status"`
+	Result    string `json:"result,omitempty"`
+	ErrorType string `json:"error_type,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Conflict  bool   `json:"conflict,omitempty"`
+
+	// ErrorCode と ErrorMessage は失敗したアイテムのみに含まれる（エラーコードはAPIのエラーレスポンスと共通）
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
 }
 
 // SearchResponse は検索レスポンスを表す
 type SearchResponse struct {
-	Query    SearchQueryDTO `json:"query"`
-	Results  []HitDTO       `json:"results"`
-	Total    int64          `json:"total"`
-	MaxScore float64        `json:"max_score,omitempty"`
-	Took     int64          `json:"took"`
-	TimedOut bool           `json:"timed_out,omitempty"`
+	Query         SearchQueryDTO    `json:"query"`
+	Results       []HitDTO          `json:"results"`
+	Total         int64             `json:"total"`
+	TotalRelation string            `json:"total_relation,omitempty"` // "eq" または "gte"
+	MaxScore      float64           `json:"max_score,omitempty"`
+	Took          int64             `json:"took"`
+	TimedOut      bool              `json:"timed_out,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	Profile       *SearchProfileDTO `json:"profile,omitempty"`       // profile=true で検索した場合のみ
+	AppliedRules  []AppliedRuleDTO  `json:"applied_rules,omitempty"` // debug=true で検索した場合のみ
+
+	// ページングのカーソル（from/size でのページングでも次のページのカーソルを返す）
+	Cursor     string  `json:"cursor,omitempty"` // このページの取得に使用したカーソル
+	NextCursor *string `json:"next_cursor"`      // 次のページのカーソル（最終ページの場合は null）
+}
+
+// ETagPayload は ETag の計算に使用する検索結果を返す
+// 実行時間（took）とプロファイル結果はリクエストごとに変わるため除き、同じ結果には同じ ETag を返す
+func (r *SearchResponse) ETagPayload() any {
+	payload := *r
+	payload.Took = 0
+	payload.Profile = nil
+	return payload
+}
+
+// AppliedRuleDTO は検索時にサービスがリクエストに加えた変更を表す
+type AppliedRuleDTO struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+}
+
+// TermsResponse はフィールドの値ごとのドキュメント数の集計結果を表す
+type TermsResponse struct {
+	Index         string           `json:"index"`
+	Field         string           `json:"field"`
+	Buckets       []TermsBucketDTO `json:"buckets"`         // ドキュメント数の多い順
+	OtherDocCount int64            `json:"other_doc_count"` // buckets に含まれなかった値のドキュメント数
+	Total         int64            `json:"total"`           // インデックスの全ドキュメント数
+	Took          int64            `json:"took"`
+}
+
+// TermsBucketDTO はフィールドの値とそのドキュメント数を表す
+type TermsBucketDTO struct {
+	Key         any    `json:"key"`
+	KeyAsString string `json:"key_as_string,omitempty"` // 日付や真偽値などの表示用の値
+	DocCount    int64  `json:"doc_count"`
+}
+
+// SearchProfileDTO は検索の処理時間の内訳を表す（時間はナノ秒）
+type SearchProfileDTO struct {
+	Shards []ShardProfileDTO `json:"shards"`
+	Fields []FieldTimingDTO  `json:"fields"` // フィールドごとのクエリ実行時間（時間の長い順）
+}
+
+// ShardProfileDTO はシャードごとの処理時間の内訳を表す
+type ShardProfileDTO struct {
+	ID             string            `json:"id"`
+	Index          string            `json:"index,omitempty"`
+	Shard          int64             `json:"shard"`
+	QueryNanos     int64             `json:"query_nanos"`
+	RewriteNanos   int64             `json:"rewrite_nanos"`
+	CollectNanos   int64             `json:"collect_nanos"`
+	FetchNanos     int64             `json:"fetch_nanos"`
+	AggregateNanos int64             `json:"aggregate_nanos"`
+	Queries        []QueryProfileDTO `json:"queries,omitempty"`
+}
+
+// QueryProfileDTO は Lucene クエリ1つの実行時間を表す
+type QueryProfileDTO struct {
+	Type        string            `json:"type"`
+	Description string            `json:"description"`
+	Nanos       int64             `json:"nanos"`
+	Children    []QueryProfileDTO `json:"children,omitempty"`
+}
+
+// FieldTimingDTO はフィールドごとのクエリ実行時間の合計を表す
+type FieldTimingDTO struct {
+	Field string `json:"field"`
+	Nanos int64  `json:"nanos"`
 }
 
 // SearchQueryDTO はレスポンス内の検索クエリを表す
 type SearchQueryDTO struct {
-	Query   string            `json:"query"`
-	Index   string            `json:"index,omitempty"`
-	Filters map[string]string `json:"filters,omitempty"`
-	From    int               `json:"from"`
-	Size    int               `json:"size"`
-	Sort    []SortFieldDTO    `json:"sort,omitempty"`
+	Query    string            `json:"query"`
+	Mode     string            `json:"mode,omitempty"`
+	Index    string            `json:"index,omitempty"`
+	Filters  map[string]string `json:"filters,omitempty"`
+	From     int               `json:"from"`
+	Size     int               `json:"size"`
+	Sort     []SortFieldDTO    `json:"sort,omitempty"`
+	MinScore float64           `json:"min_score,omitempty"`
+}
+
+// RenderedQueryResponse は実行せずに構築したElasticsearchクエリを表す
+type RenderedQueryResponse struct {
+	Index string         `json:"index,omitempty"`
+	Query map[string]any `json:"query"`
+}
+
+// QueryValidationResponse は検索を実行せずに行ったクエリ検証の結果を表す
+type QueryValidationResponse struct {
+	Valid        bool                  `json:"valid"`
+	Index        string                `json:"index,omitempty"`
+	Query        map[string]any        `json:"query,omitempty"`        // Elasticsearch で検証したクエリ句
+	Explanations []QueryExplanationDTO `json:"explanations,omitempty"` // インデックスごとの検証結果
+	Error        string                `json:"error,omitempty"`
+}
+
+// QueryExplanationDTO はインデックスごとのクエリ検証結果を表す
+type QueryExplanationDTO struct {
+	Index       string `json:"index"`
+	Valid       bool   `json:"valid"`
+	Explanation string `json:"explanation,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// IndexActionResponse はインデックスに対する管理操作の結果を表す
+type IndexActionResponse struct {
+	Index        string `json:"index"`
+	Action       string `json:"action"`
+	Acknowledged bool   `json:"acknowledged"`
+	TaskID       string `json:"task_id,omitempty"` // バックグラウンドで実行される操作のタスクID
+
+	// 削除の結果（dry_run の場合は何も変更せず、削除される内容のみを返す）
+	DryRun         bool   `json:"dry_run,omitempty"`
+	DocCount       *int64 `json:"doc_count,omitempty"`        // 削除された（dry_run の場合は削除される）ドキュメント数
+	StoreSizeBytes *int64 `json:"store_size_bytes,omitempty"` // 削除された（dry_run の場合は削除される）データのサイズ
+}
+
+// ExportedDocumentDTO はエクスポートされるNDJSONの1行を表す
+// バルク登録リクエストの documents 要素と同じ形式のため、そのまま再登録に使える
+type ExportedDocumentDTO struct {
+	Index   string         `json:"index"`
+	ID      string         `json:"id"`
+	Source  map[string]any `json:"source"`
+	Routing string         `json:"routing,omitempty"`
 }
 
 // HitDTO はレスポンス内の検索ヒットを表す
 type HitDTO struct {
-	Index  string         `json:"index"`
-	ID     string         `json:"id"`
-	Score  float64        `json:"score"`
-	Source map[string]any `json:"source"`
+	Index        string              `json:"index"`
+	ID           string              `json:"id"`
+	Score        float64             `json:"score"`
+	Source       map[string]any      `json:"source,omitzero"`         // source=false で検索した場合は省略される
+	MatchQuality string              `json:"match_quality,omitempty"` // スコアから算出した一致度（"high"、"medium"、"low"）
+	Collapsed    []HitDTO            `json:"collapsed,omitempty"`
+	Nested       []HitDTO            `json:"nested,omitempty"`
+	Highlight    map[string][]string `json:"highlight,omitempty"`    // フィールドごとのハイライトされた断片
+	Fields       map[string][]any    `json:"fields,omitempty"`       // stored_fields と docvalue_fields で取得したフィールドの値
+	Explanation  *ExplanationDTO     `json:"explanation,omitempty"`  // スコアの計算過程（explain 指定時のみ）
+	SeqNo        *int64              `json:"seq_no,omitempty"`       // seq_no_primary_term 指定時のみ
+	PrimaryTerm  *int64              `json:"primary_term,omitempty"` // seq_no_primary_term 指定時のみ
+}
+
+// ExplanationDTO はヒットのスコアの計算過程を表す
+type ExplanationDTO struct {
+	Value       float64          `json:"value"`
+	Description string           `json:"description"`
+	Details     []ExplanationDTO `json:"details,omitempty"`
 }
 
 // ErrorResponse はエラーレスポンスを表す
@@ -49,9 +300,18 @@ type ErrorResponse struct {
 
 // ErrorDTO はエラー詳細を表す
 type ErrorDTO struct {
-	Code    string `json:"code"`
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Details   string          `json:"details,omitempty"`
+	Fields    []FieldErrorDTO `json:"fields,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Retryable bool            `json:"retryable,omitempty"` // 時間をおいて再試行すれば成功しうる場合は true
+}
+
+// FieldErrorDTO はフィールド単位のエラーを表す
+type FieldErrorDTO struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
 }
 
 // HealthResponse はヘルスチェックレスポンスを表す
@@ -60,6 +320,7 @@ type HealthResponse struct {
 	Service string                 `json:"service"`
 	Version string                 `json:"version"`
 	Checks  map[string]interface{} `json:"checks"`
+	Cached  bool                   `json:"cached,omitempty"` // キャッシュしたチェック結果の場合は true
 }
 
 // NewErrorResponse は新しいエラーレスポンスを作成する
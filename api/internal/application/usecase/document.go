@@ -2,9 +2,11 @@ package usecase
 
 import (
 	"context"
+	"io"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/service"
 	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 )
@@ -12,12 +14,14 @@ import (
 // DocumentUseCase はドキュメント関連のビジネスロジックを処理する
 type DocumentUseCase struct {
 	documentService service.DocumentHandler
+	asyncIndexer    *service.AsyncIndexer
 }
 
 // NewDocumentUseCase は新しい DocumentUseCase を作成する
-func NewDocumentUseCase(documentService service.DocumentHandler) *DocumentUseCase {
+func NewDocumentUseCase(documentService service.DocumentHandler, asyncIndexer *service.AsyncIndexer) *DocumentUseCase {
 	return &DocumentUseCase{
 		documentService: documentService,
+		asyncIndexer:    asyncIndexer,
 	}
 }
 
@@ -29,7 +33,7 @@ func (uc *DocumentUseCase) CreateDocument(ctx context.Context, req *dto.CreateDo
 	}
 
 	// ドメインサービスを通じてドキュメントを作成
-	doc, err := uc.documentService.CreateDocument(ctx, req.Index, req.Source)
+	doc, err := uc.documentService.CreateDocument(ctx, req.Index, req.Source, repository.WithRouting(req.Routing), repository.WithPipeline(req.Pipeline), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +42,79 @@ func (uc *DocumentUseCase) CreateDocument(ctx context.Context, req *dto.CreateDo
 	return uc.entityToDTO(doc), nil
 }
 
-// CreateDocumentWithID は指定されたIDでドキュメントを作成する
+// CreateRawDocument は未加工のJSONから新しいドキュメントを作成する
+// レスポンスのソースは返さない（map に変換しないため）
+func (uc *DocumentUseCase) CreateRawDocument(ctx context.Context, req *dto.CreateRawDocumentRequest) (*dto.DocumentDTO, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// ドメインサービスを通じてドキュメントを作成
+	doc, err := uc.documentService.CreateRawDocument(ctx, req.Index, req.Source, repository.WithRouting(req.Routing), repository.WithPipeline(req.Pipeline), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	return uc.entityToDTO(doc), nil
+}
+
+// EnqueueDocument はドキュメントを非同期登録のキューに積む
+// キューが満杯の場合は QUEUE_FULL（503）を返す
+func (uc *DocumentUseCase) EnqueueDocument(ctx context.Context, req *dto.CreateDocumentRequest) (*dto.QueuedDocumentResponse, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if req.ID != "" {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{Field: "id", Message: "非同期登録ではドキュメントIDを指定できません"}})
+	}
+
+	doc, err := uc.asyncIndexer.Enqueue(ctx, req.Index, req.Source, repository.WithRouting(req.Routing), repository.WithPipeline(req.Pipeline))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.QueuedDocumentResponse{
+		ID:     doc.ID,
+		Index:  doc.Index,
+		Status: "queued",
+	}, nil
+}
+
+// AsyncIndexerMetrics は非同期登録のキューの状態を返す
+func (uc *DocumentUseCase) AsyncIndexerMetrics() dto.AsyncIndexerMetricsDTO {
+	stats := uc.asyncIndexer.Stats()
+	return dto.AsyncIndexerMetricsDTO{
+		QueueDepth:    stats.QueueDepth,
+		QueueCapacity: stats.QueueCapacity,
+		Enqueued:      stats.Enqueued,
+		Indexed:       stats.Indexed,
+		Failed:        stats.Failed,
+		Rejected:      stats.Rejected,
+	}
+}
+
+// CreateDocumentIdempotent は冪等キー付きでドキュメントを作成する
+// 同じキーでの再送には最初の結果を返し、その場合は replayed が true になる
+func (uc *DocumentUseCase) CreateDocumentIdempotent(ctx context.Context, req *dto.CreateDocumentRequest, key string) (*dto.DocumentDTO, bool, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	// ドメインサービスを通じてドキュメントを作成
+	doc, replayed, err := uc.documentService.CreateDocumentIdempotent(ctx, key, req.Index, req.Source, repository.WithRouting(req.Routing), repository.WithPipeline(req.Pipeline), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// DTOに変換
+	return uc.entityToDTO(doc), replayed, nil
+}
+
 func (uc *DocumentUseCase) CreateDocumentWithID(ctx context.Context, req *dto.CreateDocumentRequest) (*dto.DocumentDTO, error) {
 	// リクエストを検証
 	if err := req.Validate(); err != nil {
@@ -50,7 +126,7 @@ func (uc *DocumentUseCase) CreateDocumentWithID(ctx context.Context, req *dto.Cr
 	}
 
 	// ドメインサービスを通じてIDありでドキュメントを作成
-	doc, err := uc.documentService.CreateDocumentWithID(ctx, req.Index, req.ID, req.Source)
+	doc, err := uc.documentService.CreateDocumentWithID(ctx, req.Index, req.ID, req.Source, repository.WithRouting(req.Routing), repository.WithPipeline(req.Pipeline), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +136,8 @@ func (uc *DocumentUseCase) CreateDocumentWithID(ctx context.Context, req *dto.Cr
 }
 
 // GetDocument はインデックスとIDでドキュメントを取得する
-func (uc *DocumentUseCase) GetDocument(ctx context.Context, index, id string) (*dto.DocumentDTO, error) {
+// カスタムルーティングで登録されたドキュメントは同じ routing を指定する必要がある
+func (uc *DocumentUseCase) GetDocument(ctx context.Context, index, id, routing string) (*dto.DocumentDTO, error) {
 	// 入力を検証
 	if index == "" {
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
@@ -70,7 +147,7 @@ func (uc *DocumentUseCase) GetDocument(ctx context.Context, index, id string) (*
 	}
 
 	// ドメインサービスを通じてドキュメントを取得
-	doc, err := uc.documentService.GetDocument(ctx, index, id)
+	doc, err := uc.documentService.GetDocument(ctx, index, id, repository.WithRouting(routing))
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +156,60 @@ func (uc *DocumentUseCase) GetDocument(ctx context.Context, index, id string) (*
 	return uc.entityToDTO(doc), nil
 }
 
+// GetDocumentRaw はドキュメントの_sourceをストリームとして取得する
+// 呼び出し元は返されたストリームをCloseする必要がある
+func (uc *DocumentUseCase) GetDocumentRaw(ctx context.Context, index, id, routing string) (io.ReadCloser, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+	if id == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "ドキュメントIDは空にできません")
+	}
+
+	// ドメインサービスを通じてドキュメントを取得
+	return uc.documentService.GetDocumentRaw(ctx, index, id, repository.WithRouting(routing))
+}
+
+// DiffDocument は現在のドキュメントと変更案のソースの差分を返す（書き込みは行わない）
+func (uc *DocumentUseCase) DiffDocument(ctx context.Context, req *dto.DiffDocumentRequest) (*dto.DocumentDiffResponse, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// ドメインサービスを通じて差分を計算
+	current, diff, err := uc.documentService.DiffDocument(ctx, req.Index, req.ID, req.Source, repository.WithRouting(req.Routing))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DocumentDiffResponse{
+		Index:       current.Index,
+		ID:          current.ID,
+		Version:     current.Version,
+		SeqNo:       current.SeqNo,
+		PrimaryTerm: current.PrimaryTerm,
+		HasChanges:  diff.HasChanges(),
+		Added:       fieldChangesToDTO(diff.Added),
+		Removed:     fieldChangesToDTO(diff.Removed),
+		Changed:     fieldChangesToDTO(diff.Changed),
+	}, nil
+}
+
+// fieldChangesToDTO はフィールドの差分をDTOに変換する
+func fieldChangesToDTO(changes []entity.FieldChange) []dto.FieldChangeDTO {
+	result := make([]dto.FieldChangeDTO, len(changes))
+	for i, change := range changes {
+		result[i] = dto.FieldChangeDTO{
+			Path: change.Path,
+			Old:  change.Old,
+			New:  change.New,
+		}
+	}
+	return result
+}
+
 // UpdateDocument は既存のドキュメントを更新する
 func (uc *DocumentUseCase) UpdateDocument(ctx context.Context, req *dto.UpdateDocumentRequest) (*dto.DocumentDTO, error) {
 	// リクエストを検証
@@ -87,7 +218,7 @@ func (uc *DocumentUseCase) UpdateDocument(ctx context.Context, req *dto.UpdateDo
 	}
 
 	// ドメインサービスを通じてドキュメントを更新
-	doc, err := uc.documentService.UpdateDocument(ctx, req.Index, req.ID, req.Source)
+	doc, err := uc.documentService.UpdateDocument(ctx, req.Index, req.ID, req.Source, repository.WithRouting(req.Routing), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +227,67 @@ func (uc *DocumentUseCase) UpdateDocument(ctx context.Context, req *dto.UpdateDo
 	return uc.entityToDTO(doc), nil
 }
 
+// UpdateDocumentIfMatch はシーケンス番号とプライマリタームが一致する場合のみドキュメントを更新する
+func (uc *DocumentUseCase) UpdateDocumentIfMatch(ctx context.Context, req *dto.UpdateDocumentRequest, seqNo, primaryTerm int64) (*dto.DocumentDTO, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// ドメインサービスを通じて条件付きでドキュメントを更新
+	doc, err := uc.documentService.UpdateDocumentIfMatch(ctx, req.Index, req.ID, req.Source, seqNo, primaryTerm, repository.WithRouting(req.Routing), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	return uc.entityToDTO(doc), nil
+}
+
+// UpsertDocument はドキュメントを作成または更新する
+func (uc *DocumentUseCase) UpsertDocument(ctx context.Context, req *dto.UpdateDocumentRequest) (*dto.DocumentDTO, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	opts := []repository.DocumentOption{repository.WithRouting(req.Routing), repository.WithRefresh(entity.RefreshPolicy(req.Refresh))}
+	if req.RetryOnConflict != nil {
+		opts = append(opts, repository.WithRetryOnConflict(*req.RetryOnConflict))
+	}
+
+	// ドメインサービスを通じてドキュメントをアップサート
+	doc, err := uc.documentService.UpsertDocument(ctx, req.Index, req.ID, req.Source, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	return uc.entityToDTO(doc), nil
+}
+
+// ReplaceDocument はドキュメントを作成または置き換える（PUT のセマンティクス）
+// 新規作成された場合は created に true を返す
+func (uc *DocumentUseCase) ReplaceDocument(ctx context.Context, req *dto.UpdateDocumentRequest) (result *dto.DocumentDTO, created bool, err error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	// ドメインサービスを通じてドキュメントを作成または置き換え
+	doc, created, err := uc.documentService.ReplaceDocument(ctx, req.Index, req.ID, req.Source,
+		repository.WithRouting(req.Routing),
+		repository.WithExternalVersion(req.Version),
+		repository.WithRefresh(entity.RefreshPolicy(req.Refresh)),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// DTOに変換
+	return uc.entityToDTO(doc), created, nil
+}
+
 // DeleteDocument はドキュメントを削除する
 func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, req *dto.DeleteDocumentRequest) error {
 	// リクエストを検証
@@ -105,19 +297,148 @@ func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, req *dto.DeleteDo
 	if req.ID == "" {
 		return errors.NewAppError(errors.ErrCodeValidationFailed, "ドキュメントIDは空にできません")
 	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	opts := []repository.DocumentOption{repository.WithRouting(req.Routing), repository.WithRefresh(entity.RefreshPolicy(req.Refresh))}
+	if req.IfSeqNo != nil && req.IfPrimaryTerm != nil {
+		opts = append(opts, repository.WithIfMatch(*req.IfSeqNo, *req.IfPrimaryTerm))
+	}
 
 	// ドメインサービスを通じてドキュメントを削除
-	return uc.documentService.DeleteDocument(ctx, req.Index, req.ID)
+	return uc.documentService.DeleteDocument(ctx, req.Index, req.ID, opts...)
+}
+
+// BulkIndexDocuments は複数のドキュメントを一度にインデックスする
+func (uc *DocumentUseCase) BulkIndexDocuments(ctx context.Context, req *dto.BulkIndexRequest) (*dto.BulkIndexResponse, error) {
+	return uc.BulkIndexDocumentsWithProgress(ctx, req, nil)
+}
+
+// BulkIndexDocumentsWithProgress は複数のドキュメントを一度にインデックスし、
+// 分割したチャンクの送信が完了するたびに progress へ進捗を通知する（progress が nil の場合は通知しない）
+func (uc *DocumentUseCase) BulkIndexDocumentsWithProgress(ctx context.Context, req *dto.BulkIndexRequest, progress func(*dto.BulkProgressDTO)) (*dto.BulkIndexResponse, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// DTOをエンティティに変換
+	docs := bulkRequestToDocuments(req)
+
+	var notify func(entity.BulkProgress)
+	if progress != nil {
+		notify = func(p entity.BulkProgress) {
+			progress(&dto.BulkProgressDTO{
+				Type:            "progress",
+				CompletedChunks: p.CompletedChunks,
+				TotalChunks:     p.TotalChunks,
+				Processed:       p.Processed,
+				Total:           p.Total,
+				Errors:          p.Errors,
+			})
+		}
+	}
+
+	// ドメインサービスを通じてバルクインデックスを実行
+	result, err := uc.documentService.BulkIndexDocumentsWithProgress(ctx, docs, entity.BulkOpType(req.Mode), notify, repository.WithPipeline(req.Pipeline), repository.WithRefresh(entity.RefreshPolicy(req.Refresh)))
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	return bulkResultToDTO(result), nil
+}
+
+// ValidateBulkDocuments は複数のドキュメントを登録せずに検証し、失敗するドキュメントとその理由を返す
+func (uc *DocumentUseCase) ValidateBulkDocuments(ctx context.Context, req *dto.BulkIndexRequest) (*dto.BulkValidationResponse, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// ドメインサービスを通じて検証を実行
+	result, err := uc.documentService.ValidateBulkDocuments(ctx, bulkRequestToDocuments(req), entity.BulkOpType(req.Mode), repository.WithPipeline(req.Pipeline))
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	failures := make([]dto.BulkValidationFailureDTO, len(result.Failures))
+	for i, failure := range result.Failures {
+		errs := make([]dto.FieldErrorDTO, len(failure.Errors))
+		for j, e := range failure.Errors {
+			errs[j] = dto.FieldErrorDTO{Field: e.Field, Message: e.Message}
+		}
+		failures[i] = dto.BulkValidationFailureDTO{
+			Position: failure.Position,
+			Index:    failure.Index,
+			ID:       failure.ID,
+			Errors:   errs,
+		}
+	}
+
+	return &dto.BulkValidationResponse{
+		Total:    result.Total,
+		Valid:    result.Valid,
+		Invalid:  result.Invalid,
+		Failures: failures,
+	}, nil
+}
+
+// bulkRequestToDocuments はバルクリクエストのドキュメントをエンティティに変換する
+func bulkRequestToDocuments(req *dto.BulkIndexRequest) []*entity.Document {
+	docs := make([]*entity.Document, len(req.Documents))
+	for i, item := range req.Documents {
+		doc := entity.NewDocument(item.Index, item.Source)
+		doc.SetID(item.ID)
+		doc.Routing = item.Routing
+		doc.Pipeline = item.Pipeline
+		docs[i] = doc
+	}
+	return docs
+}
+
+// bulkResultToDTO はバルク操作の結果をDTOに変換する
+func bulkResultToDTO(result *entity.BulkResult) *dto.BulkIndexResponse {
+	items := make([]dto.BulkItemDTO, len(result.Items))
+	for i, item := range result.Items {
+		items[i] = dto.BulkItemDTO{
+			Index:     item.Index,
+			ID:        item.ID,
+			Status:    item.Status,
+			Result:    item.Result,
+			ErrorType: item.ErrorType,
+			Error:     item.Error,
+			Conflict:  item.Conflict,
+		}
+		if item.Failed() {
+			items[i].ErrorCode = item.ErrorCode
+			items[i].ErrorMessage = item.Error
+		}
+	}
+
+	return &dto.BulkIndexResponse{
+		Took:      result.Took,
+		Errors:    result.HasErrors(),
+		Succeeded: result.Succeeded,
+		Conflicts: result.Conflicts,
+		Failed:    result.Failed,
+		Items:     items,
+	}
 }
 
 // entityToDTO はエンティティをDTOに変換するヘルパーメソッド
 func (uc *DocumentUseCase) entityToDTO(doc *entity.Document) *dto.DocumentDTO {
 	return &dto.DocumentDTO{
-		ID:       doc.ID,
-		Index:    doc.Index,
-		Source:   doc.Source,
-		Version:  doc.Version,
-		Created:  doc.Created,
-		Modified: doc.Modified,
+		ID:          doc.ID,
+		Index:       doc.Index,
+		Source:      doc.Source,
+		Version:     doc.Version,
+		SeqNo:       doc.SeqNo,
+		PrimaryTerm: doc.PrimaryTerm,
+		Routing:     doc.Routing,
+		Created:     doc.Created,
+		Modified:    doc.Modified,
 	}
 }
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+func TestBulkResultToDTO(t *testing.T) {
+	result := &entity.BulkResult{}
+	result.AddItem(entity.BulkItemResult{Index: "articles", ID: "1", Status: http.StatusCreated, Result: "created"})
+	result.AddItem(entity.BulkItemResult{Index: "articles", ID: "2", Status: http.StatusBadRequest, ErrorType: "mapper_parsing_exception", Error: "failed to parse field [price]", ErrorCode: "INVALID_DOCUMENT"})
+	result.AddItem(entity.BulkItemResult{Index: "articles", ID: "3", Status: http.StatusConflict, Conflict: true, Error: "version conflict", ErrorCode: "DOCUMENT_EXISTS"})
+
+	response := bulkResultToDTO(result)
+
+	if !response.Errors || response.Succeeded != 1 || response.Failed != 1 || response.Conflicts != 1 {
+		t.Errorf("errors/succeeded/failed/conflicts = %v/%d/%d/%d, want true/1/1/1", response.Errors, response.Succeeded, response.Failed, response.Conflicts)
+	}
+
+	tests := []struct {
+		id          string
+		wantCode    string
+		wantMessage string
+	}{
+		{id: "1"},
+		{id: "2", wantCode: "INVALID_DOCUMENT", wantMessage: "failed to parse field [price]"},
+		{id: "3", wantCode: "DOCUMENT_EXISTS", wantMessage: "version conflict"},
+	}
+	if len(response.Items) != len(tests) {
+		t.Fatalf("len(items) = %d, want %d", len(response.Items), len(tests))
+	}
+	for i, tt := range tests {
+		item := response.Items[i]
+		if item.ID != tt.id {
+			t.Errorf("items[%d].ID = %s, want %s (items must follow the request order)", i, item.ID, tt.id)
+		}
+		if item.ErrorCode != tt.wantCode || item.ErrorMessage != tt.wantMessage {
+			t.Errorf("items[%d] error = %q/%q, want %q/%q", i, item.ErrorCode, item.ErrorMessage, tt.wantCode, tt.wantMessage)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/service"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// IndexUseCase はインデックス関連の操作を処理する
+type IndexUseCase struct {
+	indexService  service.IndexManager
+	searchService service.Searcher
+}
+
+// NewIndexUseCase は新しい IndexUseCase を作成する
+func NewIndexUseCase(indexService service.IndexManager, searchService service.Searcher) *IndexUseCase {
+	return &IndexUseCase{
+		indexService:  indexService,
+		searchService: searchService,
+	}
+}
+
+// GetIndexStats はインデックスの統計情報を取得する
+func (uc *IndexUseCase) GetIndexStats(ctx context.Context, index string) (map[string]any, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じて統計情報を取得
+	return uc.indexService.GetIndexStats(ctx, index)
+}
+
+// CreateIndex はマッピングと設定を指定してインデックスを作成する
+func (uc *IndexUseCase) CreateIndex(ctx context.Context, req *dto.CreateIndexRequest) (*dto.IndexActionResponse, error) {
+	// 入力を検証
+	if req.Index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じてインデックスを作成
+	if err := uc.indexService.CreateIndex(ctx, req.Index, req.Mapping, req.Settings); err != nil {
+		return nil, err
+	}
+
+	return &dto.IndexActionResponse{Index: req.Index, Action: "create", Acknowledged: true}, nil
+}
+
+// OpenIndex はクローズされたインデックスをオープンする
+func (uc *IndexUseCase) OpenIndex(ctx context.Context, index string) (*dto.IndexActionResponse, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じてインデックスをオープン
+	if err := uc.indexService.OpenIndex(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &dto.IndexActionResponse{Index: index, Action: "open", Acknowledged: true}, nil
+}
+
+// CloseIndex はインデックスをクローズする
+func (uc *IndexUseCase) CloseIndex(ctx context.Context, index string) (*dto.IndexActionResponse, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じてインデックスをクローズ
+	if err := uc.indexService.CloseIndex(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &dto.IndexActionResponse{Index: index, Action: "close", Acknowledged: true}, nil
+}
+
+// RefreshIndex はインデックスをリフレッシュし、書き込み済みのドキュメントを検索可能にする
+func (uc *IndexUseCase) RefreshIndex(ctx context.Context, index string) (*dto.IndexActionResponse, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じてインデックスをリフレッシュ
+	if err := uc.indexService.RefreshIndex(ctx, index); err != nil {
+		return nil, err
+	}
+
+	return &dto.IndexActionResponse{Index: index, Action: "refresh", Acknowledged: true}, nil
+}
+
+// ForceMerge はインデックスのフォースマージを開始する
+func (uc *IndexUseCase) ForceMerge(ctx context.Context, index string, maxSegments int) (*dto.IndexActionResponse, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じてフォースマージを開始
+	taskID, err := uc.indexService.ForceMerge(ctx, index, maxSegments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.IndexActionResponse{Index: index, Action: "forcemerge", Acknowledged: true, TaskID: taskID}, nil
+}
+
+// DeleteIndex はインデックスを削除する
+// dryRun が true の場合は削除せず、削除されるドキュメント数とサイズを返す（Acknowledged は false）
+func (uc *IndexUseCase) DeleteIndex(ctx context.Context, index string, dryRun bool) (*dto.IndexActionResponse, error) {
+	// 入力を検証
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じてインデックスを削除
+	stats, err := uc.indexService.DeleteIndex(ctx, index, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.IndexActionResponse{Index: index, Action: "delete", Acknowledged: !dryRun, DryRun: dryRun}
+	if docCount, ok := stats["doc_count"].(int64); ok {
+		response.DocCount = &docCount
+	}
+	if storeSize, ok := stats["store_size_bytes"].(int64); ok {
+		response.StoreSizeBytes = &storeSize
+	}
+	return response, nil
+}
+
+// ExportDocuments はインデックスのドキュメントをバッチ単位で fn に渡す
+// query は query_string 構文の絞り込み条件で、空の場合は全ドキュメントを対象とする
+// 絞り込み条件には検索と同じ検証・無害化・フィールドの制限を適用する
+func (uc *IndexUseCase) ExportDocuments(ctx context.Context, index, query string, fn func(docs []dto.ExportedDocumentDTO) error) error {
+	// 入力を検証
+	if index == "" {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// 絞り込み条件を検索サービスで構築する
+	var searchQuery *entity.SearchQuery
+	if query != "" {
+		var err error
+		searchQuery, err = uc.searchService.PrepareExportQuery(ctx, index, query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// ドメインサービスを通じてドキュメントを取得し、DTOに変換して渡す
+	return uc.indexService.ExportDocuments(ctx, index, searchQuery, func(hits []entity.Hit) error {
+		docs := make([]dto.ExportedDocumentDTO, len(hits))
+		for i, hit := range hits {
+			docs[i] = dto.ExportedDocumentDTO{
+				Index:   hit.Index,
+				ID:      hit.ID,
+				Source:  hit.Source,
+				Routing: hit.Routing,
+			}
+		}
+		return fn(docs)
+	})
+}
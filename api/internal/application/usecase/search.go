@@ -2,9 +2,11 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/service"
 	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 )
@@ -14,23 +16,33 @@ type SearchUseCaser interface {
 	Search(ctx context.Context, req *dto.SearchRequest) (*dto.SearchResponse, error)
 	AdvancedSearch(ctx context.Context, req *dto.SearchRequest) (*dto.SearchResponse, error)
 	MultiSearch(ctx context.Context, requests []*dto.SearchRequest) ([]*dto.SearchResponse, error)
-	SuggestSearch(ctx context.Context, query, index, field string, size int) (*dto.SearchResponse, error)
+	SuggestSearch(ctx context.Context, query, index, field, mode string, size int) (*dto.SearchResponse, error)
 	FacetedSearch(ctx context.Context, req *dto.SearchRequest, facetFields []string) (*dto.SearchResponse, error)
 	SearchByField(ctx context.Context, field, value, index string, from, size int) (*dto.SearchResponse, error)
 	SearchSimilar(ctx context.Context, index, id string, fields []string, size int) (*dto.SearchResponse, error)
+	SearchMoreLikeThis(ctx context.Context, req *dto.MoreLikeThisRequest) (*dto.SearchResponse, error)
 	GetSearchStatistics(ctx context.Context, index string) (map[string]any, error)
-	ValidateSearchQuery(ctx context.Context, req *dto.SearchRequest) error
+	ValidateSearchQuery(ctx context.Context, req *dto.SearchRequest, remote bool) (*dto.QueryValidationResponse, error)
+	RenderSearchQuery(ctx context.Context, req *dto.SearchRequest) (*dto.RenderedQueryResponse, error)
+	Iterate(ctx context.Context, req *dto.SearchRequest) *SearchIterator
+	ListDocuments(ctx context.Context, index string, from, size int, sort []dto.SortFieldDTO) (*dto.SearchResponse, error)
+	CountByField(ctx context.Context, index, field string, size int) (*dto.TermsResponse, error)
+	ClearCaches(index string) *dto.CacheClearResponse
 }
 
 // SearchUseCase は検索関連の操作を処理する
 type SearchUseCase struct {
 	searchService service.Searcher
+	indexService  service.IndexManager
+	metrics       *SearchMetrics
 }
 
 // NewSearchUseCase は新しい SearchUseCase を作成する
-func NewSearchUseCase(searchService service.Searcher) *SearchUseCase {
+func NewSearchUseCase(searchService service.Searcher, indexService service.IndexManager) *SearchUseCase {
 	return &SearchUseCase{
 		searchService: searchService,
+		indexService:  indexService,
+		metrics:       NewSearchMetrics(),
 	}
 }
 
@@ -44,14 +56,24 @@ func (uc *SearchUseCase) Search(ctx context.Context, req *dto.SearchRequest) (*d
 	// デフォルト値を設定
 	req.SetDefaults()
 
+	// カーソルが指定されていれば検証して search_after に設定
+	query, err := uc.queryWithCursor(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// ドメインサービスを通じて検索を実行
-	result, err := uc.searchService.Search(ctx, req.Query, req.Index, req.From, req.Size)
+	start := time.Now()
+	result, err := uc.searchService.ExecuteSearch(ctx, query, searchOptions(req)...)
+	uc.metrics.Record(req.Index, req.Query, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
 
-	// DTOに変換
-	return uc.entityToDTO(result), nil
+	// DTOに変換し、ページングのカーソルを設定
+	response := uc.entityToDTO(result)
+	setCursors(response, req, result)
+	return response, nil
 }
 
 // AdvancedSearch はフィルターとソートを含む高度な検索を実行する
@@ -64,17 +86,40 @@ func (uc *SearchUseCase) AdvancedSearch(ctx context.Context, req *dto.SearchRequ
 	// デフォルト値を設定
 	req.SetDefaults()
 
-	// ソートフィールドをエンティティ型に変換
-	sortFields := make([]entity.SortField, len(req.Sort))
-	for i, sort := range req.Sort {
-		sortFields[i] = entity.SortField{
-			Field: sort.Field,
-			Order: sort.Order,
-		}
+	// カーソルが指定されていれば検証して search_after に設定
+	query, err := uc.queryWithCursor(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// ドメインサービスを通じて高度な検索を実行
-	result, err := uc.searchService.AdvancedSearch(ctx, req.Query, req.Index, req.Filters, sortFields, req.From, req.Size)
+	start := time.Now()
+	result, err := uc.searchService.ExecuteSearch(ctx, query, searchOptions(req)...)
+	uc.metrics.Record(req.Index, req.Query, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換し、ページングのカーソルを設定
+	response := uc.entityToDTO(result)
+	setCursors(response, req, result)
+	return response, nil
+}
+
+// ListDocuments はインデックス内のドキュメントを検索語なしでページ単位に一覧する
+func (uc *SearchUseCase) ListDocuments(ctx context.Context, index string, from, size int, sort []dto.SortFieldDTO) (*dto.SearchResponse, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ソートフィールドを変換（ソート順序は大文字小文字を区別しない）
+	sortFields := make([]entity.SortField, 0, len(sort))
+	for _, s := range sort {
+		sortFields = append(sortFields, entity.SortField{Field: s.Field, Order: entity.NormalizeSortOrder(s.Order)})
+	}
+
+	// ドメインサービスを通じて一覧を取得
+	result, err := uc.searchService.ListDocuments(ctx, index, from, size, sortFields)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +128,50 @@ func (uc *SearchUseCase) AdvancedSearch(ctx context.Context, req *dto.SearchRequ
 	return uc.entityToDTO(result), nil
 }
 
+// ClearCaches は検索のキャッシュを削除し、キャッシュごとの削除件数を返す
+// index を指定した場合はそのインデックスのエントリのみを削除する
+func (uc *SearchUseCase) ClearCaches(index string) *dto.CacheClearResponse {
+	response := &dto.CacheClearResponse{
+		Index:  index,
+		Caches: uc.searchService.ClearCaches(index),
+	}
+	for _, evicted := range response.Caches {
+		response.Evicted += evicted
+	}
+	return response
+}
+
+// CountByField はフィールドの値ごとのドキュメント数を集計する（ヒットは返さない）
+func (uc *SearchUseCase) CountByField(ctx context.Context, index, field string, size int) (*dto.TermsResponse, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
+	}
+
+	// ドメインサービスを通じて集計を実行
+	result, err := uc.searchService.CountByField(ctx, index, field, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	response := &dto.TermsResponse{
+		Index:         result.Index,
+		Field:         result.Field,
+		Buckets:       make([]dto.TermsBucketDTO, 0, len(result.Buckets)),
+		OtherDocCount: result.OtherDocCount,
+		Total:         result.Total,
+		Took:          result.Took,
+	}
+	for _, bucket := range result.Buckets {
+		response.Buckets = append(response.Buckets, dto.TermsBucketDTO{
+			Key:         bucket.Key,
+			KeyAsString: bucket.KeyAsString,
+			DocCount:    bucket.DocCount,
+		})
+	}
+	return response, nil
+}
+
 // MultiSearch は複数の検索操作を実行する
 func (uc *SearchUseCase) MultiSearch(ctx context.Context, requests []*dto.SearchRequest) ([]*dto.SearchResponse, error) {
 	// リクエストを検証
@@ -98,27 +187,24 @@ func (uc *SearchUseCase) MultiSearch(ctx context.Context, requests []*dto.Search
 		}
 		req.SetDefaults()
 
-		query := entity.SearchQuery{
-			Query:   req.Query,
-			Index:   req.Index,
-			Filters: req.Filters,
-			From:    req.From,
-			Size:    req.Size,
-		}
-
-		// ソートフィールドを変換
-		for _, sort := range req.Sort {
-			query.Sort = append(query.Sort, entity.SortField{
-				Field: sort.Field,
-				Order: sort.Order,
-			})
+		query, err := uc.queryWithCursor(req)
+		if err != nil {
+			return nil, err
 		}
-
-		queries[i] = query
+		queries[i] = *query
 	}
 
 	// ドメインサービスを通じてマルチ検索を実行
+	start := time.Now()
 	results, err := uc.searchService.MultiSearch(ctx, queries)
+	latency := time.Since(start)
+	for i, query := range queries {
+		queryErr := err
+		if queryErr == nil && i < len(results) && results[i].HasError() {
+			queryErr = errors.NewAppError(errors.ErrCodeSearchFailed, results[i].Error)
+		}
+		uc.metrics.Record(query.Index, query.Query, latency, queryErr)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -127,13 +213,17 @@ func (uc *SearchUseCase) MultiSearch(ctx context.Context, requests []*dto.Search
 	responses := make([]*dto.SearchResponse, len(results))
 	for i, result := range results {
 		responses[i] = uc.entityToDTO(result)
+		if i < len(requests) {
+			setCursors(responses[i], requests[i], result)
+		}
 	}
 
 	return responses, nil
 }
 
 // SuggestSearch はサジェスト/オートコンプリート検索を実行する
-func (uc *SearchUseCase) SuggestSearch(ctx context.Context, query, index, field string, size int) (*dto.SearchResponse, error) {
+// mode は "prefix"（デフォルト）または "search_as_you_type"
+func (uc *SearchUseCase) SuggestSearch(ctx context.Context, query, index, field, mode string, size int) (*dto.SearchResponse, error) {
 	// 入力を検証
 	if query == "" {
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "検索クエリは空にできません")
@@ -148,7 +238,9 @@ func (uc *SearchUseCase) SuggestSearch(ctx context.Context, query, index, field
 	}
 
 	// ドメインサービスを通じてサジェスト検索を実行
-	result, err := uc.searchService.SuggestSearch(ctx, query, index, field, size)
+	start := time.Now()
+	result, err := uc.searchService.SuggestSearch(ctx, query, index, field, entity.SuggestMode(mode), size)
+	uc.metrics.Record(index, query, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +264,9 @@ func (uc *SearchUseCase) FacetedSearch(ctx context.Context, req *dto.SearchReque
 	req.SetDefaults()
 
 	// ドメインサービスを通じてファセット検索を実行
+	start := time.Now()
 	result, err := uc.searchService.FacetedSearch(ctx, req.Query, req.Index, facetFields, req.From, req.Size)
+	uc.metrics.Record(req.Index, req.Query, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
@@ -191,9 +285,9 @@ func (uc *SearchUseCase) SearchByField(ctx context.Context, field, value, index
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "値は空にできません")
 	}
 
-	// デフォルト値を設定
-	if size <= 0 {
-		size = 10
+	// デフォルト値を設定（サイズ0は検索サービスのデフォルトサイズになる）
+	if size < 0 {
+		size = 0
 	}
 	if from < 0 {
 		from = 0
@@ -246,6 +340,35 @@ func (uc *SearchUseCase) SearchSimilar(ctx context.Context, index, id string, fi
 	return uc.entityToDTO(result), nil
 }
 
+// SearchMoreLikeThis は任意のテキストに類似したドキュメントを検索する
+func (uc *SearchUseCase) SearchMoreLikeThis(ctx context.Context, req *dto.MoreLikeThisRequest) (*dto.SearchResponse, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := &entity.MoreLikeThisQuery{
+		Text:          req.Text,
+		Index:         req.Index,
+		Fields:        req.Fields,
+		MinTermFreq:   req.MinTermFreq,
+		MaxQueryTerms: req.MaxQueryTerms,
+		MinDocFreq:    req.MinDocFreq,
+		Size:          req.Size,
+	}
+
+	// ドメインサービスを通じて類似検索を実行
+	start := time.Now()
+	result, err := uc.searchService.MoreLikeThisSearch(ctx, query)
+	uc.metrics.Record(req.Index, req.Text, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	// DTOに変換
+	return uc.entityToDTO(result), nil
+}
+
 // GetSearchStatistics は検索統計と分析を返す
 func (uc *SearchUseCase) GetSearchStatistics(ctx context.Context, index string) (map[string]any, error) {
 	// 入力を検証
@@ -253,54 +376,199 @@ func (uc *SearchUseCase) GetSearchStatistics(ctx context.Context, index string)
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "インデックスは空にできません")
 	}
 
-	// 通常はElasticsearchから統計を収集する
-	// 今はモック統計を返す
-	stats := map[string]any{
-		"index":            index,
-		"total_documents":  0,
-		"search_count":     0,
-		"average_response": "0ms",
-		"popular_queries":  []string{},
-		"recent_searches":  []string{},
-		"failed_searches":  0,
-		"success_rate":     "100%",
+	// インデックス統計からドキュメント数を取得
+	indexStats, err := uc.indexService.GetIndexStats(ctx, index)
+	if err != nil {
+		return nil, err
 	}
 
+	// プロセス内で集計した検索メトリクスと合わせて返す
+	stats := uc.metrics.Snapshot(index)
+	stats["index"] = index
+	stats["total_documents"] = indexStats["doc_count"]
+
 	return stats, nil
 }
 
 // ValidateSearchQuery は検索クエリを実行せずに検証する
-func (uc *SearchUseCase) ValidateSearchQuery(ctx context.Context, req *dto.SearchRequest) error {
+// remote が true の場合は、構築したクエリを Elasticsearch の _validate/query でも検証し、
+// 対象インデックスのマッピングと合わないクエリを valid=false と説明付きで返す
+func (uc *SearchUseCase) ValidateSearchQuery(ctx context.Context, req *dto.SearchRequest, remote bool) (*dto.QueryValidationResponse, error) {
+	// リクエストを検証
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// デフォルト値を設定
+	req.SetDefaults()
+
+	if !remote {
+		return &dto.QueryValidationResponse{Valid: true, Index: req.Index}, nil
+	}
+
+	query := uc.requestToQuery(req)
+	validation, err := uc.searchService.ValidateSearch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	explanations := make([]dto.QueryExplanationDTO, len(validation.Explanations))
+	for i, explanation := range validation.Explanations {
+		explanations[i] = dto.QueryExplanationDTO{
+			Index:       explanation.Index,
+			Valid:       explanation.Valid,
+			Explanation: explanation.Explanation,
+			Error:       explanation.Error,
+		}
+	}
+
+	return &dto.QueryValidationResponse{
+		Valid:        validation.Valid,
+		Index:        query.Index,
+		Query:        validation.Query,
+		Explanations: explanations,
+		Error:        validation.Error,
+	}, nil
+}
+
+// requestToQuery は検索リクエストDTOを検索クエリエンティティに変換するヘルパーメソッド
+func (uc *SearchUseCase) requestToQuery(req *dto.SearchRequest) *entity.SearchQuery {
+	query := entity.NewSearchQuery(req.Query)
+	query.Mode = entity.SearchMode(req.Mode)
+	query.Fields = req.Fields
+	query.DefaultOperator = req.DefaultOperator
+	query.Analyzer = req.Analyzer
+	query.SetIndex(req.Index)
+	query.SetPagination(req.From, req.Size)
+	query.MinScore = req.MinScore
+	query.ExcludeSource = req.Source != nil && !*req.Source
+	query.Profile = req.Profile
+	query.Explain = req.Explain
+	query.SeqNoPrimaryTerm = req.SeqNoPrimaryTerm
+	query.Debug = req.Debug
+	if req.Collapse != nil {
+		query.Collapse = &entity.CollapseOption{
+			Field:         req.Collapse.Field,
+			InnerHitsSize: req.Collapse.InnerHitsSize,
+		}
+	}
+	if req.FunctionScore != nil {
+		query.FunctionScore = functionScoreToEntity(req.FunctionScore)
+	}
+	if req.Nested != nil {
+		query.Nested = &entity.NestedQuery{
+			Path:          req.Nested.Path,
+			Must:          req.Nested.Must,
+			Filter:        req.Nested.Filter,
+			InnerHitsSize: req.Nested.InnerHitsSize,
+		}
+	}
+	query.StoredFields = req.StoredFields
+	query.DocValueFields = req.DocValueFields
+	if req.Highlight != nil {
+		query.Highlight = &entity.Highlight{}
+		for _, field := range req.Highlight.Fields {
+			query.Highlight.Fields = append(query.Highlight.Fields, entity.HighlightField{
+				Field:             field.Field,
+				FragmentSize:      field.FragmentSize,
+				NumberOfFragments: field.NumberOfFragments,
+				Order:             field.Order,
+			})
+		}
+	}
+
+	// 空のフィルターは除外する
+	for field, value := range req.Filters {
+		if field != "" && value != "" {
+			query.AddFilter(field, value)
+		}
+	}
+
+	// ソートフィールドを変換（ソート順序は大文字小文字を区別しない）
+	for _, sort := range req.Sort {
+		query.AddSort(sort.Field, entity.NormalizeSortOrder(sort.Order))
+	}
+
+	return query
+}
+
+// searchOptions は検索リクエストDTOからシャードの選択やインデックスの解決方法などの検索オプションを組み立てる
+func searchOptions(req *dto.SearchRequest) []repository.SearchOption {
+	opts := []repository.SearchOption{
+		repository.WithPreference(req.Preference),
+		repository.WithSearchRouting(req.Routing),
+		repository.WithIgnoreUnavailable(req.IgnoreUnavailable),
+	}
+	if states := req.ExpandWildcardStates(); len(states) > 0 {
+		opts = append(opts, repository.WithExpandWildcards(states...))
+	}
+	if req.AllowNoIndices != nil {
+		opts = append(opts, repository.WithAllowNoIndices(*req.AllowNoIndices))
+	}
+	return opts
+}
+
+// functionScoreToEntity は関数スコア設定DTOをエンティティに変換する
+func functionScoreToEntity(req *dto.FunctionScoreDTO) *entity.FunctionScore {
+	fs := &entity.FunctionScore{
+		BoostMode: req.BoostMode,
+	}
+	if fvf := req.FieldValueFactor; fvf != nil {
+		fs.FieldValueFactor = &entity.FieldValueFactor{
+			Field:    fvf.Field,
+			Factor:   fvf.Factor,
+			Modifier: fvf.Modifier,
+			Missing:  fvf.Missing,
+		}
+	}
+	if decay := req.Decay; decay != nil {
+		fs.Decay = &entity.DecayFunction{
+			Function: entity.DecayFunctionType(decay.Function),
+			Field:    decay.Field,
+			Origin:   decay.Origin,
+			Scale:    decay.Scale,
+			Offset:   decay.Offset,
+			Decay:    decay.Decay,
+		}
+	}
+	return fs
+}
+
+// RenderSearchQuery は検索を実行せずに、送信されるElasticsearchクエリを返す
+func (uc *SearchUseCase) RenderSearchQuery(ctx context.Context, req *dto.SearchRequest) (*dto.RenderedQueryResponse, error) {
 	// リクエストを検証
 	if err := req.Validate(); err != nil {
-		return err
+		return nil, err
 	}
 
-	// 追加のクエリ検証をここに追加できる
-	// 例：悪意のあるクエリのチェック、構文検証など
+	// デフォルト値を設定
+	req.SetDefaults()
+
+	query := uc.requestToQuery(req)
+	rendered, err := uc.searchService.RenderSearch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return &dto.RenderedQueryResponse{
+		Index: query.Index,
+		Query: rendered,
+	}, nil
 }
 
 // entityToDTO はエンティティをDTOに変換するヘルパーメソッド
 func (uc *SearchUseCase) entityToDTO(result *entity.SearchResult) *dto.SearchResponse {
-	hits := make([]dto.HitDTO, len(result.Hits))
-	for i, hit := range result.Hits {
-		hits[i] = dto.HitDTO{
-			Index:  hit.Index,
-			ID:     hit.ID,
-			Score:  hit.Score,
-			Source: hit.Source,
-		}
-	}
+	hits := hitsToDTO(result.Hits)
 
 	// クエリを変換
 	queryDTO := dto.SearchQueryDTO{
-		Query:   result.Query.Query,
-		Index:   result.Query.Index,
-		Filters: result.Query.Filters,
-		From:    result.Query.From,
-		Size:    result.Query.Size,
+		Query:    result.Query.Query,
+		Mode:     string(result.Query.Mode),
+		Index:    result.Query.Index,
+		Filters:  result.Query.Filters,
+		From:     result.Query.From,
+		Size:     result.Query.Size,
+		MinScore: result.Query.MinScore,
 	}
 
 	// ソートフィールドを変換
@@ -312,11 +580,115 @@ func (uc *SearchUseCase) entityToDTO(result *entity.SearchResult) *dto.SearchRes
 	}
 
 	return &dto.SearchResponse{
-		Query:    queryDTO,
-		Results:  hits,
-		Total:    result.Total,
-		MaxScore: result.MaxScore,
-		Took:     result.Took,
-		TimedOut: result.TimedOut,
+		Query:         queryDTO,
+		Results:       hits,
+		Total:         result.Total,
+		TotalRelation: result.TotalRelation,
+		MaxScore:      result.MaxScore,
+		Took:          result.Took,
+		TimedOut:      result.TimedOut,
+		Error:         result.Error,
+		Profile:       profileToDTO(result.Profile),
+		AppliedRules:  appliedRulesToDTO(result.Query.AppliedRules),
+	}
+}
+
+// appliedRulesToDTO は適用したビジネスルールをDTOに変換する
+func appliedRulesToDTO(rules []entity.AppliedRule) []dto.AppliedRuleDTO {
+	if len(rules) == 0 {
+		return nil
+	}
+	dtos := make([]dto.AppliedRuleDTO, len(rules))
+	for i, rule := range rules {
+		dtos[i] = dto.AppliedRuleDTO{Rule: rule.Rule, Description: rule.Description}
+	}
+	return dtos
+}
+
+// profileToDTO は処理時間の内訳をDTOに変換する
+func profileToDTO(profile *entity.SearchProfile) *dto.SearchProfileDTO {
+	if profile == nil {
+		return nil
+	}
+
+	profileDTO := &dto.SearchProfileDTO{
+		Shards: make([]dto.ShardProfileDTO, len(profile.Shards)),
+		Fields: make([]dto.FieldTimingDTO, len(profile.Fields)),
+	}
+	for i, shard := range profile.Shards {
+		profileDTO.Shards[i] = dto.ShardProfileDTO{
+			ID:             shard.ID,
+			Index:          shard.Index,
+			Shard:          shard.Shard,
+			QueryNanos:     shard.QueryNanos,
+			RewriteNanos:   shard.RewriteNanos,
+			CollectNanos:   shard.CollectNanos,
+			FetchNanos:     shard.FetchNanos,
+			AggregateNanos: shard.AggregateNanos,
+			Queries:        queryProfilesToDTO(shard.Queries),
+		}
+	}
+	for i, field := range profile.Fields {
+		profileDTO.Fields[i] = dto.FieldTimingDTO{Field: field.Field, Nanos: field.Nanos}
+	}
+	return profileDTO
+}
+
+// explanationToDTO はスコアの計算過程（子の計算過程を含む）をDTOに変換する
+func explanationToDTO(explanation entity.Explanation) dto.ExplanationDTO {
+	explanationDTO := dto.ExplanationDTO{
+		Value:       explanation.Value,
+		Description: explanation.Description,
+	}
+	for _, detail := range explanation.Details {
+		explanationDTO.Details = append(explanationDTO.Details, explanationToDTO(detail))
+	}
+	return explanationDTO
+}
+
+// queryProfilesToDTO はクエリの実行時間（子クエリを含む）をDTOに変換する
+func queryProfilesToDTO(queries []entity.QueryProfile) []dto.QueryProfileDTO {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	dtos := make([]dto.QueryProfileDTO, len(queries))
+	for i, query := range queries {
+		dtos[i] = dto.QueryProfileDTO{
+			Type:        query.Type,
+			Description: query.Description,
+			Nanos:       query.Nanos,
+			Children:    queryProfilesToDTO(query.Children),
+		}
+	}
+	return dtos
+}
+
+// hitsToDTO はヒットをDTOに変換する（コラプスされたヒットとネストのインナーヒットを含む）
+func hitsToDTO(hits []entity.Hit) []dto.HitDTO {
+	dtos := make([]dto.HitDTO, len(hits))
+	for i, hit := range hits {
+		dtos[i] = dto.HitDTO{
+			Index:        hit.Index,
+			ID:           hit.ID,
+			Score:        hit.Score,
+			Source:       hit.Source,
+			MatchQuality: hit.MatchQuality,
+			Highlight:    hit.Highlight,
+			Fields:       hit.Fields,
+			SeqNo:        hit.SeqNo,
+			PrimaryTerm:  hit.PrimaryTerm,
+		}
+		if hit.Explanation != nil {
+			explanation := explanationToDTO(*hit.Explanation)
+			dtos[i].Explanation = &explanation
+		}
+		if len(hit.Collapsed) > 0 {
+			dtos[i].Collapsed = hitsToDTO(hit.Collapsed)
+		}
+		if len(hit.Nested) > 0 {
+			dtos[i].Nested = hitsToDTO(hit.Nested)
+		}
 	}
+	return dtos
 }
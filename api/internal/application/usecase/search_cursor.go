@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// searchCursor はページングのカーソルの中身を表す
+// ソート値は発行時の検索と同じインデックス・ソートの場合のみ有効なため、その組み合わせのハッシュを含める
+type searchCursor struct {
+	Key    string `json:"k"`
+	Values []any  `json:"v"`
+}
+
+// cursorKey はカーソルを発行・利用できる検索（インデックスとソート）を識別するキーを返す
+func cursorKey(req *dto.SearchRequest) string {
+	var b strings.Builder
+	b.WriteString(req.Index)
+	for _, sort := range req.Sort {
+		b.WriteString("\n" + sort.Field + ":" + entity.NormalizeSortOrder(sort.Order))
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodeCursor は最後のヒットのソート値をカーソル文字列にする
+func encodeCursor(req *dto.SearchRequest, values []any) (string, error) {
+	payload, err := json.Marshal(searchCursor{Key: cursorKey(req), Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// decodeCursor はカーソル文字列を検証し、search_after に渡すソート値を返す
+// 大きな整数値が丸められないよう、数値は json.Number のまま扱う
+func decodeCursor(req *dto.SearchRequest) ([]any, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+	if err != nil {
+		return nil, cursorError(dto.ErrInvalidCursor)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var cursor searchCursor
+	if err := decoder.Decode(&cursor); err != nil || len(cursor.Values) == 0 {
+		return nil, cursorError(dto.ErrInvalidCursor)
+	}
+	if cursor.Key != cursorKey(req) {
+		return nil, cursorError(dto.ErrCursorMismatch)
+	}
+	return cursor.Values, nil
+}
+
+// cursorError はカーソルのバリデーションエラーを返す
+func cursorError(err *dto.ValidationError) error {
+	return errors.NewFieldValidationError([]errors.FieldError{{Field: "cursor", Message: err.Message}})
+}
+
+// queryWithCursor はリクエストを検索クエリに変換し、カーソルが指定されていれば search_after に設定する
+func (uc *SearchUseCase) queryWithCursor(req *dto.SearchRequest) (*entity.SearchQuery, error) {
+	query := uc.requestToQuery(req)
+	if req.Cursor == "" {
+		return query, nil
+	}
+
+	values, err := decodeCursor(req)
+	if err != nil {
+		return nil, err
+	}
+	query.SearchAfter = values
+	return query, nil
+}
+
+// setCursors は使用したカーソルと次のページのカーソルをレスポンスに設定する
+// ヒットが要求した件数に満たない場合は最終ページとして next_cursor を null にする
+// ソートが一意な値のフィールドで終わらない場合、同じソート値のヒットがページの境界で欠落・重複するためカーソルは発行しない
+func setCursors(response *dto.SearchResponse, req *dto.SearchRequest, result *entity.SearchResult) {
+	response.Cursor = req.Cursor
+	if len(result.Hits) == 0 || len(result.Hits) < result.Query.Size || !result.Query.UniqueSort {
+		return
+	}
+
+	last := result.Hits[len(result.Hits)-1].Sort
+	if len(last) == 0 {
+		return
+	}
+	if next, err := encodeCursor(req, last); err == nil {
+		response.NextCursor = &next
+	}
+}
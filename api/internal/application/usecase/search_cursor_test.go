@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+func TestSetCursors(t *testing.T) {
+	tests := []struct {
+		name       string
+		hits       int
+		uniqueSort bool
+		wantNext   bool
+	}{
+		{name: "full page with unique sort", hits: 2, uniqueSort: true, wantNext: true},
+		{name: "full page without unique sort", hits: 2, uniqueSort: false, wantNext: false},
+		{name: "last page", hits: 1, uniqueSort: true, wantNext: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &dto.SearchRequest{Index: "articles", Sort: []dto.SortFieldDTO{{Field: "price", Order: "asc"}}}
+			result := entity.NewSearchResult(entity.SearchQuery{Index: "articles", Size: 2, UniqueSort: tt.uniqueSort})
+			for i := 0; i < tt.hits; i++ {
+				result.AddHit(entity.Hit{ID: "doc", Sort: []any{float64(i), "key"}})
+			}
+
+			response := &dto.SearchResponse{}
+			setCursors(response, req, result)
+
+			if got := response.NextCursor != nil; got != tt.wantNext {
+				t.Fatalf("next_cursor set = %v, want %v", got, tt.wantNext)
+			}
+			if !tt.wantNext {
+				return
+			}
+
+			// 発行したカーソルは同じインデックス・ソートの検索で最後のヒットのソート値に戻る
+			next := &dto.SearchRequest{Index: "articles", Sort: req.Sort, Cursor: *response.NextCursor}
+			values, err := decodeCursor(next)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if len(values) != 2 || values[1] != "key" {
+				t.Errorf("decodeCursor() = %v, want the last hit's sort values", values)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// iteratorTiebreakerField は同じソート値のヒットの順序を確定させるためのフィールド
+const iteratorTiebreakerField = "_doc"
+
+// SearchIterator は search_after を使って検索結果の全ページを順に取得する
+// 取得中にインデックスが更新された場合、ヒットの欠落や重複が起こり得る
+//
+//	it := searchUseCase.Iterate(ctx, req)
+//	for it.Next() {
+//		for _, hit := range it.Hits() {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SearchIterator struct {
+	uc          *SearchUseCase
+	ctx         context.Context
+	req         dto.SearchRequest
+	searchAfter []any
+	hits        []dto.HitDTO
+	total       int64
+	err         error
+	done        bool
+}
+
+// Iterate はリクエストに一致する全てのヒットをバッチ単位で返すイテレーターを作成する
+// req.Size は1バッチあたりの件数として使われ、req.From は無視される
+func (uc *SearchUseCase) Iterate(ctx context.Context, req *dto.SearchRequest) *SearchIterator {
+	it := &SearchIterator{
+		uc:  uc,
+		ctx: ctx,
+		req: *req,
+	}
+	it.req.From = 0
+
+	// リクエストを検証
+	if err := it.req.Validate(); err != nil {
+		it.err = err
+	}
+
+	return it
+}
+
+// Next は次のバッチを取得し、取得できた場合に true を返す
+// 結果を全て取得した場合やエラーが発生した場合は false を返す
+func (it *SearchIterator) Next() bool {
+	it.hits = nil
+	if it.done || it.err != nil {
+		return false
+	}
+
+	// クライアントの切断やタイムアウトで中断する
+	if err := it.ctx.Err(); err != nil {
+		it.err = errors.WrapError(err, errors.ErrCodeSearchFailed, "Search iteration aborted")
+		return false
+	}
+
+	query := it.uc.requestToQuery(&it.req)
+	if len(query.Sort) == 0 {
+		query.AddSort("_score", "desc")
+	}
+	if last := query.Sort[len(query.Sort)-1].Field; last != iteratorTiebreakerField && last != "_id" {
+		query.AddSort(iteratorTiebreakerField, "asc")
+	}
+	query.SearchAfter = it.searchAfter
+
+	start := time.Now()
+	result, err := it.uc.searchService.ExecuteSearch(it.ctx, query, searchOptions(&it.req)...)
+	it.uc.metrics.Record(query.Index, query.Query, time.Since(start), err)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	if len(result.Hits) == 0 {
+		it.done = true
+		return false
+	}
+
+	// 次のページのカーソルを記録する（件数が満たない場合は最終ページ）
+	it.searchAfter = result.Hits[len(result.Hits)-1].Sort
+	if len(it.searchAfter) == 0 || len(result.Hits) < query.Size {
+		it.done = true
+	}
+
+	it.hits = hitsToDTO(result.Hits)
+	it.total = result.Total
+	return true
+}
+
+// Hits は直前の Next で取得したバッチを返す
+func (it *SearchIterator) Hits() []dto.HitDTO {
+	return it.hits
+}
+
+// Total は一致したドキュメントの総数を返す
+func (it *SearchIterator) Total() int64 {
+	return it.total
+}
+
+// Err は反復中に発生したエラーを返す
+func (it *SearchIterator) Err() error {
+	return it.err
+}
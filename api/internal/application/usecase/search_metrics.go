@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentQueryCapacity は保持する直近クエリの最大数
+const recentQueryCapacity = 100
+
+// popularQueryLimit は返す人気クエリの最大数
+const popularQueryLimit = 10
+
+// allIndicesKey はインデックス未指定の検索を集計するキー
+const allIndicesKey = "_all"
+
+// maxTrackedIndices は個別に集計するインデックス名の最大数
+// インデックス名はリクエストで任意に指定できるため、上限を超えた名前は otherIndicesKey にまとめてメモリの増加を防ぐ
+const maxTrackedIndices = 100
+
+// otherIndicesKey は個別に集計する上限を超えたインデックスの検索を集計するキー
+const otherIndicesKey = "_other"
+
+// SearchMetrics はインデックスごとの検索メトリクスをプロセス内で集計する
+type SearchMetrics struct {
+	mu      sync.Mutex
+	indices map[string]*indexSearchMetrics
+}
+
+// indexSearchMetrics は単一インデックスの検索メトリクスを表す
+type indexSearchMetrics struct {
+	searchCount  int64
+	failedCount  int64
+	totalLatency time.Duration
+	recent       []string
+	next         int
+}
+
+// NewSearchMetrics は新しい SearchMetrics を作成する
+func NewSearchMetrics() *SearchMetrics {
+	return &SearchMetrics{
+		indices: make(map[string]*indexSearchMetrics),
+	}
+}
+
+// Record は検索1回分の結果を記録する
+func (m *SearchMetrics) Record(index, query string, latency time.Duration, err error) {
+	if index == "" {
+		index = allIndicesKey
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics, ok := m.indices[index]
+	if !ok {
+		if len(m.indices) >= maxTrackedIndices {
+			index = otherIndicesKey
+			metrics = m.indices[index]
+		}
+		if metrics == nil {
+			metrics = &indexSearchMetrics{}
+			m.indices[index] = metrics
+		}
+	}
+
+	metrics.searchCount++
+	metrics.totalLatency += latency
+	if err != nil {
+		metrics.failedCount++
+	}
+
+	// 直近クエリをリングバッファに保持
+	if len(metrics.recent) < recentQueryCapacity {
+		metrics.recent = append(metrics.recent, query)
+	} else {
+		metrics.recent[metrics.next] = query
+	}
+	metrics.next = (metrics.next + 1) % recentQueryCapacity
+}
+
+// Snapshot はインデックスの検索メトリクスを返す
+func (m *SearchMetrics) Snapshot(index string) map[string]any {
+	if index == "" {
+		index = allIndicesKey
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics, ok := m.indices[index]
+	if !ok {
+		metrics = &indexSearchMetrics{}
+	}
+
+	averageResponse := time.Duration(0)
+	successRate := 100.0
+	if metrics.searchCount > 0 {
+		averageResponse = metrics.totalLatency / time.Duration(metrics.searchCount)
+		successRate = float64(metrics.searchCount-metrics.failedCount) / float64(metrics.searchCount) * 100
+	}
+
+	recent := metrics.recentQueries()
+
+	return map[string]any{
+		"search_count":     metrics.searchCount,
+		"average_response": fmt.Sprintf("%dms", averageResponse.Milliseconds()),
+		"popular_queries":  popularQueries(recent, popularQueryLimit),
+		"recent_searches":  recent,
+		"failed_searches":  metrics.failedCount,
+		"success_rate":     fmt.Sprintf("%.1f%%", successRate),
+	}
+}
+
+// recentQueries は直近クエリを新しい順に返す
+func (im *indexSearchMetrics) recentQueries() []string {
+	queries := make([]string, 0, len(im.recent))
+	for i := 1; i <= len(im.recent); i++ {
+		pos := (im.next - i + len(im.recent)) % len(im.recent)
+		queries = append(queries, im.recent[pos])
+	}
+	return queries
+}
+
+// popularQueries は出現回数の多い順にクエリを返す
+func popularQueries(queries []string, limit int) []string {
+	counts := make(map[string]int)
+	for _, query := range queries {
+		counts[query]++
+	}
+
+	popular := make([]string, 0, len(counts))
+	for query := range counts {
+		popular = append(popular, query)
+	}
+	sort.Slice(popular, func(i, j int) bool {
+		if counts[popular[i]] != counts[popular[j]] {
+			return counts[popular[i]] > counts[popular[j]]
+		}
+		return popular[i] < popular[j]
+	})
+
+	if len(popular) > limit {
+		popular = popular[:limit]
+	}
+	return popular
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSearchMetricsRecordCapsIndexCardinality(t *testing.T) {
+	metrics := NewSearchMetrics()
+
+	for i := range maxTrackedIndices + 50 {
+		metrics.Record(fmt.Sprintf("random-%d", i), "q", time.Millisecond, nil)
+	}
+	// 既に集計しているインデックスは上限後も個別に集計する
+	metrics.Record("random-0", "q", time.Millisecond, nil)
+
+	if got := len(metrics.indices); got != maxTrackedIndices+1 {
+		t.Fatalf("tracked keys = %d, want %d plus the other bucket", got, maxTrackedIndices)
+	}
+	if got := metrics.Snapshot(otherIndicesKey)["search_count"]; got != int64(50) {
+		t.Errorf("other bucket search_count = %v, want 50", got)
+	}
+	if got := metrics.Snapshot("random-0")["search_count"]; got != int64(2) {
+		t.Errorf("random-0 search_count = %v, want 2", got)
+	}
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+func TestRequestToQuerySeqNoPrimaryTerm(t *testing.T) {
+	uc := NewSearchUseCase(nil, nil)
+
+	for _, enabled := range []bool{false, true} {
+		query := uc.requestToQuery(&dto.SearchRequest{Query: "golang", SeqNoPrimaryTerm: enabled})
+		if query.SeqNoPrimaryTerm != enabled {
+			t.Errorf("SeqNoPrimaryTerm = %v, want %v", query.SeqNoPrimaryTerm, enabled)
+		}
+	}
+}
+
+func TestHitsToDTOSeqNoPrimaryTerm(t *testing.T) {
+	seqNo, primaryTerm := int64(42), int64(3)
+	hits := hitsToDTO([]entity.Hit{
+		{Index: "articles", ID: "1", SeqNo: &seqNo, PrimaryTerm: &primaryTerm},
+		{Index: "articles", ID: "2"},
+	})
+
+	with, err := json.Marshal(hits[0])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(with), `"seq_no":42`) || !strings.Contains(string(with), `"primary_term":3`) {
+		t.Errorf("hit = %s, want seq_no 42 and primary_term 3", with)
+	}
+
+	// seq_no_primary_term を指定しない検索のヒットには含めない
+	without, err := json.Marshal(hits[1])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(without), "seq_no") || strings.Contains(string(without), "primary_term") {
+		t.Errorf("hit = %s, want no seq_no or primary_term", without)
+	}
+}
@@ -1,16 +1,22 @@
 package container
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/Yuki-TU/elastic-search/api/config"
 	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/service"
+	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/audit"
 	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/elasticsearch"
+	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/tenancy"
 	"github.com/Yuki-TU/elastic-search/api/internal/interface/handler"
 	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
 )
 
 // Container は全ての依存関係を保持する
@@ -20,21 +26,34 @@ type Container struct {
 
 	// インフラストラクチャ
 	ElasticsearchClient *elasticsearch.Client
-	ElasticsearchRepo   repository.ElasticsearchRepository
-	Logger              *log.Logger
+	// ElasticsearchClusters は監視対象の全クラスターのクライアント（先頭は ElasticsearchClient）
+	ElasticsearchClusters []*elasticsearch.Client
+	ElasticsearchRepo     repository.ElasticsearchRepository
+	// TenantRepo はドメインサービスが使うリポジトリ（マルチテナンシー有効時はテナントのインデックスに限定する）
+	TenantRepo  repository.ElasticsearchRepository
+	Logger      *log.Logger
+	AuditLogger service.AuditLogger
 
 	// ドメインサービス
 	DocumentService *service.DocumentService
 	SearchService   *service.SearchService
+	IndexService    *service.IndexService
+	// AsyncIndexer は非同期登録のキュー（ワーカーはサーバーが開始・停止する）
+	AsyncIndexer *service.AsyncIndexer
 
 	// ユースケース
 	DocumentUseCase *usecase.DocumentUseCase
 	SearchUseCase   *usecase.SearchUseCase
+	IndexUseCase    *usecase.IndexUseCase
 
 	// ハンドラー
 	DocumentHandler *handler.DocumentHandler
 	SearchHandler   *handler.SearchHandler
 	HealthHandler   *handler.HealthHandler
+	InfoHandler     *handler.InfoHandler
+	IndexHandler    *handler.IndexHandler
+	MetricsHandler  *handler.MetricsHandler
+	CacheHandler    *handler.CacheHandler
 
 	// ミドルウェア
 	LoggingMiddleware *middleware.LoggingMiddleware
@@ -56,7 +75,9 @@ func NewContainer() (*Container, error) {
 	}
 
 	// ドメインサービスを初期化
-	container.initDomainServices()
+	if err := container.initDomainServices(); err != nil {
+		return nil, err
+	}
 
 	// ユースケースを初期化
 	container.initUseCases()
@@ -74,58 +95,205 @@ func NewContainer() (*Container, error) {
 func (c *Container) initInfrastructure() error {
 	var err error
 
-	// Elasticsearchクライアントを初期化
-	c.ElasticsearchClient, err = elasticsearch.NewClient(c.Config)
+	// Elasticsearchクライアントを初期化（追加クラスターはヘルスチェックでのみ使う）
+	c.ElasticsearchClusters, err = elasticsearch.NewClusterClients(c.Config, c.Config.ElasticsearchClusters)
 	if err != nil {
 		return err
 	}
+	c.ElasticsearchClient = c.ElasticsearchClusters[0]
 
 	// Elasticsearchリポジトリを初期化
 	c.ElasticsearchRepo = elasticsearch.NewRepository(c.ElasticsearchClient)
 
+	// マルチテナンシー有効時はインデックス名にテナントのプレフィックスを付与する
+	// ヘルスチェックはテナントに関わらず実際のインデックスを確認するため、元のリポジトリを使う
+	c.TenantRepo = c.ElasticsearchRepo
+	if c.Config.TenancyEnabled {
+		if err := validateTenantCredentials(c.Config); err != nil {
+			return err
+		}
+		c.TenantRepo, err = tenancy.NewRepository(c.ElasticsearchRepo, c.Config.TenantIndexPrefix)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 監査ロガーを初期化
+	c.AuditLogger, err = newAuditLogger(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newAuditLogger は設定に応じた監査ロガーを作成する（無効な場合は何も記録しない）
+func newAuditLogger(cfg *config.Config) (service.AuditLogger, error) {
+	if !cfg.AuditLogEnabled {
+		return service.NopAuditLogger{}, nil
+	}
+	if cfg.AuditLogFile == "" {
+		return audit.NewJSONLogger(os.Stdout), nil
+	}
+
+	file, err := os.OpenFile(cfg.AuditLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return audit.NewJSONLogger(file), nil
+}
+
+// validateTenantCredentials はテナントをクライアントが自由に選べない設定になっているかを検証する
+// テナント用APIキーがなければ、ヘッダーを信頼できるゲートウェイの背後であることの明示が必要
+func validateTenantCredentials(cfg *config.Config) error {
+	if len(cfg.TenantAPIKeys) == 0 && !cfg.TenantHeaderTrusted {
+		return fmt.Errorf("TENANCY_ENABLED requires TENANT_API_KEYS, or TENANT_HEADER_TRUSTED=true behind a gateway that sets %s", cfg.TenantHeader)
+	}
+	for key, tenant := range cfg.TenantAPIKeys {
+		if key == "" || !middleware.ValidTenantID(tenant) {
+			return fmt.Errorf("invalid TENANT_API_KEYS entry for tenant %q: keys must be non-empty and tenant IDs lowercase alphanumeric", tenant)
+		}
+	}
 	return nil
 }
 
 // initDomainServices はドメインサービスを初期化する
-func (c *Container) initDomainServices() {
-	// ドキュメントサービスを初期化
-	c.DocumentService = service.NewDocumentService(c.ElasticsearchRepo)
+func (c *Container) initDomainServices() error {
+	// ドキュメントサービスを初期化（リフレッシュの方法は起動時に検証する）
+	documentConfig := &service.DocumentConfig{
+		DefaultPipelines:      c.Config.IngestPipelines,
+		DefaultIndex:          c.Config.DefaultIndex,
+		AuditLogger:           c.AuditLogger,
+		AuditIncludeSource:    c.Config.AuditLogIncludeSource,
+		BulkChunkMaxDocs:      c.Config.BulkChunkMaxDocs,
+		BulkChunkMaxBytes:     c.Config.BulkChunkMaxBytes,
+		BulkConcurrency:       c.Config.BulkConcurrency,
+		UpdateRetryOnConflict: c.Config.UpdateRetryOnConflict,
+		WriteRefresh:          entity.RefreshPolicy(strings.ToLower(c.Config.WriteRefresh)),
+		BulkRefresh:           entity.RefreshPolicy(strings.ToLower(c.Config.BulkRefresh)),
+		IdempotencyKeyTTL:     c.Config.IdempotencyKeyTTL,
+		IdempotencyMaxKeys:    c.Config.IdempotencyMaxKeys,
+	}
+	if err := documentConfig.Validate(); err != nil {
+		return err
+	}
+	c.DocumentService = service.NewDocumentServiceWithConfig(c.TenantRepo, documentConfig)
+
+	// 検索サービスを初期化（デフォルトソートは起動時に検証する）
+	searchConfig := &service.SearchConfig{
+		DefaultSize:      c.Config.SearchDefaultSize,
+		MaxSize:          c.Config.SearchMaxSize,
+		DefaultOperator:  c.Config.SearchDefaultOperator,
+		QueryFields:      c.Config.SearchQueryFields,
+		AllowedAnalyzers: c.Config.SearchAllowedAnalyzers,
+		CursorTiebreaker: c.Config.SearchCursorTiebreaker,
+		QuerySanitizePolicy: service.QuerySanitizePolicy{
+			StripChars:  c.Config.SearchQueryStripChars,
+			EscapeChars: c.Config.SearchQueryEscapeChars,
+		},
+		FieldBoosts:  c.Config.SearchFieldBoosts,
+		DefaultSorts: defaultSorts(c.Config.SearchDefaultSorts),
+		DefaultIndex: c.Config.DefaultIndex,
+
+		ResultWindowCacheTTL: c.Config.SearchResultWindowCacheTTL,
+		CheckIndexExists:     c.Config.SearchCheckIndexExists,
+		IndexExistsCacheTTL:  c.Config.SearchIndexExistsCacheTTL,
+		FieldValidation:      service.FieldValidationMode(strings.ToLower(c.Config.SearchFieldValidation)),
+		FieldMappingCacheTTL: c.Config.SearchFieldMappingCacheTTL,
+		Logger:               c.Logger,
+
+		ResultCacheTTL:        c.Config.SearchResultCacheTTL,
+		ResultCacheMaxEntries: c.Config.SearchResultCacheMaxEntries,
+	}
+	if err := searchConfig.Validate(); err != nil {
+		return err
+	}
+	c.SearchService = service.NewSearchServiceWithConfig(c.TenantRepo, searchConfig)
+
+	// インデックスサービスを初期化
+	c.IndexService = service.NewIndexService(c.TenantRepo)
 
-	// 検索サービスを初期化
-	c.SearchService = service.NewSearchService(c.ElasticsearchRepo)
+	// 非同期インデクサーを初期化
+	c.AsyncIndexer = service.NewAsyncIndexer(c.DocumentService, &service.AsyncIndexerConfig{
+		QueueSize:     c.Config.AsyncIndexQueueSize,
+		BatchSize:     c.Config.AsyncIndexBatchSize,
+		FlushInterval: c.Config.AsyncIndexFlushInterval,
+		Logger:        c.Logger,
+	})
+
+	return nil
+}
+
+// defaultSorts はインデックスごとのデフォルトソート設定をエンティティに変換する
+func defaultSorts(sorts config.DefaultSorts) map[string][]entity.SortField {
+	converted := make(map[string][]entity.SortField, len(sorts))
+	for index, specs := range sorts {
+		for _, spec := range specs {
+			converted[index] = append(converted[index], entity.SortField{Field: spec.Field, Order: spec.Order})
+		}
+	}
+	return converted
 }
 
 // initUseCases はユースケースを初期化する
 func (c *Container) initUseCases() {
 	// ドキュメントユースケースを初期化
-	c.DocumentUseCase = usecase.NewDocumentUseCase(c.DocumentService)
+	c.DocumentUseCase = usecase.NewDocumentUseCase(c.DocumentService, c.AsyncIndexer)
 
 	// 検索ユースケースを初期化
-	c.SearchUseCase = usecase.NewSearchUseCase(c.SearchService)
+	c.SearchUseCase = usecase.NewSearchUseCase(c.SearchService, c.IndexService)
+
+	// インデックスユースケースを初期化
+	c.IndexUseCase = usecase.NewIndexUseCase(c.IndexService, c.SearchService)
 }
 
 // initHandlers はハンドラーを初期化する
 func (c *Container) initHandlers() {
+	// リクエストボディの解析設定を適用
+	utils.SetParseOptions(&utils.ParseOptions{
+		MaxDepth:              c.Config.RequestMaxDepth,
+		DisallowUnknownFields: c.Config.RequestDisallowUnknownFields,
+		MaxBodySize:           c.Config.RequestMaxBodySize,
+	})
+
 	// ドキュメントハンドラーを初期化
 	c.DocumentHandler = handler.NewDocumentHandler(c.DocumentUseCase)
 
 	// 検索ハンドラーを初期化
-	c.SearchHandler = handler.NewSearchHandler(c.SearchUseCase)
+	c.SearchHandler = handler.NewSearchHandler(c.SearchUseCase, c.Config.SearchCacheMaxAge, c.Config.SearchSessionPreference)
 
 	// ヘルスハンドラーを初期化
-	c.HealthHandler = handler.NewHealthHandler(c.ElasticsearchClient)
+	c.HealthHandler = handler.NewHealthHandler(c.ElasticsearchClusters, c.ElasticsearchRepo, c.Config.RequiredIndices, c.Config.RequiredIndicesMinStatus, c.Config.HealthCacheTTL)
+
+	// 情報ハンドラーを初期化
+	c.InfoHandler = handler.NewInfoHandler(c.ElasticsearchClient)
+
+	// インデックスハンドラーを初期化
+	c.IndexHandler = handler.NewIndexHandler(c.IndexUseCase)
+
+	// メトリクスハンドラーを初期化
+	c.MetricsHandler = handler.NewMetricsHandler(c.DocumentUseCase)
+
+	// キャッシュハンドラーを初期化
+	c.CacheHandler = handler.NewCacheHandler(c.SearchUseCase, c.HealthHandler)
 }
 
 // initMiddleware はミドルウェアを初期化する
 func (c *Container) initMiddleware() {
-	// ログミドルウェアを初期化
-	c.LoggingMiddleware = middleware.NewLoggingMiddleware(c.Logger)
+	// ログミドルウェアを初期化（不明なログレベルは info として扱う）
+	level, err := middleware.ParseLogLevel(c.Config.LogLevel)
+	if err != nil {
+		c.Logger.Printf("WARNING: %v, falling back to info", err)
+	}
+	c.LoggingMiddleware = middleware.NewLoggingMiddlewareWithLevel(c.Logger, level)
 }
 
 // Cleanup はクリーンアップ操作を実行する
 func (c *Container) Cleanup() error {
-	if c.ElasticsearchClient != nil {
-		return c.ElasticsearchClient.Close()
+	for _, client := range c.ElasticsearchClusters {
+		if err := client.Close(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -160,6 +328,11 @@ func (c *Container) GetSearchService() *service.SearchService {
 	return c.SearchService
 }
 
+// GetIndexService はインデックスサービスを返す
+func (c *Container) GetIndexService() *service.IndexService {
+	return c.IndexService
+}
+
 // GetDocumentUseCase はドキュメントユースケースを返す
 func (c *Container) GetDocumentUseCase() *usecase.DocumentUseCase {
 	return c.DocumentUseCase
@@ -170,6 +343,11 @@ func (c *Container) GetSearchUseCase() usecase.SearchUseCaser {
 	return c.SearchUseCase
 }
 
+// GetIndexUseCase はインデックスユースケースを返す
+func (c *Container) GetIndexUseCase() *usecase.IndexUseCase {
+	return c.IndexUseCase
+}
+
 // GetDocumentHandler はドキュメントハンドラーを返す
 func (c *Container) GetDocumentHandler() *handler.DocumentHandler {
 	return c.DocumentHandler
@@ -185,6 +363,31 @@ func (c *Container) GetHealthHandler() *handler.HealthHandler {
 	return c.HealthHandler
 }
 
+// GetInfoHandler は情報ハンドラーを返す
+func (c *Container) GetInfoHandler() *handler.InfoHandler {
+	return c.InfoHandler
+}
+
+// GetIndexHandler はインデックスハンドラーを返す
+func (c *Container) GetIndexHandler() *handler.IndexHandler {
+	return c.IndexHandler
+}
+
+// GetMetricsHandler はメトリクスハンドラーを返す
+func (c *Container) GetMetricsHandler() *handler.MetricsHandler {
+	return c.MetricsHandler
+}
+
+// GetCacheHandler はキャッシュハンドラーを返す
+func (c *Container) GetCacheHandler() *handler.CacheHandler {
+	return c.CacheHandler
+}
+
+// GetAsyncIndexer は非同期インデクサーを返す
+func (c *Container) GetAsyncIndexer() *service.AsyncIndexer {
+	return c.AsyncIndexer
+}
+
 // GetLoggingMiddleware はログミドルウェアを返す
 func (c *Container) GetLoggingMiddleware() *middleware.LoggingMiddleware {
 	return c.LoggingMiddleware
@@ -203,11 +406,18 @@ type ContainerInterface interface {
 	GetElasticsearchRepo() repository.ElasticsearchRepository
 	GetDocumentService() *service.DocumentService
 	GetSearchService() *service.SearchService
+	GetIndexService() *service.IndexService
 	GetDocumentUseCase() *usecase.DocumentUseCase
 	GetSearchUseCase() usecase.SearchUseCaser
+	GetIndexUseCase() *usecase.IndexUseCase
 	GetDocumentHandler() *handler.DocumentHandler
 	GetSearchHandler() *handler.SearchHandler
 	GetHealthHandler() *handler.HealthHandler
+	GetInfoHandler() *handler.InfoHandler
+	GetIndexHandler() *handler.IndexHandler
+	GetMetricsHandler() *handler.MetricsHandler
+	GetCacheHandler() *handler.CacheHandler
+	GetAsyncIndexer() *service.AsyncIndexer
 	GetLoggingMiddleware() *middleware.LoggingMiddleware
 	Cleanup() error
 }
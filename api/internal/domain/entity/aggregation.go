@@ -0,0 +1,34 @@
+package entity
+
+// FieldCapability はマッピング上のフィールドの型と、集約に使えるかどうかを表す
+type FieldCapability struct {
+	Types        []string `json:"types"`        // 対象インデックスでの型（インデックスごとに異なる場合は複数）
+	Aggregatable bool     `json:"aggregatable"` // 全ての対象インデックスで集約に使える場合のみ true
+}
+
+// HasType はフィールドが指定した型を含むかどうかを返す
+func (c *FieldCapability) HasType(fieldType string) bool {
+	for _, t := range c.Types {
+		if t == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+// TermsBucket はフィールドの値ごとのドキュメント数を表す
+type TermsBucket struct {
+	Key         any    `json:"key"`
+	KeyAsString string `json:"key_as_string,omitempty"` // 日付や真偽値などの表示用の値
+	DocCount    int64  `json:"doc_count"`
+}
+
+// TermsResult はフィールドの値ごとのドキュメント数の集計結果を表す
+type TermsResult struct {
+	Index         string        `json:"index"`
+	Field         string        `json:"field"`
+	Buckets       []TermsBucket `json:"buckets"`
+	OtherDocCount int64         `json:"other_doc_count"` // 上位に含まれなかった値のドキュメント数
+	Total         int64         `json:"total"`           // インデックスの全ドキュメント数
+	Took          int64         `json:"took"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// AuditOperation は監査対象の変更操作の種類を表す
+type AuditOperation string
+
+const (
+	AuditOperationCreate AuditOperation = "create"
+	AuditOperationUpdate AuditOperation = "update"
+	AuditOperationUpsert AuditOperation = "upsert"
+	AuditOperationDelete AuditOperation = "delete"
+)
+
+// AnonymousSubject は認証されていないリクエストの操作主体を表す
+const AnonymousSubject = "anonymous"
+
+// AuditEvent はドキュメントに対する変更操作の監査記録を表す
+// Source は設定で明示的に有効にした場合のみ含まれる
+type AuditEvent struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Operation  AuditOperation `json:"operation"`
+	Index      string         `json:"index"`
+	DocumentID string         `json:"document_id"`
+	Subject    string         `json:"subject"`
+	Source     map[string]any `json:"source,omitempty"`
+}
@@ -0,0 +1,105 @@
+package entity
+
+// BulkOpType はバルク操作で使用するアクション種別を表す
+type BulkOpType string
+
+const (
+	// BulkOpIndex は既存ドキュメントを上書きする（index アクション）
+	BulkOpIndex BulkOpType = "index"
+	// BulkOpCreate は既存IDを上書きせず競合として報告する（create アクション）
+	BulkOpCreate BulkOpType = "create"
+	// BulkOpUpsert は存在しなければ作成し、存在すれば既存ドキュメントにマージする（update アクション）
+	BulkOpUpsert BulkOpType = "upsert"
+)
+
+// IsValid はアクション種別がサポートされているかどうかを返す（空はデフォルトのindex）
+func (t BulkOpType) IsValid() bool {
+	switch t {
+	case "", BulkOpIndex, BulkOpCreate, BulkOpUpsert:
+		return true
+	}
+	return false
+}
+
+// BulkItemResult はバルク操作の個別アイテムの結果を表す
+type BulkItemResult struct {
+	Index     string `json:"index"`
+	ID        string `json:"id"`
+	Status    int    `json:"status"`
+	Result    string `json:"result,omitempty"` // "created" や "updated"、"noop"（upsert で変更がない場合）など
+	ErrorType string `json:"error_type,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Conflict  bool   `json:"conflict,omitempty"`   // 既存ドキュメントとの競合（create 時の既存IDなど）
+	ErrorCode string `json:"error_code,omitempty"` // 失敗の分類（APIのエラーレスポンスと同じエラーコード）
+}
+
+// Failed はアイテムが失敗したかどうかを返す（競合を含む）
+func (r BulkItemResult) Failed() bool {
+	return r.Error != "" || r.Status >= 300
+}
+
+// BulkProgress は分割送信中のバルク操作の進捗を表す（チャンクの送信が完了するたびに通知される）
+type BulkProgress struct {
+	CompletedChunks int `json:"completed_chunks"`
+	TotalChunks     int `json:"total_chunks"`
+	Processed       int `json:"processed"` // 送信が完了したドキュメント数
+	Total           int `json:"total"`
+	Errors          int `json:"errors"` // これまでに競合または失敗したドキュメント数
+}
+
+// BulkResult はバルク操作全体の結果を表す
+type BulkResult struct {
+	Took      int64            `json:"took"`
+	Items     []BulkItemResult `json:"items"`
+	Succeeded int              `json:"succeeded"`
+	Conflicts int              `json:"conflicts"`
+	Failed    int              `json:"failed"`
+}
+
+// AddItem はアイテムの結果を追加し、集計を更新する
+func (r *BulkResult) AddItem(item BulkItemResult) {
+	r.Items = append(r.Items, item)
+	switch {
+	case item.Conflict:
+		r.Conflicts++
+	case item.Failed():
+		r.Failed++
+	default:
+		r.Succeeded++
+	}
+}
+
+// Merge は別のバルク結果（分割送信したチャンクの結果など）のアイテムと所要時間を追加する
+func (r *BulkResult) Merge(other *BulkResult) {
+	r.Took += other.Took
+	for _, item := range other.Items {
+		r.AddItem(item)
+	}
+}
+
+// HasErrors は競合または失敗したアイテムがあるかどうかを返す
+func (r *BulkResult) HasErrors() bool {
+	return r.Conflicts > 0 || r.Failed > 0
+}
+
+// BulkValidationError はドキュメントの検証エラーのフィールドと理由を表す
+type BulkValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// BulkValidationFailure は検証に失敗したドキュメントを表す
+type BulkValidationFailure struct {
+	Position int                   `json:"position"` // リクエスト内のドキュメントの位置（0始まり）
+	Index    string                `json:"index"`
+	ID       string                `json:"id,omitempty"`
+	Errors   []BulkValidationError `json:"errors"`
+}
+
+// BulkValidation はバルク登録の事前検証（登録は行わない）の結果を表す
+type BulkValidation struct {
+	Total    int                     `json:"total"`
+	Valid    int                     `json:"valid"`
+	Invalid  int                     `json:"invalid"`
+	Failures []BulkValidationFailure `json:"failures"`
+}
@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldChange はフィールド単位の差分を表す
+type FieldChange struct {
+	Path string `json:"path"`          // ドット区切りのパス（配列の要素は "tags[0]"）
+	Old  any    `json:"old,omitempty"` // 変更前の値（追加の場合はなし）
+	New  any    `json:"new,omitempty"` // 変更後の値（削除の場合はなし）
+}
+
+// DocumentDiff は2つのソースのフィールド単位の差分を表す
+type DocumentDiff struct {
+	Added   []FieldChange `json:"added"`
+	Removed []FieldChange `json:"removed"`
+	Changed []FieldChange `json:"changed"`
+}
+
+// HasChanges は差分があるかどうかを返す
+func (d *DocumentDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// DiffSources は現在のソースと変更案のソースをフィールド単位で比較する
+// ネストしたオブジェクトは再帰的に比較し、配列は要素の位置ごとに比較する
+// 数値は表現（json.Number か float64 か）に関わらず値で比較する
+func DiffSources(current, proposed map[string]any) *DocumentDiff {
+	diff := &DocumentDiff{
+		Added:   []FieldChange{},
+		Removed: []FieldChange{},
+		Changed: []FieldChange{},
+	}
+	diff.compareObjects("", current, proposed)
+	return diff
+}
+
+// compareObjects はオブジェクトのキーごとに比較する（出力を安定させるためキー順に処理する）
+func (d *DocumentDiff) compareObjects(path string, current, proposed map[string]any) {
+	keys := make([]string, 0, len(current)+len(proposed))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range proposed {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+
+		oldValue, inCurrent := current[key]
+		newValue, inProposed := proposed[key]
+		switch {
+		case !inProposed:
+			d.Removed = append(d.Removed, FieldChange{Path: fieldPath, Old: oldValue})
+		case !inCurrent:
+			d.Added = append(d.Added, FieldChange{Path: fieldPath, New: newValue})
+		default:
+			d.compareValues(fieldPath, oldValue, newValue)
+		}
+	}
+}
+
+// compareArrays は配列を要素の位置ごとに比較する
+func (d *DocumentDiff) compareArrays(path string, current, proposed []any) {
+	for i := range max(len(current), len(proposed)) {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(proposed):
+			d.Removed = append(d.Removed, FieldChange{Path: elementPath, Old: current[i]})
+		case i >= len(current):
+			d.Added = append(d.Added, FieldChange{Path: elementPath, New: proposed[i]})
+		default:
+			d.compareValues(elementPath, current[i], proposed[i])
+		}
+	}
+}
+
+// compareValues は値を比較し、オブジェクトと配列は中身を再帰的に比較する
+func (d *DocumentDiff) compareValues(path string, current, proposed any) {
+	currentObject, currentIsObject := current.(map[string]any)
+	proposedObject, proposedIsObject := proposed.(map[string]any)
+	if currentIsObject && proposedIsObject {
+		d.compareObjects(path, currentObject, proposedObject)
+		return
+	}
+
+	currentArray, currentIsArray := current.([]any)
+	proposedArray, proposedIsArray := proposed.([]any)
+	if currentIsArray && proposedIsArray {
+		d.compareArrays(path, currentArray, proposedArray)
+		return
+	}
+
+	if !scalarEqual(current, proposed) {
+		d.Changed = append(d.Changed, FieldChange{Path: path, Old: current, New: proposed})
+	}
+}
+
+// scalarEqual はスカラー値を比較する。数値は表現に関わらず値で比較する
+func scalarEqual(a, b any) bool {
+	aNumber, aIsNumber := a.(json.Number)
+	bNumber, bIsNumber := b.(json.Number)
+	if aIsNumber && bIsNumber {
+		if aInt, err := aNumber.Int64(); err == nil {
+			if bInt, err := bNumber.Int64(); err == nil {
+				return aInt == bInt
+			}
+		}
+	}
+
+	aFloat, aIsFloat := numberValue(a)
+	bFloat, bIsFloat := numberValue(b)
+	if aIsFloat || bIsFloat {
+		return aIsFloat && bIsFloat && aFloat == bFloat
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// numberValue は json.Number または float64 の数値を float64 として返す
+func numberValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
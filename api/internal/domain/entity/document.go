@@ -6,12 +6,48 @@ import (
 
 // Document は Elasticsearch のドキュメントを表す
 type Document struct {
-	ID       string         `json:"id"`
-	Index    string         `json:"index"`
-	Source   map[string]any `json:"source"`
-	Version  int64          `json:"version"`
-	Created  time.Time      `json:"created"`
-	Modified time.Time      `json:"modified"`
+	ID          string         `json:"id"`
+	Index       string         `json:"index"`
+	Source      map[string]any `json:"source"` // 取得したドキュメントでは常に非nil（_source がない場合は空）
+	Version     int64          `json:"version"`
+	SeqNo       int64          `json:"seq_no"`
+	PrimaryTerm int64          `json:"primary_term"`
+	Routing     string         `json:"routing,omitempty"` // カスタムルーティング値（未設定時はIDでルーティング）
+	Pipeline    string         `json:"-"`                 // インデックス時に適用するインジェストパイプライン
+	// Upsert は部分更新でドキュメントが存在しない場合に作成するソース（nil の場合は Source をそのまま作成する）
+	Upsert map[string]any `json:"-"`
+	// RawSource は未加工のJSONで作成するドキュメントのソース（設定されている場合は Source の代わりにそのまま送信する）
+	RawSource *RawSource `json:"-"`
+	// ExternalVersion はクライアントが管理する外部バージョン（0の場合はElasticsearchの内部バージョン管理）
+	// 保存済みのバージョン以下の値での書き込みは競合として拒否される
+	ExternalVersion int64 `json:"-"`
+	// RetryOnConflict は部分更新がバージョン競合した際にElasticsearch側で再試行する回数
+	RetryOnConflict int `json:"-"`
+	// Refresh は書き込み後に検索へ反映させるリフレッシュの方法（空の場合はElasticsearchのデフォルト）
+	Refresh  RefreshPolicy `json:"-"`
+	Created  time.Time     `json:"created"`
+	Modified time.Time     `json:"modified"`
+}
+
+// RefreshPolicy は書き込み後のリフレッシュの方法を表す
+type RefreshPolicy string
+
+const (
+	// RefreshTrue は書き込み後すぐにリフレッシュして検索に反映する
+	RefreshTrue RefreshPolicy = "true"
+	// RefreshFalse はリフレッシュしない（定期リフレッシュまで検索に反映されない）
+	RefreshFalse RefreshPolicy = "false"
+	// RefreshWaitFor は次の定期リフレッシュで検索に反映されるまで応答を待つ
+	RefreshWaitFor RefreshPolicy = "wait_for"
+)
+
+// IsValid はリフレッシュの方法がサポートされているかどうかを返す（空は設定のデフォルト）
+func (p RefreshPolicy) IsValid() bool {
+	switch p {
+	case "", RefreshTrue, RefreshFalse, RefreshWaitFor:
+		return true
+	}
+	return false
 }
 
 // NewDocument は新しい Document インスタンスを作成する
@@ -26,6 +62,19 @@ func NewDocument(index string, source map[string]any) *Document {
 	}
 }
 
+// NewRawDocument は未加工のJSONをソースとする新しい Document インスタンスを作成する
+// Source は nil のままで、フィールドの読み書きは RawSource に対して行う
+func NewRawDocument(index string, source *RawSource) *Document {
+	now := time.Now()
+	return &Document{
+		Index:     index,
+		RawSource: source,
+		Version:   1,
+		Created:   now,
+		Modified:  now,
+	}
+}
+
 // SetID はドキュメント ID を設定する
 func (d *Document) SetID(id string) {
 	d.ID = id
@@ -40,12 +89,19 @@ func (d *Document) UpdateSource(source map[string]any) {
 
 // GetField はドキュメントソースから特定のフィールドを取得する
 func (d *Document) GetField(field string) (any, bool) {
+	if d.RawSource != nil {
+		return d.RawSource.GetField(field)
+	}
 	value, exists := d.Source[field]
 	return value, exists
 }
 
 // SetField はドキュメントソースに特定のフィールドを設定する
 func (d *Document) SetField(field string, value any) {
-	d.Source[field] = value
+	if d.RawSource != nil {
+		d.RawSource.SetField(field, value)
+	} else {
+		d.Source[field] = value
+	}
 	d.Modified = time.Now()
 }
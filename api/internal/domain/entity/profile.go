@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+)
+
+// SearchProfile は検索のプロファイル結果（Elasticsearch の profile）を要約したものを表す
+type SearchProfile struct {
+	Shards []ShardProfile `json:"shards"`
+	Fields []FieldTiming  `json:"fields"` // フィールドごとのクエリ実行時間（時間の長い順）
+}
+
+// ShardProfile はシャードごとの処理時間の内訳を表す（時間はナノ秒）
+type ShardProfile struct {
+	ID             string         `json:"id"` // "[ノードID][インデックス][シャード番号]"
+	Index          string         `json:"index,omitempty"`
+	Shard          int64          `json:"shard"`
+	QueryNanos     int64          `json:"query_nanos"`
+	RewriteNanos   int64          `json:"rewrite_nanos"`
+	CollectNanos   int64          `json:"collect_nanos"`
+	FetchNanos     int64          `json:"fetch_nanos"`
+	AggregateNanos int64          `json:"aggregate_nanos"`
+	Queries        []QueryProfile `json:"queries,omitempty"` // Lucene クエリのツリー
+}
+
+// QueryProfile は Lucene クエリ1つの実行時間を表す
+type QueryProfile struct {
+	Type        string         `json:"type"`        // "BooleanQuery" や "TermQuery" など
+	Description string         `json:"description"` // "title:golang" など
+	Nanos       int64          `json:"nanos"`
+	Children    []QueryProfile `json:"children,omitempty"`
+}
+
+// FieldTiming はフィールドごとのクエリ実行時間の合計を表す
+type FieldTiming struct {
+	Field string `json:"field"`
+	Nanos int64  `json:"nanos"`
+}
+
+// SummarizeFields は全シャードの末端のクエリの実行時間をフィールドごとに合計し、Fields に設定する
+// フィールドはクエリの説明（"title:golang" や "price:[10 TO 20]"）から判定し、判定できないものは含めない
+func (p *SearchProfile) SummarizeFields() {
+	totals := map[string]int64{}
+	var walk func(queries []QueryProfile)
+	walk = func(queries []QueryProfile) {
+		for _, query := range queries {
+			if len(query.Children) > 0 {
+				walk(query.Children)
+				continue
+			}
+			if field := queryField(query.Description); field != "" {
+				totals[field] += query.Nanos
+			}
+		}
+	}
+	for _, shard := range p.Shards {
+		walk(shard.Queries)
+	}
+
+	p.Fields = make([]FieldTiming, 0, len(totals))
+	for field, nanos := range totals {
+		p.Fields = append(p.Fields, FieldTiming{Field: field, Nanos: nanos})
+	}
+	sort.Slice(p.Fields, func(i, j int) bool {
+		if p.Fields[i].Nanos != p.Fields[j].Nanos {
+			return p.Fields[i].Nanos > p.Fields[j].Nanos
+		}
+		return p.Fields[i].Field < p.Fields[j].Field
+	})
+}
+
+// queryField はクエリの説明から対象フィールドを返す（"title:golang" → "title"）
+func queryField(description string) string {
+	description = strings.TrimLeft(description, "+-#")
+	field, _, ok := strings.Cut(description, ":")
+	if !ok || field == "" || strings.ContainsAny(field, " ()") {
+		return ""
+	}
+	return field
+}
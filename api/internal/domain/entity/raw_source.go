@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrSourceNotObject はドキュメントソースがJSONオブジェクトでない場合のエラー
+var ErrSourceNotObject = errors.New("document source must be a JSON object")
+
+// RawSource はJSONオブジェクトのドキュメントソースを、トップレベルのフィールドごとに元のバイト列のまま保持する
+// 値をデコードしないため、数値の精度やフィールドの順序は送信時もリクエストのまま変わらない
+// フィールドの追加・置き換えはトップレベルでのみ行い、変更したフィールドだけをエンコードし直す
+type RawSource struct {
+	fields []rawField
+	err    error // SetField で値をエンコードできなかった最初のエラー
+}
+
+// rawField はトップレベルのフィールド名と未加工の値を表す
+type rawField struct {
+	name  string
+	value json.RawMessage
+}
+
+// ParseRawSource はJSONオブジェクトを RawSource に分解する
+// オブジェクト以外の値や、オブジェクトの後に続くデータがある場合はエラーを返す
+func ParseRawSource(data []byte) (*RawSource, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, ErrSourceNotObject
+	}
+
+	source := &RawSource{}
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := tok.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		source.fields = append(source.fields, rawField{name: name, value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, ErrSourceNotObject
+	}
+
+	return source, nil
+}
+
+// Len はトップレベルのフィールド数を返す
+func (s *RawSource) Len() int {
+	return len(s.fields)
+}
+
+// GetField はトップレベルのフィールドの値をデコードして返す
+func (s *RawSource) GetField(field string) (any, bool) {
+	for _, f := range s.fields {
+		if f.name == field {
+			var value any
+			if err := json.Unmarshal(f.value, &value); err != nil {
+				return nil, false
+			}
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// SetField はトップレベルのフィールドを設定する（既存のフィールドは同じ位置で置き換え、なければ末尾に追加する）
+// 値をエンコードできない場合は設定せず、エラーを Err で返す
+func (s *RawSource) SetField(field string, value any) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return
+	}
+	for i := range s.fields {
+		if s.fields[i].name == field {
+			s.fields[i].value = encoded
+			return
+		}
+	}
+	s.fields = append(s.fields, rawField{name: field, value: encoded})
+}
+
+// Err は SetField で発生した最初のエラーを返す
+func (s *RawSource) Err() error {
+	return s.err
+}
+
+// Bytes はフィールドを元の順序でJSONオブジェクトに組み立てる
+func (s *RawSource) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range s.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// Map はソースを map にデコードする（数値は精度を保つため json.Number になる）
+func (s *RawSource) Map() (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(s.Bytes()))
+	decoder.UseNumber()
+
+	var source map[string]any
+	if err := decoder.Decode(&source); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseRawSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantLen int
+		wantErr bool
+		wantIs  error
+	}{
+		{name: "object", data: `{"title":"Go","views":1}`, wantLen: 2},
+		{name: "empty object", data: ` {} `, wantLen: 0},
+		{name: "nested values", data: `{"tags":["a","b"],"author":{"name":"x"}}`, wantLen: 2},
+		{name: "array", data: `[{"title":"Go"}]`, wantErr: true, wantIs: ErrSourceNotObject},
+		{name: "string", data: `"title"`, wantErr: true, wantIs: ErrSourceNotObject},
+		{name: "trailing object", data: `{"a":1}{"b":2}`, wantErr: true, wantIs: ErrSourceNotObject},
+		{name: "invalid json", data: `{"a":}`, wantErr: true},
+		{name: "unterminated object", data: `{"a":1`, wantErr: true},
+		{name: "empty", data: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := ParseRawSource([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRawSource(%q) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("ParseRawSource(%q) error = %v, want %v", tt.data, err, tt.wantIs)
+			}
+			if err == nil && source.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", source.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestRawSourceBytesPreservesSource(t *testing.T) {
+	// float64 に変換すると精度が落ちる整数とフィールドの順序がそのまま残ること
+	data := `{"zeta":12345678901234567890,"alpha":{"b":1,"a":2},"price":1.10}`
+	source, err := ParseRawSource([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseRawSource() error = %v", err)
+	}
+
+	if got := string(source.Bytes()); got != data {
+		t.Errorf("Bytes() = %s, want %s", got, data)
+	}
+}
+
+func TestRawSourceSetField(t *testing.T) {
+	source, err := ParseRawSource([]byte(`{"id":12345678901234567890,"updated_at":"old","title":"Go"}`))
+	if err != nil {
+		t.Fatalf("ParseRawSource() error = %v", err)
+	}
+
+	source.SetField("updated_at", "new")
+	source.SetField("created_at", "now")
+	if err := source.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	want := `{"id":12345678901234567890,"updated_at":"new","title":"Go","created_at":"now"}`
+	if got := string(source.Bytes()); got != want {
+		t.Errorf("Bytes() = %s, want %s", got, want)
+	}
+	if got, ok := source.GetField("updated_at"); !ok || got != "new" {
+		t.Errorf("GetField(updated_at) = %v, %v, want new, true", got, ok)
+	}
+	if _, ok := source.GetField("missing"); ok {
+		t.Error("GetField(missing) found a field")
+	}
+}
+
+func TestRawSourceSetFieldError(t *testing.T) {
+	source, err := ParseRawSource([]byte(`{"title":"Go"}`))
+	if err != nil {
+		t.Fatalf("ParseRawSource() error = %v", err)
+	}
+
+	source.SetField("bad", make(chan int))
+	source.SetField("ok", 1)
+	if source.Err() == nil {
+		t.Error("Err() = nil, want the encoding error")
+	}
+	if _, ok := source.GetField("bad"); ok {
+		t.Error("field that failed to encode was set")
+	}
+	if _, ok := source.GetField("ok"); !ok {
+		t.Error("field set after the error is missing")
+	}
+}
+
+func TestRawSourceMap(t *testing.T) {
+	source, err := ParseRawSource([]byte(`{"id":12345678901234567890,"title":"Go"}`))
+	if err != nil {
+		t.Fatalf("ParseRawSource() error = %v", err)
+	}
+
+	m, err := source.Map()
+	if err != nil {
+		t.Fatalf("Map() error = %v", err)
+	}
+	if got, ok := m["id"].(json.Number); !ok || got.String() != "12345678901234567890" {
+		t.Errorf("Map()[id] = %#v, want json.Number 12345678901234567890", m["id"])
+	}
+	if m["title"] != "Go" {
+		t.Errorf("Map()[title] = %v, want Go", m["title"])
+	}
+}
+
+func TestRawDocumentFields(t *testing.T) {
+	source, err := ParseRawSource([]byte(`{"title":"Go"}`))
+	if err != nil {
+		t.Fatalf("ParseRawSource() error = %v", err)
+	}
+
+	doc := NewRawDocument("articles", source)
+	doc.SetField("author", "alice")
+	if doc.Source != nil {
+		t.Errorf("Source = %v, want nil for a raw document", doc.Source)
+	}
+	if got, ok := doc.GetField("author"); !ok || got != "alice" {
+		t.Errorf("GetField(author) = %v, %v, want alice, true", got, ok)
+	}
+	if got := string(source.Bytes()); got != `{"title":"Go","author":"alice"}` {
+		t.Errorf("Bytes() = %s", got)
+	}
+}
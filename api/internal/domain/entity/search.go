@@ -1,13 +1,219 @@
 package entity
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SearchMode は検索クエリ文字列の解釈方法を表す
+type SearchMode string
+
+const (
+	// SearchModeMatch はクエリ文字列を解析対象のテキストとして扱う（multi_match）
+	SearchModeMatch SearchMode = "match"
+	// SearchModeQueryString はクエリ文字列をLucene構文として解釈する（query_string）
+	SearchModeQueryString SearchMode = "query_string"
+	// SearchModeSimpleQueryString は構文エラーを許容する簡易構文として解釈する（simple_query_string）
+	SearchModeSimpleQueryString SearchMode = "simple_query_string"
+	// SearchModeSearchAsYouType は search_as_you_type フィールドとその _2gram/_3gram サブフィールドを
+	// bool_prefix の multi_match で検索する（入力途中の語を前方一致させるサジェスト専用のモード）
+	SearchModeSearchAsYouType SearchMode = "search_as_you_type"
+)
+
+// IsValid は検索モードがサポートされているかどうかを返す（空はデフォルトのmatch）
+func (m SearchMode) IsValid() bool {
+	switch m {
+	case "", SearchModeMatch, SearchModeQueryString, SearchModeSimpleQueryString:
+		return true
+	}
+	return false
+}
+
+// SuggestMode はサジェスト検索の方式を表す
+type SuggestMode string
+
+const (
+	// SuggestModePrefix はクエリ文字列の末尾をワイルドカードとした前方一致で検索する
+	SuggestModePrefix SuggestMode = "prefix"
+	// SuggestModeSearchAsYouType は search_as_you_type としてマッピングされたフィールドを検索する
+	SuggestModeSearchAsYouType SuggestMode = "search_as_you_type"
+)
+
+// IsValid はサジェスト方式がサポートされているかどうかを返す（空はデフォルトのprefix）
+func (m SuggestMode) IsValid() bool {
+	switch m {
+	case "", SuggestModePrefix, SuggestModeSearchAsYouType:
+		return true
+	}
+	return false
+}
+
+// IsValidOperator はデフォルト演算子として指定可能な値かどうかを返す（空はデフォルト）
+func IsValidOperator(operator string) bool {
+	switch strings.ToLower(operator) {
+	case "", "and", "or":
+		return true
+	}
+	return false
+}
+
 // SearchQuery は検索クエリ構造を表す
 type SearchQuery struct {
-	Query   string            `json:"query"`
-	Index   string            `json:"index,omitempty"`
-	Filters map[string]string `json:"filters,omitempty"`
-	From    int               `json:"from"`
-	Size    int               `json:"size"`
-	Sort    []SortField       `json:"sort,omitempty"`
+	Query            string             `json:"query"`
+	Mode             SearchMode         `json:"mode,omitempty"`
+	Fields           []string           `json:"fields,omitempty"`
+	DefaultOperator  string             `json:"default_operator,omitempty"`
+	Analyzer         string             `json:"analyzer,omitempty"` // 検索語の解析に使用するアナライザー（空の場合はフィールドのマッピングに従う）
+	Index            string             `json:"index,omitempty"`    // カンマ区切りの複数指定やワイルドカード（例: "logs-*"）も可
+	Filters          map[string]string  `json:"filters,omitempty"`
+	From             int                `json:"from"`
+	Size             int                `json:"size"`
+	Sort             []SortField        `json:"sort,omitempty"`
+	MinScore         float64            `json:"min_score,omitempty"` // 0は無効。閾値未満のヒットは Total にも含まれない
+	Collapse         *CollapseOption    `json:"collapse,omitempty"`
+	SearchAfter      []any              `json:"search_after,omitempty"` // 前ページ最後のヒットのソート値（From と併用不可）
+	UniqueSort       bool               `json:"-"`                      // ソートの最後が一意な値のフィールドで、search_after でのページングに欠落や重複が起きない
+	Nested           *NestedQuery       `json:"nested,omitempty"`
+	FunctionScore    *FunctionScore     `json:"function_score,omitempty"`
+	FieldBoosts      map[string]float64 `json:"field_boosts,omitempty"`   // 対象フィールドに付与するブースト（"title" → "title^3"）
+	ExcludeSource    bool               `json:"exclude_source,omitempty"` // true の場合はヒットのソースを取得しない（Source は nil）
+	Profile          bool               `json:"profile,omitempty"`        // true の場合は処理時間の内訳を取得する（負荷が高いため信頼済みの呼び出し元のみ）
+	Highlight        *Highlight         `json:"highlight,omitempty"`
+	StoredFields     []string           `json:"stored_fields,omitempty"`       // _source 以外に保存されたフィールド（store: true）のうち取得するもの
+	DocValueFields   []string           `json:"docvalue_fields,omitempty"`     // doc values から取得するフィールド
+	Explain          bool               `json:"explain,omitempty"`             // true の場合はヒットごとにスコアの計算過程を取得する
+	SeqNoPrimaryTerm bool               `json:"seq_no_primary_term,omitempty"` // true の場合はヒットごとに _seq_no と _primary_term を取得する（条件付き更新用）
+	Debug            bool               `json:"debug,omitempty"`               // true の場合はサービスが適用したビジネスルールを AppliedRules に記録する
+	AppliedRules     []AppliedRule      `json:"applied_rules,omitempty"`
+}
+
+// AppliedRule は検索時にサービスがリクエストに加えた変更（ビジネスルールの適用）を表す
+type AppliedRule struct {
+	Rule        string `json:"rule"`        // "size_clamped" などの識別子
+	Description string `json:"description"` // 変更前後の値を含む説明
+}
+
+// FunctionScore はフィールド値や減衰関数でスコアを調整する設定を表す
+type FunctionScore struct {
+	FieldValueFactor *FieldValueFactor `json:"field_value_factor,omitempty"`
+	Decay            *DecayFunction    `json:"decay,omitempty"`
+	BoostMode        string            `json:"boost_mode,omitempty"` // 元のスコアとの結合方法（空はmultiply）
+}
+
+// FieldValueFactor は数値フィールドの値をスコアに反映する設定を表す
+type FieldValueFactor struct {
+	Field    string   `json:"field"`
+	Factor   float64  `json:"factor,omitempty"`   // 0の場合は1
+	Modifier string   `json:"modifier,omitempty"` // 空はnone
+	Missing  *float64 `json:"missing,omitempty"`  // フィールドがないドキュメントに使う値
+}
+
+// DecayFunction は原点からの距離に応じてスコアを減衰させる設定を表す
+type DecayFunction struct {
+	Function DecayFunctionType `json:"function"`
+	Field    string            `json:"field"`
+	Origin   string            `json:"origin,omitempty"` // 日付フィールドでは省略時に現在時刻
+	Scale    string            `json:"scale"`
+	Offset   string            `json:"offset,omitempty"`
+	Decay    float64           `json:"decay,omitempty"` // 0の場合は0.5
+}
+
+// DecayFunctionType は減衰関数の種類を表す
+type DecayFunctionType string
+
+const (
+	DecayFunctionGauss  DecayFunctionType = "gauss"
+	DecayFunctionLinear DecayFunctionType = "linear"
+	DecayFunctionExp    DecayFunctionType = "exp"
+)
+
+// IsValid は減衰関数がサポートされているかどうかを返す
+func (f DecayFunctionType) IsValid() bool {
+	switch f {
+	case DecayFunctionGauss, DecayFunctionLinear, DecayFunctionExp:
+		return true
+	}
+	return false
+}
+
+// IsValidExpandWildcards は expand_wildcards として指定可能な値かどうかを返す
+func IsValidExpandWildcards(value string) bool {
+	switch value {
+	case "all", "open", "closed", "hidden", "none":
+		return true
+	}
+	return false
+}
+
+// IsValidFieldValueModifier は field_value_factor の modifier として指定可能な値かどうかを返す（空はnone）
+func IsValidFieldValueModifier(modifier string) bool {
+	switch modifier {
+	case "", "none", "log", "log1p", "log2p", "ln", "ln1p", "ln2p", "square", "sqrt", "reciprocal":
+		return true
+	}
+	return false
+}
+
+// IsValidBoostMode は function_score の boost_mode として指定可能な値かどうかを返す（空はmultiply）
+func IsValidBoostMode(mode string) bool {
+	switch mode {
+	case "", "multiply", "replace", "sum", "avg", "max", "min":
+		return true
+	}
+	return false
+}
+
+// NestedQuery はネストされたオブジェクト配列の単一要素内で一致させるクエリを表す
+// Must と Filter のキーは path からの完全なフィールド名（例: "comments.author"）
+type NestedQuery struct {
+	Path          string            `json:"path"`
+	Must          map[string]string `json:"must,omitempty"`            // フィールド -> 全文一致させるテキスト
+	Filter        map[string]string `json:"filter,omitempty"`          // フィールド -> 完全一致させる値
+	InnerHitsSize int               `json:"inner_hits_size,omitempty"` // 0の場合は一致した要素を返さない
+}
+
+// CollapseOption はフィールドコラプス（グループごとに1件へ集約）の設定を表す
+type CollapseOption struct {
+	Field         string `json:"field"`
+	InnerHitsSize int    `json:"inner_hits_size,omitempty"` // 0の場合はグループ内のヒットを返さない
+}
+
+// Highlight はヒットのハイライト（一致箇所を含む断片）の設定を表す
+type Highlight struct {
+	Fields []HighlightField `json:"fields"`
+}
+
+// HighlightField はフィールドごとのハイライトの設定を表す（未指定の値はElasticsearchのデフォルトを使用する）
+type HighlightField struct {
+	Field             string `json:"field"`
+	FragmentSize      *int   `json:"fragment_size,omitempty"`       // 断片の文字数（デフォルト100）
+	NumberOfFragments *int   `json:"number_of_fragments,omitempty"` // 返す断片の最大数（デフォルト5、0の場合はフィールド全体を1つの断片として返す）
+	Order             string `json:"order,omitempty"`               // "score"（スコア順）または "none"（出現順、デフォルト）
+}
+
+// IsValidHighlightOrder はハイライトの断片の並び順として指定可能な値かどうかを返す（空は none）
+func IsValidHighlightOrder(order string) bool {
+	switch order {
+	case "", "score", "none":
+		return true
+	}
+	return false
+}
+
+// MoreLikeThisQuery は任意のテキストに類似したドキュメントを検索するクエリを表す
+// 閾値が0の場合はElasticsearchのデフォルト値を使用する
+type MoreLikeThisQuery struct {
+	Text          string   `json:"text"`
+	Index         string   `json:"index,omitempty"`
+	Fields        []string `json:"fields,omitempty"`
+	MinTermFreq   int      `json:"min_term_freq,omitempty"`
+	MaxQueryTerms int      `json:"max_query_terms,omitempty"`
+	MinDocFreq    int      `json:"min_doc_freq,omitempty"`
+	Size          int      `json:"size"`
 }
 
 // SortField はソートフィールドを表す
@@ -16,22 +222,72 @@ type SortField struct {
 	Order string `json:"order"` // "asc" または "desc"
 }
 
+// NormalizeSortOrder はソート順序の前後の空白を除去して小文字に正規化する
+func NormalizeSortOrder(order string) string {
+	return strings.ToLower(strings.TrimSpace(order))
+}
+
+// IsValidSortOrder は正規化後のソート順序が "asc" または "desc" かどうかを返す
+func IsValidSortOrder(order string) bool {
+	switch NormalizeSortOrder(order) {
+	case "asc", "desc":
+		return true
+	}
+	return false
+}
+
+// QueryValidation は Elasticsearch の _validate/query による検証結果を表す
+type QueryValidation struct {
+	Valid        bool               `json:"valid"`
+	Query        map[string]any     `json:"query"`                  // 検証したクエリ句
+	Explanations []QueryExplanation `json:"explanations,omitempty"` // インデックスごとの説明
+	Error        string             `json:"error,omitempty"`        // インデックス単位でない検証エラー
+}
+
+// QueryExplanation はインデックスごとのクエリ検証結果を表す
+type QueryExplanation struct {
+	Index       string `json:"index"`
+	Valid       bool   `json:"valid"`
+	Explanation string `json:"explanation,omitempty"` // 書き換え後のLuceneクエリ
+	Error       string `json:"error,omitempty"`
+}
+
 // SearchResult は検索操作の結果を表す
 type SearchResult struct {
-	Query    SearchQuery `json:"query"`
-	Hits     []Hit       `json:"hits"`
-	Total    int64       `json:"total"`
-	MaxScore float64     `json:"max_score"`
-	Took     int64       `json:"took"`
-	TimedOut bool        `json:"timed_out"`
+	Query         SearchQuery    `json:"query"`
+	Hits          []Hit          `json:"hits"`
+	Total         int64          `json:"total"`
+	TotalRelation string         `json:"total_relation,omitempty"` // "eq"（正確な値）または "gte"（下限値）
+	MaxScore      float64        `json:"max_score"`
+	Took          int64          `json:"took"`
+	TimedOut      bool           `json:"timed_out"`
+	Error         string         `json:"error,omitempty"`
+	Profile       *SearchProfile `json:"profile,omitempty"` // Query.Profile が true の場合のみ
 }
 
 // Hit は単一の検索結果を表す
 type Hit struct {
-	Index  string         `json:"_index"`
-	ID     string         `json:"_id"`
-	Score  float64        `json:"_score"`
-	Source map[string]any `json:"_source"`
+	Index        string              `json:"_index"`
+	ID           string              `json:"_id"`
+	Score        float64             `json:"_score"`
+	Routing      string              `json:"_routing,omitempty"`
+	Source       map[string]any      `json:"_source"`                 // ソースがない場合（source=false、マッピングで無効化、不正な形式）は nil
+	MatchQuality string              `json:"match_quality,omitempty"` // スコアから算出した一致度（"high"、"medium"、"low"）
+	Collapsed    []Hit               `json:"collapsed,omitempty"`     // フィールドコラプス時に同じグループに属するヒット
+	Sort         []any               `json:"sort,omitempty"`          // ソート値（search_after のカーソルとして使用する）
+	Nested       []Hit               `json:"nested,omitempty"`        // ネストクエリに一致した要素（_source はネストされたオブジェクト）
+	Highlight    map[string][]string `json:"highlight,omitempty"`     // フィールドごとのハイライトされた断片
+	Fields       map[string][]any    `json:"fields,omitempty"`        // stored_fields と docvalue_fields で取得したフィールドの値
+	Explanation  *Explanation        `json:"_explanation,omitempty"`  // スコアの計算過程（explain 指定時のみ）
+	SeqNo        *int64              `json:"_seq_no,omitempty"`       // シーケンス番号（seq_no_primary_term 指定時のみ）
+	PrimaryTerm  *int64              `json:"_primary_term,omitempty"` // プライマリターム（seq_no_primary_term 指定時のみ）
+}
+
+// Explanation はヒットのスコアの計算過程（Elasticsearch の _explanation）を表す
+type Explanation struct {
+	Value       float64       `json:"value"`
+	Description string        `json:"description"`
+	Details     []Explanation `json:"details,omitempty"`
 }
 
 // NewSearchQuery は新しい SearchQuery インスタンスを作成する
@@ -50,6 +306,74 @@ func (sq *SearchQuery) SetIndex(index string) {
 	sq.Index = index
 }
 
+// Indices はカンマ区切りのインデックス指定を個々のインデックス名またはパターンに分割する
+// 前後の空白は除去し、空の要素は含めない
+func (sq *SearchQuery) Indices() []string {
+	var indices []string
+	for _, index := range strings.Split(sq.Index, ",") {
+		if index = strings.TrimSpace(index); index != "" {
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+// CanonicalKey は検索結果を左右する内容が同じクエリで同じ値になるキー（SHA-256 の16進数）を返す
+// クエリ文字列の前後の空白、インデックス・検索対象フィールド・取得フィールドの指定順、
+// フィルターの順序、ソート順序やデフォルト演算子の大文字小文字の違いは無視する
+// ソートフィールドの並びは優先順位を表すため、順序の違いは別のクエリとして扱う
+func (sq *SearchQuery) CanonicalKey() string {
+	canonical := *sq
+	canonical.Query = strings.TrimSpace(sq.Query)
+	canonical.Index = strings.Join(sortedCopy(sq.Indices()), ",")
+	canonical.Fields = sortedCopy(sq.Fields)
+	canonical.DefaultOperator = strings.ToLower(strings.TrimSpace(sq.DefaultOperator))
+	canonical.StoredFields = sortedCopy(sq.StoredFields)
+	canonical.DocValueFields = sortedCopy(sq.DocValueFields)
+	canonical.AppliedRules = nil
+
+	canonical.Sort = make([]SortField, len(sq.Sort))
+	for i, field := range sq.Sort {
+		canonical.Sort[i] = SortField{Field: strings.TrimSpace(field.Field), Order: NormalizeSortOrder(field.Order)}
+	}
+
+	// encoding/json はマップのキーをソートして出力するため、フィルターの順序に依存しない
+	payload, err := json.Marshal(canonical)
+	if err != nil {
+		payload = []byte(fmt.Sprintf("%#v", canonical))
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// sortedCopy は空白を除いた要素をソートしたコピーを返す（元のスライスは変更しない）
+func sortedCopy(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := make([]string, len(values))
+	for i, value := range values {
+		sorted[i] = strings.TrimSpace(value)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// RecordRule は Debug が有効な場合に、適用したビジネスルールを記録する
+func (sq *SearchQuery) RecordRule(rule, format string, args ...any) {
+	if !sq.Debug {
+		return
+	}
+	sq.AppliedRules = append(sq.AppliedRules, AppliedRule{Rule: rule, Description: fmt.Sprintf(format, args...)})
+}
+
+// IsDateMathIndex はインデックス名が <logs-{now/d}> のような日付計算式かどうかを返す
+// 日付計算式は Elasticsearch がリクエスト時点の日付で実際のインデックス名に解決する
+func IsDateMathIndex(index string) bool {
+	index = strings.TrimPrefix(strings.TrimSpace(index), "-")
+	return len(index) > 2 && strings.HasPrefix(index, "<") && strings.HasSuffix(index, ">")
+}
+
 // AddFilter は検索クエリにフィルターを追加する
 func (sq *SearchQuery) AddFilter(field, value string) {
 	sq.Filters[field] = value
@@ -84,6 +408,11 @@ func (sr *SearchResult) AddHit(hit Hit) {
 	sr.Hits = append(sr.Hits, hit)
 }
 
+// HasError はサブクエリが失敗したかどうかを返す
+func (sr *SearchResult) HasError() bool {
+	return sr.Error != ""
+}
+
 // HasResults は検索結果があるかどうかを返す
 func (sr *SearchResult) HasResults() bool {
 	return len(sr.Hits) > 0
@@ -0,0 +1,83 @@
+package entity
+
+import "testing"
+
+func TestSearchQueryCanonicalKey(t *testing.T) {
+	base := func() *SearchQuery {
+		return &SearchQuery{
+			Query:           "golang",
+			Index:           "articles,blogs",
+			Fields:          []string{"title", "body"},
+			DefaultOperator: "and",
+			Filters:         map[string]string{"category": "tech", "status": "published"},
+			Size:            10,
+			Sort:            []SortField{{Field: "date", Order: "desc"}, {Field: "_score", Order: "desc"}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(q *SearchQuery)
+		same   bool
+	}{
+		{name: "identical", modify: func(q *SearchQuery) {}, same: true},
+		{name: "index order", modify: func(q *SearchQuery) { q.Index = " blogs, articles" }, same: true},
+		{name: "field order", modify: func(q *SearchQuery) { q.Fields = []string{"body", "title"} }, same: true},
+		{name: "filter insertion order", modify: func(q *SearchQuery) {
+			q.Filters = map[string]string{"status": "published"}
+			q.Filters["category"] = "tech"
+		}, same: true},
+		{name: "query whitespace", modify: func(q *SearchQuery) { q.Query = "  golang " }, same: true},
+		{name: "operator case", modify: func(q *SearchQuery) { q.DefaultOperator = "AND" }, same: true},
+		{name: "sort order case", modify: func(q *SearchQuery) { q.Sort[0].Order = " DESC" }, same: true},
+		{name: "applied rules", modify: func(q *SearchQuery) { q.AppliedRules = []AppliedRule{{Rule: "size_clamped"}} }, same: true},
+		{name: "different query", modify: func(q *SearchQuery) { q.Query = "rust" }, same: false},
+		{name: "different index", modify: func(q *SearchQuery) { q.Index = "articles" }, same: false},
+		{name: "different filter value", modify: func(q *SearchQuery) { q.Filters["status"] = "draft" }, same: false},
+		{name: "sort priority", modify: func(q *SearchQuery) { q.Sort[0], q.Sort[1] = q.Sort[1], q.Sort[0] }, same: false},
+		{name: "different page", modify: func(q *SearchQuery) { q.From = 10 }, same: false},
+	}
+
+	want := base().CanonicalKey()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := base()
+			tt.modify(query)
+			if got := query.CanonicalKey(); (got == want) != tt.same {
+				t.Errorf("CanonicalKey() equal = %v, want %v", got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestSearchQueryCanonicalKeyDoesNotModifyQuery(t *testing.T) {
+	query := &SearchQuery{Query: " golang ", Index: "b,a", Fields: []string{"title", "body"}}
+	query.CanonicalKey()
+
+	if query.Query != " golang " || query.Index != "b,a" || query.Fields[0] != "title" {
+		t.Errorf("CanonicalKey() modified the query: %+v", query)
+	}
+}
+
+func TestIsDateMathIndex(t *testing.T) {
+	tests := []struct {
+		index string
+		want  bool
+	}{
+		{index: "<logs-{now/d}>", want: true},
+		{index: " <logs-{now/d{yyyy.MM.dd|+12:00}}> ", want: true},
+		{index: "-<logs-{now/d-1d}>", want: true},
+		{index: "logs-2024.01.01", want: false},
+		{index: "logs-*", want: false},
+		{index: "<>", want: false},
+		{index: "<logs", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.index, func(t *testing.T) {
+			if got := IsDateMathIndex(tt.index); got != tt.want {
+				t.Errorf("IsDateMathIndex(%q) = %v, want %v", tt.index, got, tt.want)
+			}
+		})
+	}
+}
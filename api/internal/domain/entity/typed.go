@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoSource はヒットにソースがない（source=false で除外された、マッピングで無効化されているなど）ことを表す
+var ErrNoSource = errors.New("hit has no source")
+
+// UnmarshalSource はヒットのソースを JSON を介して型付きの値に変換する
+// ソースがない場合はゼロ値を黙って返さず、ErrNoSource をラップしたエラーを返す
+func UnmarshalSource[T any](hit Hit) (T, error) {
+	var value T
+	if hit.Source == nil {
+		return value, fmt.Errorf("failed to unmarshal source of %s/%s: %w", hit.Index, hit.ID, ErrNoSource)
+	}
+
+	data, err := json.Marshal(hit.Source)
+	if err != nil {
+		return value, fmt.Errorf("failed to marshal source of %s/%s: %w", hit.Index, hit.ID, err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to unmarshal source of %s/%s: %w", hit.Index, hit.ID, err)
+	}
+
+	return value, nil
+}
+
+// NewDocumentFromStruct は型付きの値を JSON を介してソースに変換し、新しい Document を作成する
+// 値は JSON オブジェクトに変換できる必要がある（構造体やマップなど）
+func NewDocumentFromStruct[T any](index string, value T) (*Document, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	var source map[string]any
+	if err := json.Unmarshal(data, &source); err != nil || source == nil {
+		return nil, fmt.Errorf("document must marshal to a JSON object, got %T", value)
+	}
+
+	return NewDocument(index, source), nil
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnmarshalSource(t *testing.T) {
+	type article struct {
+		Title string `json:"title"`
+	}
+
+	tests := []struct {
+		name      string
+		source    map[string]any
+		want      string
+		wantNoSrc bool
+	}{
+		{name: "object", source: map[string]any{"title": "Go"}, want: "Go"},
+		{name: "empty source", source: map[string]any{}},
+		{name: "no source", source: nil, wantNoSrc: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalSource[article](Hit{Index: "articles", ID: "1", Source: tt.source})
+			if tt.wantNoSrc {
+				if !errors.Is(err, ErrNoSource) {
+					t.Errorf("UnmarshalSource() error = %v, want ErrNoSource", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalSource() error = %v", err)
+			}
+			if got.Title != tt.want {
+				t.Errorf("Title = %q, want %q", got.Title, tt.want)
+			}
+		})
+	}
+}
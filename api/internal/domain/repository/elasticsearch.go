@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
 )
@@ -10,38 +12,181 @@ import (
 type ElasticsearchRepository interface {
 	// ドキュメント操作
 	CreateDocument(ctx context.Context, doc *entity.Document) error
-	GetDocument(ctx context.Context, index, id string) (*entity.Document, error)
+	GetDocument(ctx context.Context, index, id string, opts ...DocumentOption) (*entity.Document, error)
+	GetDocumentRaw(ctx context.Context, index, id string, opts ...DocumentOption) (io.ReadCloser, error)
 	UpdateDocument(ctx context.Context, doc *entity.Document) error
-	DeleteDocument(ctx context.Context, index, id string) error
+	UpdateDocumentIfMatch(ctx context.Context, doc *entity.Document, seqNo, primaryTerm int64) error
+	UpsertDocument(ctx context.Context, doc *entity.Document) error
+	ReplaceDocument(ctx context.Context, doc *entity.Document) (bool, error)
+	DeleteDocument(ctx context.Context, index, id string, opts ...DocumentOption) error
 
 	// 検索操作
-	Search(ctx context.Context, query *entity.SearchQuery) (*entity.SearchResult, error)
+	Search(ctx context.Context, query *entity.SearchQuery, opts ...SearchOption) (*entity.SearchResult, error)
 	MultiSearch(ctx context.Context, queries []*entity.SearchQuery) ([]*entity.SearchResult, error)
+	MoreLikeThis(ctx context.Context, query *entity.MoreLikeThisQuery) (*entity.SearchResult, error)
+	RenderSearchQuery(query *entity.SearchQuery) map[string]any
+	ValidateQuery(ctx context.Context, query *entity.SearchQuery) (*entity.QueryValidation, error)
+	TermsAggregation(ctx context.Context, index, field string, size int) (*entity.TermsResult, error)
 
 	// インデックス操作
-	CreateIndex(ctx context.Context, index string, mapping map[string]any) error
+	CreateIndex(ctx context.Context, index string, mapping, settings map[string]any) error
 	DeleteIndex(ctx context.Context, index string) error
 	IndexExists(ctx context.Context, index string) (bool, error)
+	IndexStats(ctx context.Context, index string) (map[string]any, error)
+	OpenIndex(ctx context.Context, index string) error
+	CloseIndex(ctx context.Context, index string) error
+	Refresh(ctx context.Context, index string) error
+	ForceMerge(ctx context.Context, index string, maxSegments int) (string, error)
+	GetFieldType(ctx context.Context, index, field string) (string, error)
+	FieldCapabilities(ctx context.Context, index, field string) (*entity.FieldCapability, error)
+	MaxResultWindow(ctx context.Context, index string) (int, error)
+	ScrollDocuments(ctx context.Context, index string, query *entity.SearchQuery, batchSize int, fn func(hits []entity.Hit) error) error
 
 	// バルク操作
-	BulkIndex(ctx context.Context, documents []*entity.Document) error
-	BulkDelete(ctx context.Context, indices []string, ids []string) error
+	BulkIndex(ctx context.Context, documents []*entity.Document, opType entity.BulkOpType, opts ...DocumentOption) (*entity.BulkResult, error)
+	BulkDelete(ctx context.Context, indices []string, ids []string, opts ...DocumentOption) error
 
 	// ヘルスチェックと情報取得
 	Health(ctx context.Context) error
 	Info(ctx context.Context) (map[string]any, error)
 }
 
+// DocumentOptions provides additional options for single-document operations
+type DocumentOptions struct {
+	Routing         string
+	Pipeline        string
+	ExternalVersion int64
+	RetryOnConflict *int
+	IfSeqNo         *int64
+	IfPrimaryTerm   *int64
+	Refresh         entity.RefreshPolicy
+}
+
+// DocumentOption configures DocumentOptions
+type DocumentOption func(*DocumentOptions)
+
+// WithRouting sets the shard routing value of the document
+func WithRouting(routing string) DocumentOption {
+	return func(o *DocumentOptions) {
+		o.Routing = routing
+	}
+}
+
+// WithPipeline sets the ingest pipeline used when indexing the document.
+// "_none" disables any default pipeline configured for the index.
+func WithPipeline(pipeline string) DocumentOption {
+	return func(o *DocumentOptions) {
+		o.Pipeline = pipeline
+	}
+}
+
+// WithExternalVersion indexes the document with version_type=external and the given
+// client-managed version. Writes with a version not greater than the stored one are rejected.
+func WithExternalVersion(version int64) DocumentOption {
+	return func(o *DocumentOptions) {
+		o.ExternalVersion = version
+	}
+}
+
+// WithRetryOnConflict sets how many times Elasticsearch retries a partial update
+// when the document is changed concurrently. Unset options fall back to the server default.
+func WithRetryOnConflict(retries int) DocumentOption {
+	return func(o *DocumentOptions) {
+		o.RetryOnConflict = &retries
+	}
+}
+
+// WithIfMatch makes a delete succeed only while the document still has the given
+// sequence number and primary term (optimistic concurrency control)
+func WithIfMatch(seqNo, primaryTerm int64) DocumentOption {
+	return func(o *DocumentOptions) {
+		o.IfSeqNo = &seqNo
+		o.IfPrimaryTerm = &primaryTerm
+	}
+}
+
+// WithRefresh sets whether the write waits for or forces an index refresh so that it becomes
+// visible to search. An empty policy leaves the decision to the configured default.
+func WithRefresh(policy entity.RefreshPolicy) DocumentOption {
+	return func(o *DocumentOptions) {
+		o.Refresh = policy
+	}
+}
+
+// NewDocumentOptions returns DocumentOptions with opts applied
+func NewDocumentOptions(opts ...DocumentOption) *DocumentOptions {
+	options := &DocumentOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
 // SearchOptions provides additional options for search operations
 type SearchOptions struct {
-	Timeout           string
-	Preference        string
-	Routing           string
+	Timeout           time.Duration // per-shard search timeout; partial results are returned when exceeded
+	Preference        string        // custom string or shard preference such as "_local", keeps repeated searches on the same shard copies
+	Routing           string        // limits the search to the shards holding documents with this routing value
 	ExpandWildcards   []string
-	AllowNoIndices    bool
+	AllowNoIndices    *bool // nil uses the Elasticsearch default (true)
 	IgnoreUnavailable bool
 }
 
+// SearchOption configures SearchOptions
+type SearchOption func(*SearchOptions)
+
+// WithTimeout sets the per-shard search timeout
+func WithTimeout(timeout time.Duration) SearchOption {
+	return func(o *SearchOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithPreference sets the shard copy preference of the search
+func WithPreference(preference string) SearchOption {
+	return func(o *SearchOptions) {
+		o.Preference = preference
+	}
+}
+
+// WithSearchRouting restricts the search to the shards for the routing value
+func WithSearchRouting(routing string) SearchOption {
+	return func(o *SearchOptions) {
+		o.Routing = routing
+	}
+}
+
+// WithExpandWildcards sets which index states wildcard patterns expand to
+// ("all", "open", "closed", "hidden" or "none")
+func WithExpandWildcards(states ...string) SearchOption {
+	return func(o *SearchOptions) {
+		o.ExpandWildcards = states
+	}
+}
+
+// WithAllowNoIndices sets whether a wildcard or _all matching no indices is an error
+func WithAllowNoIndices(allow bool) SearchOption {
+	return func(o *SearchOptions) {
+		o.AllowNoIndices = &allow
+	}
+}
+
+// WithIgnoreUnavailable sets whether missing or closed concrete indices are ignored
+func WithIgnoreUnavailable(ignore bool) SearchOption {
+	return func(o *SearchOptions) {
+		o.IgnoreUnavailable = ignore
+	}
+}
+
+// NewSearchOptions returns SearchOptions with opts applied
+func NewSearchOptions(opts ...SearchOption) *SearchOptions {
+	options := &SearchOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
 // BulkItem represents a single item in a bulk operation
 type BulkItem struct {
 	Index  string         `json:"_index"`
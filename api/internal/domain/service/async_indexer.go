@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// AsyncIndexerConfig は非同期インデクサーの設定を表す
+type AsyncIndexerConfig struct {
+	// QueueSize はキューに保持できるドキュメントの最大数（超えた登録は拒否する）
+	QueueSize int
+	// BatchSize は1回のバルクリクエストで送信する最大件数
+	BatchSize int
+	// FlushInterval はバッチが埋まらなくても送信するまでの最大待ち時間
+	FlushInterval time.Duration
+	// Logger は送信に失敗したバッチの記録先（nil の場合は log.Default）
+	Logger *log.Logger
+}
+
+// DefaultAsyncIndexerConfig はデフォルトの非同期インデクサー設定を返す
+func DefaultAsyncIndexerConfig() *AsyncIndexerConfig {
+	return &AsyncIndexerConfig{
+		QueueSize:     10000,
+		BatchSize:     500,
+		FlushInterval: time.Second,
+	}
+}
+
+// AsyncIndexerStats は非同期インデクサーの状態を表す
+type AsyncIndexerStats struct {
+	QueueDepth    int
+	QueueCapacity int
+	Enqueued      int64
+	Indexed       int64
+	Failed        int64
+	Rejected      int64
+}
+
+// asyncItem はキューに積まれたドキュメントと、登録を受け付けたリクエストの認証情報
+type asyncItem struct {
+	doc     *entity.Document
+	tenant  string
+	subject string
+}
+
+// AsyncIndexer はドキュメントをキューに積み、バックグラウンドでまとめてバルク登録する
+// キューが満杯の場合は待たずに拒否し、Close 後はキューに残ったドキュメントを送信してから停止する
+type AsyncIndexer struct {
+	documents *DocumentService
+	config    *AsyncIndexerConfig
+	queue     chan asyncItem
+
+	// mu は Close とキューへの送信が同時に行われないようにする
+	mu     sync.RWMutex
+	closed bool
+
+	enqueued atomic.Int64
+	indexed  atomic.Int64
+	failed   atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewAsyncIndexer は新しい AsyncIndexer を作成する
+func NewAsyncIndexer(documents *DocumentService, config *AsyncIndexerConfig) *AsyncIndexer {
+	if config == nil {
+		config = DefaultAsyncIndexerConfig()
+	}
+	defaults := DefaultAsyncIndexerConfig()
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
+
+	return &AsyncIndexer{
+		documents: documents,
+		config:    config,
+		queue:     make(chan asyncItem, config.QueueSize),
+	}
+}
+
+// Start はバックグラウンドのワーカーを開始する
+// ワーカーは wg に登録され、Close 後にキューを送信し終えると完了する
+func (a *AsyncIndexer) Start(wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.run()
+	}()
+}
+
+// Enqueue はドキュメントを検証してキューに積み、登録予定のドキュメントを返す
+// ID は受け付け時に発行するため、登録後にそのIDで取得できる
+func (a *AsyncIndexer) Enqueue(ctx context.Context, index string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error) {
+	index, err := a.documents.resolveIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source) == 0 {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{Field: "source", Message: "Document source cannot be empty"}})
+	}
+
+	// ドキュメントエンティティを作成
+	doc := entity.NewDocument(index, source)
+	doc.SetID(newDocumentID())
+	options := repository.NewDocumentOptions(opts...)
+	doc.Routing = options.Routing
+	doc.Pipeline = a.documents.resolvePipeline(index, options.Pipeline)
+	if err := a.documents.validateDocument(doc); err != nil {
+		return nil, err
+	}
+
+	item := asyncItem{doc: doc, tenant: auth.Tenant(ctx), subject: auth.Subject(ctx)}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil, errors.NewQueueFullError("Indexing queue is shutting down", "")
+	}
+	select {
+	case a.queue <- item:
+		a.enqueued.Add(1)
+		return doc, nil
+	default:
+		a.rejected.Add(1)
+		return nil, errors.NewQueueFullError("Indexing queue is full", "1")
+	}
+}
+
+// Close は新しい登録の受け付けを停止する（キューに残ったドキュメントはワーカーが送信する）
+func (a *AsyncIndexer) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+	a.closed = true
+	close(a.queue)
+}
+
+// Stats は現在のキューの状態と累計件数を返す
+func (a *AsyncIndexer) Stats() AsyncIndexerStats {
+	return AsyncIndexerStats{
+		QueueDepth:    len(a.queue),
+		QueueCapacity: cap(a.queue),
+		Enqueued:      a.enqueued.Load(),
+		Indexed:       a.indexed.Load(),
+		Failed:        a.failed.Load(),
+		Rejected:      a.rejected.Load(),
+	}
+}
+
+// run はキューからバッチを組み立て、件数が埋まるか一定時間が経過するたびに送信する
+func (a *AsyncIndexer) run() {
+	ticker := time.NewTicker(a.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]asyncItem, 0, a.config.BatchSize)
+	for {
+		select {
+		case item, ok := <-a.queue:
+			if !ok {
+				a.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) >= a.config.BatchSize {
+				a.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			a.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush はバッチをリクエスト元のテナントと主体ごとにまとめてバルク登録する
+func (a *AsyncIndexer) flush(batch []asyncItem) {
+	type origin struct{ tenant, subject string }
+	var origins []origin
+	groups := make(map[origin][]*entity.Document)
+	for _, item := range batch {
+		key := origin{tenant: item.tenant, subject: item.subject}
+		if _, exists := groups[key]; !exists {
+			origins = append(origins, key)
+		}
+		groups[key] = append(groups[key], item.doc)
+	}
+
+	for _, key := range origins {
+		docs := groups[key]
+		ctx := context.Background()
+		if key.tenant != "" {
+			ctx = auth.WithTenant(ctx, key.tenant)
+		}
+		if key.subject != "" {
+			ctx = auth.WithSubject(ctx, key.subject)
+		}
+
+		result, err := a.documents.BulkIndexDocuments(ctx, docs, entity.BulkOpCreate)
+		if err != nil {
+			a.failed.Add(int64(len(docs)))
+			a.config.Logger.Printf("Async indexer: failed to index %d documents: %v", len(docs), err)
+			continue
+		}
+		a.indexed.Add(int64(result.Succeeded))
+		if failed := len(docs) - result.Succeeded; failed > 0 {
+			a.failed.Add(int64(failed))
+			a.config.Logger.Printf("Async indexer: %d of %d documents were not indexed", failed, len(docs))
+		}
+	}
+}
+
+// newDocumentID は Elasticsearch の自動採番と同じ長さ（20文字）のランダムなIDを生成する
+func newDocumentID() string {
+	b := make([]byte, 15)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+// AuditLogger はドキュメントの変更操作を監査ログとして記録するインターフェース
+// 記録の失敗で変更操作自体を失敗させないよう、エラーは返さない
+type AuditLogger interface {
+	Record(ctx context.Context, event entity.AuditEvent)
+}
+
+// NopAuditLogger は何も記録しない AuditLogger
+type NopAuditLogger struct{}
+
+// Record は何もしない
+func (NopAuditLogger) Record(context.Context, entity.AuditEvent) {}
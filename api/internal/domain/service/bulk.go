@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// bulkActionOverhead はバルクボディでドキュメント1件ごとに加わるアクション行と改行の推定バイト数
+const bulkActionOverhead = 128
+
+// bulkChunks は件数と推定バイト数の上限を超えないようにドキュメントを分割する
+// 単独で上限を超えるドキュメントはそのドキュメントだけのチャンクとして送信する
+func bulkChunks(docs []*entity.Document, maxDocs int, maxBytes int64) [][]*entity.Document {
+	var chunks [][]*entity.Document
+	start := 0
+	var size int64
+	for i, doc := range docs {
+		docSize := estimateBulkItemSize(doc)
+		if i > start && (i-start >= maxDocs || size+docSize > maxBytes) {
+			chunks = append(chunks, docs[start:i])
+			start, size = i, 0
+		}
+		size += docSize
+	}
+	if start < len(docs) {
+		chunks = append(chunks, docs[start:])
+	}
+	return chunks
+}
+
+// estimateBulkItemSize はドキュメント1件がバルクボディに占めるおおよそのバイト数を返す
+func estimateBulkItemSize(doc *entity.Document) int64 {
+	body, err := json.Marshal(doc.Source)
+	if err != nil {
+		return bulkActionOverhead
+	}
+	return int64(len(body)) + bulkActionOverhead
+}
+
+// submitBulkChunks はチャンクを最大 concurrency 件ずつ並行して送信し、チャンクの順序で結果を集約する
+// 結果のアイテムは元のドキュメントと同じ順序になるため、失敗を送信元のドキュメントに対応付けられる
+// 全てのチャンクが失敗した場合（何も登録されていない場合）は最初のエラーを返す
+// progress が nil でなければ、チャンクの送信が完了するたびに（完了順に）進捗を通知する
+// refresh はチャンクごとのリクエストに適用する（空の場合はElasticsearchのデフォルト）
+func (s *DocumentService) submitBulkChunks(ctx context.Context, chunks [][]*entity.Document, opType entity.BulkOpType, concurrency int, refresh entity.RefreshPolicy, progress func(entity.BulkProgress)) (*entity.BulkResult, error) {
+	results := make([]*entity.BulkResult, len(chunks))
+	errs := make([]error, len(chunks))
+	tracker := newBulkProgressTracker(chunks, progress)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// キャンセル後は残りのチャンクを送信しない
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i], errs[i] = s.repo.BulkIndex(ctx, chunks[i], opType, repository.WithRefresh(refresh))
+				tracker.done(len(chunks[i]), results[i], errs[i])
+			}
+		}()
+	}
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &entity.BulkResult{}
+	var firstErr error
+	failedChunks := 0
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			failedChunks++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			// 送信済みのチャンクは取り消せないため、失敗したチャンクのドキュメントはアイテムとして報告する
+			addFailedBulkItems(result, chunk, errs[i])
+			continue
+		}
+		result.Merge(results[i])
+	}
+	if failedChunks == len(chunks) {
+		return nil, firstErr
+	}
+
+	return result, nil
+}
+
+// bulkProgressTracker は並行して送信されるチャンクの完了を集計し、進捗を通知する
+type bulkProgressTracker struct {
+	mu       sync.Mutex
+	notify   func(entity.BulkProgress)
+	progress entity.BulkProgress
+}
+
+// newBulkProgressTracker は新しい bulkProgressTracker を作成する（notify が nil の場合は何もしない）
+func newBulkProgressTracker(chunks [][]*entity.Document, notify func(entity.BulkProgress)) *bulkProgressTracker {
+	tracker := &bulkProgressTracker{notify: notify}
+	tracker.progress.TotalChunks = len(chunks)
+	for _, chunk := range chunks {
+		tracker.progress.Total += len(chunk)
+	}
+	return tracker
+}
+
+// done はチャンク1つの完了を記録して進捗を通知する
+// リクエスト自体が失敗したチャンクは、全てのドキュメントを失敗として数える
+func (t *bulkProgressTracker) done(size int, result *entity.BulkResult, err error) {
+	if t.notify == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.CompletedChunks++
+	t.progress.Processed += size
+	switch {
+	case err != nil:
+		t.progress.Errors += size
+	case result != nil:
+		t.progress.Errors += result.Conflicts + result.Failed
+	}
+	t.notify(t.progress)
+}
+
+// addFailedBulkItems はリクエスト自体が失敗したチャンクのドキュメントを失敗アイテムとして追加する
+func addFailedBulkItems(result *entity.BulkResult, chunk []*entity.Document, err error) {
+	status := http.StatusInternalServerError
+	code := errors.ErrCodeDocumentCreateFailed
+	if appErr := errors.GetAppError(err); appErr != nil {
+		status = appErr.HTTPStatus
+		code = appErr.Code
+	}
+
+	for _, doc := range chunk {
+		result.AddItem(entity.BulkItemResult{
+			Index:     doc.Index,
+			ID:        doc.ID,
+			Status:    status,
+			ErrorType: "bulk_request_failed",
+			Error:     err.Error(),
+			ErrorCode: string(code),
+		})
+	}
+}
+
+// classifyBulkItemErrors は失敗したアイテムに、Elasticsearch のエラー種別とステータスから
+// APIのエラーレスポンスと同じエラーコードを設定する（クライアントがアイテムごとに対処を判断できるように）
+func classifyBulkItemErrors(result *entity.BulkResult, opType entity.BulkOpType) {
+	for i := range result.Items {
+		item := &result.Items[i]
+		if !item.Failed() || item.ErrorCode != "" {
+			continue
+		}
+		item.ErrorCode = string(bulkItemErrorCode(*item, opType))
+	}
+}
+
+// bulkItemErrorCode は失敗したアイテムのエラーコードを返す
+func bulkItemErrorCode(item entity.BulkItemResult, opType entity.BulkOpType) errors.ErrorCode {
+	switch {
+	case item.Conflict && opType == entity.BulkOpCreate:
+		return errors.ErrCodeDocumentExists
+	case item.Conflict:
+		return errors.ErrCodeVersionConflict
+	case item.ErrorType == "index_not_found_exception":
+		return errors.ErrCodeIndexNotFound
+	case item.ErrorType == "document_missing_exception" || item.Status == http.StatusNotFound:
+		return errors.ErrCodeDocumentNotFound
+	case item.Status == http.StatusTooManyRequests:
+		return errors.ErrCodeRateLimited
+	case item.Status == http.StatusRequestEntityTooLarge:
+		return errors.ErrCodePayloadTooLarge
+	case item.Status >= 400 && item.Status < 500:
+		return errors.ErrCodeInvalidDocument
+	case opType == entity.BulkOpUpsert:
+		return errors.ErrCodeDocumentUpdateFailed
+	default:
+		return errors.ErrCodeDocumentCreateFailed
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// bulkRepository は一定の遅延の後に全てのドキュメントを登録済みとして返すリポジトリ
+// failIndex に一致するインデックスのドキュメントを含むチャンクはリクエストごと失敗させる
+// 最後に受け取ったリフレッシュの方法を refresh に記録する
+type bulkRepository struct {
+	repository.ElasticsearchRepository
+	latency   time.Duration
+	failIndex string
+
+	mu      sync.Mutex
+	refresh entity.RefreshPolicy
+}
+
+func (r *bulkRepository) BulkIndex(ctx context.Context, docs []*entity.Document, _ entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkResult, error) {
+	r.mu.Lock()
+	r.refresh = repository.NewDocumentOptions(opts...).Refresh
+	r.mu.Unlock()
+
+	select {
+	case <-time.After(r.latency):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	result := &entity.BulkResult{}
+	for _, doc := range docs {
+		if doc.Index == r.failIndex {
+			return nil, errors.NewAppError(errors.ErrCodeElasticsearchDown, "cluster unavailable")
+		}
+		result.AddItem(entity.BulkItemResult{Index: doc.Index, ID: doc.ID, Status: http.StatusCreated})
+	}
+	return result, nil
+}
+
+// bulkDocuments は index のドキュメントを n 件作成する
+func bulkDocuments(index string, n int) []*entity.Document {
+	docs := make([]*entity.Document, n)
+	for i := range docs {
+		docs[i] = &entity.Document{Index: index, ID: fmt.Sprintf("%s-%d", index, i), Source: map[string]any{"n": i}}
+	}
+	return docs
+}
+
+func TestSubmitBulkChunks(t *testing.T) {
+	docs := append(bulkDocuments("articles", 6), append(bulkDocuments("broken", 2), bulkDocuments("blogs", 4)...)...)
+
+	tests := []struct {
+		name        string
+		concurrency int
+	}{
+		{name: "sequential", concurrency: 1},
+		{name: "concurrent", concurrency: 4},
+		{name: "more workers than chunks", concurrency: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDocumentService(&bulkRepository{latency: time.Millisecond, failIndex: "broken"})
+			chunks := bulkChunks(docs, 2, 10<<20)
+
+			result, err := s.submitBulkChunks(context.Background(), chunks, entity.BulkOpIndex, tt.concurrency, entity.RefreshFalse, nil)
+			if err != nil {
+				t.Fatalf("submitBulkChunks() error = %v", err)
+			}
+
+			// 結果のアイテムは元のドキュメントと同じ順序で、失敗したチャンクのドキュメントに対応付けられる
+			if len(result.Items) != len(docs) {
+				t.Fatalf("len(items) = %d, want %d", len(result.Items), len(docs))
+			}
+			for i, item := range result.Items {
+				if item.ID != docs[i].ID {
+					t.Fatalf("items[%d].ID = %s, want %s", i, item.ID, docs[i].ID)
+				}
+				wantFailed := docs[i].Index == "broken"
+				if item.Failed() != wantFailed {
+					t.Errorf("items[%d] (%s) failed = %v, want %v", i, item.ID, item.Failed(), wantFailed)
+				}
+				if wantFailed && item.ErrorCode != string(errors.ErrCodeElasticsearchDown) {
+					t.Errorf("items[%d].ErrorCode = %s, want %s", i, item.ErrorCode, errors.ErrCodeElasticsearchDown)
+				}
+			}
+			if result.Succeeded != 10 || result.Failed != 2 {
+				t.Errorf("succeeded = %d, failed = %d, want 10 and 2", result.Succeeded, result.Failed)
+			}
+		})
+	}
+}
+
+func TestSubmitBulkChunksAllFailed(t *testing.T) {
+	s := NewDocumentService(&bulkRepository{failIndex: "broken"})
+	chunks := bulkChunks(bulkDocuments("broken", 4), 2, 10<<20)
+
+	_, err := s.submitBulkChunks(context.Background(), chunks, entity.BulkOpIndex, 2, entity.RefreshFalse, nil)
+	if !errors.HasCode(err, errors.ErrCodeElasticsearchDown) {
+		t.Errorf("submitBulkChunks() error = %v, want ELASTICSEARCH_DOWN", err)
+	}
+}
+
+func TestSubmitBulkChunksCanceled(t *testing.T) {
+	s := NewDocumentService(&bulkRepository{latency: time.Second})
+	chunks := bulkChunks(bulkDocuments("articles", 8), 2, 10<<20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.submitBulkChunks(ctx, chunks, entity.BulkOpIndex, 2, entity.RefreshFalse, nil)
+	if err != context.Canceled {
+		t.Errorf("submitBulkChunks() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBulkIndexDocumentsRefresh(t *testing.T) {
+	tests := []struct {
+		name   string
+		config entity.RefreshPolicy
+		opts   []repository.DocumentOption
+		want   entity.RefreshPolicy
+	}{
+		{name: "default does not force a refresh", want: entity.RefreshFalse},
+		{name: "configured default", config: entity.RefreshWaitFor, want: entity.RefreshWaitFor},
+		{name: "request overrides config", config: entity.RefreshWaitFor, opts: []repository.DocumentOption{repository.WithRefresh(entity.RefreshTrue)}, want: entity.RefreshTrue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &bulkRepository{}
+			s := NewDocumentServiceWithConfig(repo, &DocumentConfig{BulkRefresh: tt.config})
+
+			if _, err := s.BulkIndexDocuments(context.Background(), bulkDocuments("articles", 2), entity.BulkOpIndex, tt.opts...); err != nil {
+				t.Fatalf("BulkIndexDocuments() error = %v", err)
+			}
+			if repo.refresh != tt.want {
+				t.Errorf("refresh = %q, want %q", repo.refresh, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkSubmitBulkChunks は Elasticsearch の往復時間（2ms）を模したリポジトリで、
+// 並行数ごとの 10,000 件（100 チャンク）の送信時間を比較する
+func BenchmarkSubmitBulkChunks(b *testing.B) {
+	docs := bulkDocuments("articles", 10000)
+	chunks := bulkChunks(docs, 100, 10<<20)
+
+	for _, concurrency := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			s := NewDocumentService(&bulkRepository{latency: 2 * time.Millisecond})
+			for b.Loop() {
+				if _, err := s.submitBulkChunks(context.Background(), chunks, entity.BulkOpIndex, concurrency, entity.RefreshFalse, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(docs)*b.N)/b.Elapsed().Seconds(), "docs/s")
+		})
+	}
+}
+
+func TestBulkItemErrorCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   entity.BulkItemResult
+		opType entity.BulkOpType
+		want   errors.ErrorCode
+	}{
+		{name: "existing id on create", item: entity.BulkItemResult{Status: http.StatusConflict, Conflict: true}, opType: entity.BulkOpCreate, want: errors.ErrCodeDocumentExists},
+		{name: "version conflict on upsert", item: entity.BulkItemResult{Status: http.StatusConflict, Conflict: true}, opType: entity.BulkOpUpsert, want: errors.ErrCodeVersionConflict},
+		{name: "missing index", item: entity.BulkItemResult{Status: http.StatusNotFound, ErrorType: "index_not_found_exception"}, opType: entity.BulkOpIndex, want: errors.ErrCodeIndexNotFound},
+		{name: "missing document", item: entity.BulkItemResult{Status: http.StatusNotFound, ErrorType: "document_missing_exception"}, opType: entity.BulkOpUpsert, want: errors.ErrCodeDocumentNotFound},
+		{name: "rejected by a full queue", item: entity.BulkItemResult{Status: http.StatusTooManyRequests, ErrorType: "es_rejected_execution_exception"}, opType: entity.BulkOpIndex, want: errors.ErrCodeRateLimited},
+		{name: "too large", item: entity.BulkItemResult{Status: http.StatusRequestEntityTooLarge}, opType: entity.BulkOpIndex, want: errors.ErrCodePayloadTooLarge},
+		{name: "mapping error", item: entity.BulkItemResult{Status: http.StatusBadRequest, ErrorType: "mapper_parsing_exception"}, opType: entity.BulkOpIndex, want: errors.ErrCodeInvalidDocument},
+		{name: "server error on index", item: entity.BulkItemResult{Status: http.StatusInternalServerError}, opType: entity.BulkOpIndex, want: errors.ErrCodeDocumentCreateFailed},
+		{name: "server error on upsert", item: entity.BulkItemResult{Status: http.StatusServiceUnavailable}, opType: entity.BulkOpUpsert, want: errors.ErrCodeDocumentUpdateFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bulkItemErrorCode(tt.item, tt.opType); got != tt.want {
+				t.Errorf("bulkItemErrorCode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyBulkItemErrors(t *testing.T) {
+	result := &entity.BulkResult{}
+	result.AddItem(entity.BulkItemResult{ID: "1", Status: http.StatusCreated})
+	result.AddItem(entity.BulkItemResult{ID: "2", Status: http.StatusBadRequest, ErrorType: "mapper_parsing_exception", Error: "failed to parse"})
+	result.AddItem(entity.BulkItemResult{ID: "3", Status: http.StatusServiceUnavailable, ErrorCode: string(errors.ErrCodeElasticsearchDown)})
+
+	classifyBulkItemErrors(result, entity.BulkOpIndex)
+
+	want := []string{"", string(errors.ErrCodeInvalidDocument), string(errors.ErrCodeElasticsearchDown)}
+	for i, item := range result.Items {
+		if item.ErrorCode != want[i] {
+			t.Errorf("items[%d].ErrorCode = %q, want %q", i, item.ErrorCode, want[i])
+		}
+	}
+}
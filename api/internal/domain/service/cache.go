@@ -0,0 +1,40 @@
+package service
+
+import (
+	"slices"
+	"strings"
+)
+
+// 検索サービスが保持するキャッシュの名前
+const (
+	CacheResultWindow = "result_window"
+	CacheIndexExists  = "index_exists"
+	CacheFieldMapping = "field_mapping"
+	CacheSearchResult = "search_result"
+)
+
+// ClearCaches は検索サービスのキャッシュ（インデックスの max_result_window、存在確認、マッピング上のフィールド、検索結果）を削除し、
+// キャッシュごとの削除件数を返す。index を指定した場合はそのインデックスのエントリのみを全テナント分削除する
+func (s *SearchService) ClearCaches(index string) map[string]int {
+	var match func(key string) bool
+	if index != "" {
+		match = func(key string) bool {
+			return cacheKeyHasIndex(key, index)
+		}
+	}
+
+	return map[string]int{
+		CacheResultWindow: s.resultWindows.clear(match),
+		CacheIndexExists:  s.indexExists.clear(match),
+		CacheFieldMapping: s.knownFields.clear(match),
+		CacheSearchResult: s.results.clear(match),
+	}
+}
+
+// cacheKeyHasIndex はキャッシュのキー（"テナント/インデックス[/...]"）が index を対象とするかどうかを返す
+// カンマ区切りで複数のインデックスを対象とするキーは、いずれかが一致すれば対象とする
+func cacheKeyHasIndex(key, index string) bool {
+	_, rest, _ := strings.Cut(key, "/")
+	target, _, _ := strings.Cut(rest, "/")
+	return slices.Contains(strings.Split(target, ","), index)
+}
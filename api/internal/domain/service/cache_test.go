@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+func TestCacheKeyHasIndex(t *testing.T) {
+	tests := []struct {
+		key   string
+		index string
+		want  bool
+	}{
+		{key: "/articles", index: "articles", want: true},
+		{key: "acme/articles", index: "articles", want: true},
+		{key: "acme/articles/title", index: "articles", want: true},
+		{key: "acme/blogs,articles/golang", index: "articles", want: true},
+		{key: "acme/articles-2024", index: "articles", want: false},
+		{key: "acme/blogs/articles", index: "articles", want: false},
+		{key: "articles", index: "articles", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := cacheKeyHasIndex(tt.key, tt.index); got != tt.want {
+				t.Errorf("cacheKeyHasIndex(%q, %q) = %v, want %v", tt.key, tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearCaches(t *testing.T) {
+	config := DefaultSearchConfig()
+	config.ResultWindowCacheTTL = time.Minute
+	config.IndexExistsCacheTTL = time.Minute
+	config.FieldMappingCacheTTL = time.Minute
+	config.ResultCacheTTL = time.Minute
+	s := NewSearchServiceWithConfig(nil, config)
+
+	// 2つのテナントが articles と blogs をそれぞれキャッシュしている状態
+	for _, tenant := range []string{"", "acme"} {
+		for _, index := range []string{"articles", "blogs"} {
+			s.resultWindows.set(tenant+"/"+index, 10000)
+			s.indexExists.set(tenant + "/" + index)
+			s.knownFields.set(tenant + "/" + index + "/title")
+			s.results.set(tenant+"/"+index+"/golang", &entity.SearchResult{})
+		}
+	}
+	s.results.set("acme/articles,blogs/golang", &entity.SearchResult{})
+
+	want := map[string]int{CacheResultWindow: 2, CacheIndexExists: 2, CacheFieldMapping: 2, CacheSearchResult: 3}
+	got := s.ClearCaches("articles")
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("ClearCaches(articles)[%s] = %d, want %d", name, got[name], n)
+		}
+	}
+
+	want = map[string]int{CacheResultWindow: 2, CacheIndexExists: 2, CacheFieldMapping: 2, CacheSearchResult: 2}
+	got = s.ClearCaches("")
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("ClearCaches()[%s] = %d, want %d", name, got[name], n)
+		}
+	}
+}
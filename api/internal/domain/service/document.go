@@ -3,49 +3,161 @@ package service
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
 	"strings"
 	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
 	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 )
 
 // DocumentHandler はドキュメントサービスのインターフェース
 type DocumentHandler interface {
-	CreateDocument(ctx context.Context, index string, source map[string]any) (*entity.Document, error)
-	GetDocument(ctx context.Context, index, id string) (*entity.Document, error)
-	UpdateDocument(ctx context.Context, index, id string, source map[string]any) (*entity.Document, error)
-	DeleteDocument(ctx context.Context, index, id string) error
-	BulkIndexDocuments(ctx context.Context, docs []*entity.Document) error
-	CreateDocumentWithID(ctx context.Context, index, id string, source map[string]any) (*entity.Document, error)
+	CreateDocument(ctx context.Context, index string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error)
+	CreateRawDocument(ctx context.Context, index string, raw []byte, opts ...repository.DocumentOption) (*entity.Document, error)
+	GetDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) (*entity.Document, error)
+	GetDocumentRaw(ctx context.Context, index, id string, opts ...repository.DocumentOption) (io.ReadCloser, error)
+	DiffDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, *entity.DocumentDiff, error)
+	UpdateDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error)
+	UpdateDocumentIfMatch(ctx context.Context, index, id string, source map[string]any, seqNo, primaryTerm int64, opts ...repository.DocumentOption) (*entity.Document, error)
+	UpsertDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error)
+	ReplaceDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, bool, error)
+	DeleteDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) error
+	BulkIndexDocuments(ctx context.Context, docs []*entity.Document, opType entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkResult, error)
+	BulkIndexDocumentsWithProgress(ctx context.Context, docs []*entity.Document, opType entity.BulkOpType, progress func(entity.BulkProgress), opts ...repository.DocumentOption) (*entity.BulkResult, error)
+	ValidateBulkDocuments(ctx context.Context, docs []*entity.Document, opType entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkValidation, error)
+	CreateDocumentWithID(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error)
+	CreateDocumentIdempotent(ctx context.Context, key, index string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, bool, error)
+}
+
+// DocumentConfig はドキュメントサービスの設定を表す
+type DocumentConfig struct {
+	// DefaultPipelines はパイプライン未指定時にインデックスごとに適用するインジェストパイプライン
+	DefaultPipelines map[string]string
+	// DefaultIndex はインデックス未指定で作成されるドキュメントの登録先（空の場合は指定必須）
+	DefaultIndex string
+	// AuditLogger は変更操作の監査イベントの記録先（nil の場合は記録しない）
+	AuditLogger AuditLogger
+	// AuditIncludeSource が true の場合、監査イベントにドキュメントの内容を含める
+	AuditIncludeSource bool
+	// BulkChunkMaxDocs と BulkChunkMaxBytes は一括登録を分割送信する際の1リクエストあたりの上限
+	// （件数と推定バイト数。Elasticsearch の http.max_content_length を超えないようにする）
+	BulkChunkMaxDocs  int
+	BulkChunkMaxBytes int64
+	// BulkConcurrency は分割したチャンクを並行して送信する最大数（1の場合は順に送信する）
+	BulkConcurrency int
+	// UpdateRetryOnConflict はリクエストで指定がない場合の部分更新の競合時再試行回数（0の場合は再試行しない）
+	UpdateRetryOnConflict int
+	// WriteRefresh と BulkRefresh はリクエストで指定がない場合の単一ドキュメントの書き込みと一括登録のリフレッシュの方法
+	WriteRefresh entity.RefreshPolicy
+	BulkRefresh  entity.RefreshPolicy
+	// IdempotencyKeyTTL は冪等キーごとの作成結果を保持する期間（0の場合は冪等キーを無視する）
+	IdempotencyKeyTTL time.Duration
+	// IdempotencyMaxKeys は保持する冪等キーの上限（超えた場合は古いものから破棄する。0の場合は上限なし）
+	IdempotencyMaxKeys int
+}
+
+// DefaultDocumentConfig はデフォルトのドキュメント設定を返す
+func DefaultDocumentConfig() *DocumentConfig {
+	return &DocumentConfig{
+		DefaultPipelines:      map[string]string{},
+		AuditLogger:           NopAuditLogger{},
+		BulkChunkMaxDocs:      1000,
+		BulkChunkMaxBytes:     10 << 20,
+		BulkConcurrency:       1,
+		UpdateRetryOnConflict: 3,
+		WriteRefresh:          entity.RefreshTrue,
+		BulkRefresh:           entity.RefreshFalse,
+		IdempotencyKeyTTL:     time.Hour,
+		IdempotencyMaxKeys:    10000,
+	}
+}
+
+// Validate は設定値が有効かどうかを検証する
+func (c *DocumentConfig) Validate() error {
+	if !c.WriteRefresh.IsValid() {
+		return fmt.Errorf("invalid write refresh policy: %s (must be true, false or wait_for)", c.WriteRefresh)
+	}
+	if !c.BulkRefresh.IsValid() {
+		return fmt.Errorf("invalid bulk refresh policy: %s (must be true, false or wait_for)", c.BulkRefresh)
+	}
+	return nil
 }
 
 // DocumentService はドキュメント操作のビジネスロジックを提供する
 type DocumentService struct {
-	repo repository.ElasticsearchRepository
+	repo        repository.ElasticsearchRepository
+	config      *DocumentConfig
+	idempotency *idempotencyStore // 冪等キーが無効な場合は nil
 }
 
 // NewDocumentService は新しいDocumentServiceを作成する
 func NewDocumentService(repo repository.ElasticsearchRepository) *DocumentService {
-	return &DocumentService{
-		repo: repo,
+	return NewDocumentServiceWithConfig(repo, DefaultDocumentConfig())
+}
+
+// NewDocumentServiceWithConfig は設定を指定して新しいDocumentServiceを作成する
+func NewDocumentServiceWithConfig(repo repository.ElasticsearchRepository, config *DocumentConfig) *DocumentService {
+	defaults := DefaultDocumentConfig()
+	if config == nil {
+		config = defaults
+	}
+	if config.BulkChunkMaxDocs <= 0 {
+		config.BulkChunkMaxDocs = defaults.BulkChunkMaxDocs
+	}
+	if config.BulkChunkMaxBytes <= 0 {
+		config.BulkChunkMaxBytes = defaults.BulkChunkMaxBytes
+	}
+	if config.BulkConcurrency <= 0 {
+		config.BulkConcurrency = defaults.BulkConcurrency
+	}
+	if config.UpdateRetryOnConflict < 0 {
+		config.UpdateRetryOnConflict = defaults.UpdateRetryOnConflict
 	}
+	if config.WriteRefresh == "" {
+		config.WriteRefresh = defaults.WriteRefresh
+	}
+	if config.BulkRefresh == "" {
+		config.BulkRefresh = defaults.BulkRefresh
+	}
+	if config.DefaultPipelines == nil {
+		config.DefaultPipelines = map[string]string{}
+	}
+	if config.AuditLogger == nil {
+		config.AuditLogger = NopAuditLogger{}
+	}
+
+	service := &DocumentService{
+		repo:   repo,
+		config: config,
+	}
+	if config.IdempotencyKeyTTL > 0 {
+		service.idempotency = newIdempotencyStore(config.IdempotencyKeyTTL, config.IdempotencyMaxKeys)
+	}
+	return service
 }
 
 // CreateDocument は新しいドキュメントを作成する
-func (s *DocumentService) CreateDocument(ctx context.Context, index string, source map[string]any) (*entity.Document, error) {
+func (s *DocumentService) CreateDocument(ctx context.Context, index string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error) {
 	// 入力を検証
-	if index == "" {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	index, err := s.resolveIndex(index)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(source) == 0 {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{Field: "source", Message: "Document source cannot be empty"}})
 	}
 
 	// ドキュメントエンティティを作成
 	doc := entity.NewDocument(index, source)
+	options := repository.NewDocumentOptions(opts...)
+	doc.Routing = options.Routing
+	doc.Pipeline = s.resolvePipeline(index, options.Pipeline)
+	doc.Refresh = s.resolveRefresh(options.Refresh, s.config.WriteRefresh)
 
 	// ビジネスルールを適用
 	if err := s.applyBusinessRules(doc); err != nil {
@@ -56,12 +168,62 @@ func (s *DocumentService) CreateDocument(ctx context.Context, index string, sour
 	if err := s.repo.CreateDocument(ctx, doc); err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to create document")
 	}
+	s.audit(ctx, entity.AuditOperationCreate, doc.Index, doc.ID, doc.Source)
+
+	return doc, nil
+}
+
+// CreateRawDocument は未加工のJSONオブジェクトから新しいドキュメントを作成する
+// map への変換を経由しないため、数値の精度やフィールドの順序はリクエストのまま保たれる。
+// ビジネスルール（タイムスタンプの付与など）は変更するトップレベルのフィールドにのみ適用する。
+// 返すドキュメントの Source は nil
+func (s *DocumentService) CreateRawDocument(ctx context.Context, index string, raw []byte, opts ...repository.DocumentOption) (*entity.Document, error) {
+	// 入力を検証
+	index, err := s.resolveIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := entity.ParseRawSource(raw)
+	if err != nil {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{Field: "source", Message: "Document source must be a JSON object"}})
+	}
+	if source.Len() == 0 {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{Field: "source", Message: "Document source cannot be empty"}})
+	}
+
+	// ドキュメントエンティティを作成
+	doc := entity.NewRawDocument(index, source)
+	options := repository.NewDocumentOptions(opts...)
+	doc.Routing = options.Routing
+	doc.Pipeline = s.resolvePipeline(index, options.Pipeline)
+	doc.Refresh = s.resolveRefresh(options.Refresh, s.config.WriteRefresh)
+
+	// ビジネスルールを適用
+	if err := s.applyBusinessRules(doc); err != nil {
+		return nil, err
+	}
+	if err := source.Err(); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInvalidDocument, "Failed to apply business rules to document")
+	}
+
+	// リポジトリに保存
+	if err := s.repo.CreateDocument(ctx, doc); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to create document")
+	}
+
+	// 監査ログにソースを含める場合のみ map に変換する
+	var auditSource map[string]any
+	if s.config.AuditIncludeSource {
+		auditSource, _ = source.Map()
+	}
+	s.audit(ctx, entity.AuditOperationCreate, doc.Index, doc.ID, auditSource)
 
 	return doc, nil
 }
 
 // GetDocument はIDでドキュメントを取得する
-func (s *DocumentService) GetDocument(ctx context.Context, index, id string) (*entity.Document, error) {
+func (s *DocumentService) GetDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) (*entity.Document, error) {
 	if index == "" {
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
 	}
@@ -70,7 +232,7 @@ func (s *DocumentService) GetDocument(ctx context.Context, index, id string) (*e
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document ID cannot be empty")
 	}
 
-	doc, err := s.repo.GetDocument(ctx, index, id)
+	doc, err := s.repo.GetDocument(ctx, index, id, opts...)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Document not found")
 	}
@@ -78,8 +240,41 @@ func (s *DocumentService) GetDocument(ctx context.Context, index, id string) (*e
 	return doc, nil
 }
 
+// GetDocumentRaw はドキュメントの_sourceを加工せずにストリームとして取得する
+func (s *DocumentService) GetDocumentRaw(ctx context.Context, index, id string, opts ...repository.DocumentOption) (io.ReadCloser, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if id == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document ID cannot be empty")
+	}
+
+	body, err := s.repo.GetDocumentRaw(ctx, index, id, opts...)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Document not found")
+	}
+
+	return body, nil
+}
+
+// DiffDocument は現在のドキュメントと変更案のソースのフィールド単位の差分を返す
+// 書き込みは行わない。比較に使った現在のドキュメントも返す
+func (s *DocumentService) DiffDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, *entity.DocumentDiff, error) {
+	if len(source) == 0 {
+		return nil, nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+	}
+
+	current, err := s.GetDocument(ctx, index, id, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return current, entity.DiffSources(current.Source, source), nil
+}
+
 // UpdateDocument は既存のドキュメントを更新する
-func (s *DocumentService) UpdateDocument(ctx context.Context, index, id string, source map[string]any) (*entity.Document, error) {
+func (s *DocumentService) UpdateDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error) {
 	if index == "" {
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
 	}
@@ -93,29 +288,179 @@ func (s *DocumentService) UpdateDocument(ctx context.Context, index, id string,
 	}
 
 	// 既存のドキュメントを取得
-	doc, err := s.repo.GetDocument(ctx, index, id)
+	doc, err := s.repo.GetDocument(ctx, index, id, opts...)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Document not found")
 	}
 
 	// ドキュメントを更新
 	doc.UpdateSource(source)
+	doc.Refresh = s.resolveRefresh(repository.NewDocumentOptions(opts...).Refresh, s.config.WriteRefresh)
 
 	// ビジネスルールを適用
 	if err := s.applyBusinessRules(doc); err != nil {
 		return nil, err
 	}
 
-	// リポジトリに保存
-	if err := s.repo.UpdateDocument(ctx, doc); err != nil {
+	// 取得した版を条件に保存し、取得後に削除されたドキュメントを作り直さないようにする
+	if err := s.repo.UpdateDocumentIfMatch(ctx, doc, doc.SeqNo, doc.PrimaryTerm); err != nil {
+		if errors.HasCode(err, errors.ErrCodePreconditionFailed) {
+			return nil, err
+		}
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to update document")
 	}
+	s.audit(ctx, entity.AuditOperationUpdate, doc.Index, doc.ID, doc.Source)
+
+	return doc, nil
+}
+
+// UpdateDocumentIfMatch はシーケンス番号とプライマリタームが一致する場合のみドキュメントを更新する
+func (s *DocumentService) UpdateDocumentIfMatch(ctx context.Context, index, id string, source map[string]any, seqNo, primaryTerm int64, opts ...repository.DocumentOption) (*entity.Document, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if id == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document ID cannot be empty")
+	}
+
+	if len(source) == 0 {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+	}
+
+	// 既存のドキュメントを取得
+	doc, err := s.repo.GetDocument(ctx, index, id, opts...)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Document not found")
+	}
+
+	// 取得時点で既に変更されていれば書き込まずに失敗させる
+	if doc.SeqNo != seqNo || doc.PrimaryTerm != primaryTerm {
+		return nil, errors.NewPreconditionFailedError(index, id)
+	}
+
+	// ドキュメントを更新
+	doc.UpdateSource(source)
+	doc.Refresh = s.resolveRefresh(repository.NewDocumentOptions(opts...).Refresh, s.config.WriteRefresh)
+
+	// ビジネスルールを適用
+	if err := s.applyBusinessRules(doc); err != nil {
+		return nil, err
+	}
+
+	// 取得後の競合はElasticsearch側の楽観的同時実行制御で検出する
+	if err := s.repo.UpdateDocumentIfMatch(ctx, doc, seqNo, primaryTerm); err != nil {
+		if errors.HasCode(err, errors.ErrCodePreconditionFailed) {
+			return nil, err
+		}
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to update document")
+	}
+	s.audit(ctx, entity.AuditOperationUpdate, doc.Index, doc.ID, doc.Source)
+
+	return doc, nil
+}
+
+// UpsertDocument はドキュメントが存在しなければ作成し、存在すればマージする
+// 同時更新による競合はElasticsearch側で retry_on_conflict 回まで再試行される
+func (s *DocumentService) UpsertDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if id == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document ID cannot be empty")
+	}
+
+	if len(source) == 0 {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+	}
+
+	options := repository.NewDocumentOptions(opts...)
+	retryOnConflict := s.config.UpdateRetryOnConflict
+	if options.RetryOnConflict != nil {
+		if *options.RetryOnConflict < 0 {
+			return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Retry on conflict must be non-negative")
+		}
+		retryOnConflict = *options.RetryOnConflict
+	}
+
+	// ドキュメントエンティティを作成
+	doc := entity.NewDocument(index, source)
+	doc.Routing = options.Routing
+	doc.RetryOnConflict = retryOnConflict
+	doc.Refresh = s.resolveRefresh(options.Refresh, s.config.WriteRefresh)
+	doc.SetID(id)
+
+	// 既存ドキュメントへのマージと新規作成でルールを分けて適用する
+	if err := s.applyUpsertRules(doc); err != nil {
+		return nil, err
+	}
+
+	// 単一のリクエストでアップサート
+	if err := s.repo.UpsertDocument(ctx, doc); err != nil {
+		if errors.HasCode(err, errors.ErrCodeVersionConflict) {
+			return nil, err
+		}
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to upsert document")
+	}
+	s.audit(ctx, entity.AuditOperationUpsert, doc.Index, doc.ID, doc.Source)
 
 	return doc, nil
 }
 
+// ReplaceDocument は指定されたIDのドキュメントを作成、または既存のソースを丸ごと置き換える
+// 単一のインデックスリクエストで行うため存在確認との競合は起きない。新規作成された場合は true を返す
+// 外部バージョンを指定した場合、保存済みのバージョン以下での書き込みは VERSION_CONFLICT となる
+func (s *DocumentService) ReplaceDocument(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, bool, error) {
+	if index == "" {
+		return nil, false, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if id == "" {
+		return nil, false, errors.NewAppError(errors.ErrCodeValidationFailed, "Document ID cannot be empty")
+	}
+
+	if len(source) == 0 {
+		return nil, false, errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+	}
+
+	options := repository.NewDocumentOptions(opts...)
+	if options.ExternalVersion < 0 {
+		return nil, false, errors.NewAppError(errors.ErrCodeValidationFailed, "External version must be non-negative")
+	}
+
+	// ドキュメントエンティティを作成
+	doc := entity.NewDocument(index, source)
+	doc.Routing = options.Routing
+	doc.Pipeline = s.resolvePipeline(index, options.Pipeline)
+	doc.Refresh = s.resolveRefresh(options.Refresh, s.config.WriteRefresh)
+	doc.ExternalVersion = options.ExternalVersion
+	doc.SetID(id)
+
+	// ビジネスルールを適用
+	if err := s.applyBusinessRules(doc); err != nil {
+		return nil, false, err
+	}
+
+	// リポジトリに保存
+	created, err := s.repo.ReplaceDocument(ctx, doc)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeVersionConflict) {
+			return nil, false, err
+		}
+		return nil, false, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to replace document")
+	}
+	operation := entity.AuditOperationUpdate
+	if created {
+		operation = entity.AuditOperationCreate
+	}
+	s.audit(ctx, operation, doc.Index, doc.ID, doc.Source)
+
+	return doc, created, nil
+}
+
 // DeleteDocument はドキュメントを削除する
-func (s *DocumentService) DeleteDocument(ctx context.Context, index, id string) error {
+func (s *DocumentService) DeleteDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) error {
 	if index == "" {
 		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
 	}
@@ -125,49 +470,176 @@ func (s *DocumentService) DeleteDocument(ctx context.Context, index, id string)
 	}
 
 	// ドキュメントの存在確認
-	_, err := s.repo.GetDocument(ctx, index, id)
+	doc, err := s.repo.GetDocument(ctx, index, id, opts...)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Document not found")
 	}
 
-	// ドキュメントを削除
-	if err := s.repo.DeleteDocument(ctx, index, id); err != nil {
+	// 条件付き削除の場合、取得時点で既に変更されていれば削除せずに失敗させる
+	options := repository.NewDocumentOptions(opts...)
+	if options.IfSeqNo != nil && options.IfPrimaryTerm != nil &&
+		(doc.SeqNo != *options.IfSeqNo || doc.PrimaryTerm != *options.IfPrimaryTerm) {
+		return errors.NewPreconditionFailedError(index, id)
+	}
+
+	// ドキュメントを削除（取得後の競合はElasticsearch側の楽観的同時実行制御で検出する）
+	refresh := repository.WithRefresh(s.resolveRefresh(options.Refresh, s.config.WriteRefresh))
+	if err := s.repo.DeleteDocument(ctx, index, id, append(opts, refresh)...); err != nil {
+		if errors.HasCode(err, errors.ErrCodePreconditionFailed) {
+			return err
+		}
 		return errors.WrapError(err, errors.ErrCodeDocumentDeleteFailed, "Failed to delete document")
 	}
+	s.audit(ctx, entity.AuditOperationDelete, index, id, nil)
 
 	return nil
 }
 
 // BulkIndexDocuments は複数のドキュメントを一度に作成する
-func (s *DocumentService) BulkIndexDocuments(ctx context.Context, docs []*entity.Document) error {
+// 件数と推定サイズの上限を超える場合は複数のリクエストに分割して送信し（BulkConcurrency 件まで並行）、
+// 元のドキュメントの順序で結果を集約する
+// opType が create の場合、既存IDのドキュメントは上書きされずアイテムごとに競合として報告される
+// opType が upsert の場合、既存IDのドキュメントにはマージされる（IDが必須で、パイプラインは指定できない）
+// パイプラインはドキュメント個別の指定、opts、インデックスのデフォルトの順に優先される
+// インデックス未指定のドキュメントにはデフォルトインデックスを適用する
+func (s *DocumentService) BulkIndexDocuments(ctx context.Context, docs []*entity.Document, opType entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkResult, error) {
+	return s.BulkIndexDocumentsWithProgress(ctx, docs, opType, nil, opts...)
+}
+
+// BulkIndexDocumentsWithProgress は BulkIndexDocuments と同様に一括登録し、
+// 分割したチャンクの送信が完了するたびに progress へ進捗を通知する（検証エラーの場合は通知しない）
+// progress は並行して送信する場合も1つずつ呼び出される
+func (s *DocumentService) BulkIndexDocumentsWithProgress(ctx context.Context, docs []*entity.Document, opType entity.BulkOpType, progress func(entity.BulkProgress), opts ...repository.DocumentOption) (*entity.BulkResult, error) {
 	if len(docs) == 0 {
-		return errors.NewAppError(errors.ErrCodeValidationFailed, "No documents provided for bulk indexing")
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "No documents provided for bulk indexing")
+	}
+
+	if !opType.IsValid() {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "mode",
+			Message: fmt.Sprintf("Unsupported bulk mode: %s", opType),
+		}})
 	}
 
 	// 全てのドキュメントを検証
+	options := repository.NewDocumentOptions(opts...)
+	var fields errors.FieldErrors
+	for i, doc := range docs {
+		fields = append(fields, s.prepareBulkDocument(doc, opType, options.Pipeline, fmt.Sprintf("documents[%d]", i))...)
+	}
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+
+	// チャンクごとにバルクインデックスを実行
+	chunks := bulkChunks(docs, s.config.BulkChunkMaxDocs, s.config.BulkChunkMaxBytes)
+	refresh := s.resolveRefresh(options.Refresh, s.config.BulkRefresh)
+	result, err := s.submitBulkChunks(ctx, chunks, opType, s.config.BulkConcurrency, refresh, progress)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to bulk index documents")
+	}
+	classifyBulkItemErrors(result, opType)
+
+	// 成功したアイテムごとに監査イベントを記録（アイテムはリクエストと同じ順序で返る）
+	for i, item := range result.Items {
+		if item.Failed() || item.Result == "noop" {
+			continue
+		}
+		var source map[string]any
+		if i < len(docs) {
+			source = docs[i].Source
+		}
+		operation := entity.AuditOperationUpdate
+		if item.Result == "created" {
+			operation = entity.AuditOperationCreate
+		}
+		s.audit(ctx, operation, item.Index, item.ID, source)
+	}
+
+	return result, nil
+}
+
+// ValidateBulkDocuments は BulkIndexDocuments と同じ検証とビジネスルールを全てのドキュメントに適用し、
+// 登録は行わずに失敗するドキュメントとその理由を返す（大量の登録の前にデータの問題を見つけるため）
+func (s *DocumentService) ValidateBulkDocuments(ctx context.Context, docs []*entity.Document, opType entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkValidation, error) {
+	if len(docs) == 0 {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "No documents provided for bulk indexing")
+	}
+
+	if !opType.IsValid() {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "mode",
+			Message: fmt.Sprintf("Unsupported bulk mode: %s", opType),
+		}})
+	}
+
+	pipeline := repository.NewDocumentOptions(opts...).Pipeline
+	result := &entity.BulkValidation{Total: len(docs), Failures: []entity.BulkValidationFailure{}}
 	for i, doc := range docs {
-		if err := s.validateDocument(doc); err != nil {
-			return errors.NewAppError(errors.ErrCodeValidationFailed, fmt.Sprintf("Document %d validation failed: %v", i, err))
+		fields := s.prepareBulkDocument(doc, opType, pipeline, fmt.Sprintf("documents[%d]", i))
+		if len(fields) == 0 {
+			result.Valid++
+			continue
 		}
 
-		// ビジネスルールを適用
-		if err := s.applyBusinessRules(doc); err != nil {
-			return errors.NewAppError(errors.ErrCodeValidationFailed, fmt.Sprintf("Document %d business rule validation failed: %v", i, err))
+		failure := entity.BulkValidationFailure{Position: i}
+		if doc != nil {
+			failure.Index = doc.Index
+			failure.ID = doc.ID
 		}
+		for _, field := range fields {
+			failure.Errors = append(failure.Errors, entity.BulkValidationError{Field: field.Field, Message: field.Message})
+		}
+		result.Failures = append(result.Failures, failure)
+		result.Invalid++
 	}
 
-	// バルクインデックスを実行
-	if err := s.repo.BulkIndex(ctx, docs); err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to bulk index documents")
+	return result, nil
+}
+
+// prepareBulkDocument はバルク登録する1件のドキュメントを検証し、デフォルトインデックスやパイプライン、
+// ビジネスルールを適用する。検証エラーはフィールド名に prefix を付けて返す
+func (s *DocumentService) prepareBulkDocument(doc *entity.Document, opType entity.BulkOpType, pipeline, prefix string) errors.FieldErrors {
+	if doc != nil && doc.Index == "" {
+		doc.Index = s.config.DefaultIndex
+	}
+	if err := s.validateDocument(doc); err != nil {
+		return prefixFieldErrors(err, prefix)
 	}
 
-	return nil
+	var fields errors.FieldErrors
+	if opType == entity.BulkOpUpsert {
+		// 部分更新にはIDが必要で、インジェストパイプラインは適用されない
+		if doc.ID == "" {
+			fields.Add(prefix+".id", "Document ID is required in upsert mode")
+		}
+		if doc.Pipeline != "" || pipeline != "" {
+			fields.Add(prefix+".pipeline", "Ingest pipelines are not supported in upsert mode")
+		}
+		doc.RetryOnConflict = s.config.UpdateRetryOnConflict
+
+		// 既存ドキュメントへのマージと新規作成でルールを分けて適用する
+		if err := s.applyUpsertRules(doc); err != nil {
+			fields = append(fields, prefixFieldErrors(err, prefix)...)
+		}
+		return fields
+	}
+	if doc.Pipeline == "" {
+		doc.Pipeline = s.resolvePipeline(doc.Index, pipeline)
+	}
+
+	// ビジネスルールを適用
+	if err := s.applyBusinessRules(doc); err != nil {
+		fields = append(fields, prefixFieldErrors(err, prefix)...)
+	}
+	return fields
 }
 
 // CreateDocumentWithID は指定されたIDでドキュメントを作成する
-func (s *DocumentService) CreateDocumentWithID(ctx context.Context, index, id string, source map[string]any) (*entity.Document, error) {
-	if index == "" {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+func (s *DocumentService) CreateDocumentWithID(ctx context.Context, index, id string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, error) {
+	index, err := s.resolveIndex(index)
+	if err != nil {
+		return nil, err
 	}
 
 	if id == "" {
@@ -175,17 +647,20 @@ func (s *DocumentService) CreateDocumentWithID(ctx context.Context, index, id st
 	}
 
 	if len(source) == 0 {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{Field: "source", Message: "Document source cannot be empty"}})
 	}
 
 	// ドキュメントが既に存在するかを確認
-	_, err := s.repo.GetDocument(ctx, index, id)
-	if err == nil {
+	if _, err := s.repo.GetDocument(ctx, index, id, opts...); err == nil {
 		return nil, errors.NewDocumentExistsError(index, id)
 	}
 
 	// ドキュメントエンティティを作成
 	doc := entity.NewDocument(index, source)
+	options := repository.NewDocumentOptions(opts...)
+	doc.Routing = options.Routing
+	doc.Pipeline = s.resolvePipeline(index, options.Pipeline)
+	doc.Refresh = s.resolveRefresh(options.Refresh, s.config.WriteRefresh)
 	doc.SetID(id)
 
 	// ビジネスルールを適用
@@ -197,10 +672,66 @@ func (s *DocumentService) CreateDocumentWithID(ctx context.Context, index, id st
 	if err := s.repo.CreateDocument(ctx, doc); err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to create document")
 	}
+	s.audit(ctx, entity.AuditOperationCreate, doc.Index, doc.ID, doc.Source)
 
 	return doc, nil
 }
 
+// audit は変更操作の監査イベントを記録する
+// 操作主体はコンテキストの認証情報から取得し、ドキュメントの内容は設定で有効な場合のみ含める
+func (s *DocumentService) audit(ctx context.Context, operation entity.AuditOperation, index, id string, source map[string]any) {
+	subject := auth.Subject(ctx)
+	if subject == "" {
+		subject = entity.AnonymousSubject
+	}
+
+	event := entity.AuditEvent{
+		Timestamp:  time.Now().UTC(),
+		Operation:  operation,
+		Index:      index,
+		DocumentID: id,
+		Subject:    subject,
+	}
+	if s.config.AuditIncludeSource {
+		event.Source = source
+	}
+
+	s.config.AuditLogger.Record(ctx, event)
+}
+
+// resolveIndex はドキュメントの登録先インデックスを返す
+// 明示的な指定がなければデフォルトインデックスを使用し、どちらもなければバリデーションエラーを返す
+func (s *DocumentService) resolveIndex(index string) (string, error) {
+	if index != "" {
+		return index, nil
+	}
+	if s.config.DefaultIndex != "" {
+		return s.config.DefaultIndex, nil
+	}
+	return "", errors.NewFieldValidationError([]errors.FieldError{{
+		Field:   "index",
+		Message: "Index is required when no default index is configured",
+	}})
+}
+
+// resolvePipeline はインデックス時に使用するパイプラインを返す
+// 明示的な指定がなければインデックスのデフォルトパイプラインを使用する
+func (s *DocumentService) resolvePipeline(index, pipeline string) string {
+	if pipeline != "" {
+		return pipeline
+	}
+	return s.config.DefaultPipelines[index]
+}
+
+// resolveRefresh は書き込み後のリフレッシュの方法を返す
+// 明示的な指定がなければ設定のデフォルトを使用する
+func (s *DocumentService) resolveRefresh(refresh, fallback entity.RefreshPolicy) entity.RefreshPolicy {
+	if refresh != "" {
+		return refresh
+	}
+	return fallback
+}
+
 // applyBusinessRules はドキュメントにビジネスルールを適用する
 func (s *DocumentService) applyBusinessRules(doc *entity.Document) error {
 	// タイムスタンプフィールドが存在しない場合は追加
@@ -228,44 +759,89 @@ func (s *DocumentService) applyBusinessRules(doc *entity.Document) error {
 	return nil
 }
 
+// applyUpsertRules は部分更新（存在すれば既存ドキュメントにマージし、なければ作成する）にビジネスルールを適用する
+// マージする部分ドキュメント（Source）には updated_at のみを設定し、created_at はドキュメントが存在しない場合に
+// 作成されるソース（Upsert）にのみ設定する。既存ドキュメントへのマージでは部分ドキュメントに必須フィールドが
+// 含まれないのが通常のため、必須フィールドは検証しない
+func (s *DocumentService) applyUpsertRules(doc *entity.Document) error {
+	now := time.Now().Format(time.RFC3339)
+	doc.SetField("updated_at", now)
+
+	// データ変換を適用
+	if err := s.applyDataTransformations(doc); err != nil {
+		return err
+	}
+
+	// 新規作成される場合のソースには作成日時も含める
+	upsert := maps.Clone(doc.Source)
+	if upsert == nil {
+		upsert = make(map[string]any)
+	}
+	if _, exists := upsert["created_at"]; !exists {
+		upsert["created_at"] = now
+	}
+	doc.Upsert = upsert
+	return nil
+}
+
 // validateDocument はドキュメントを検証する
 func (s *DocumentService) validateDocument(doc *entity.Document) error {
 	if doc == nil {
-		return errors.NewAppError(errors.ErrCodeValidationFailed, "Document cannot be nil")
+		return errors.NewFieldValidationError([]errors.FieldError{{Field: "document", Message: "Document cannot be nil"}})
 	}
 
+	var fields errors.FieldErrors
 	if doc.Index == "" {
-		return errors.NewAppError(errors.ErrCodeValidationFailed, "Document index cannot be empty")
+		fields.Add("index", "Index is required when no default index is configured")
 	}
 
 	if len(doc.Source) == 0 {
-		return errors.NewAppError(errors.ErrCodeValidationFailed, "Document source cannot be empty")
+		fields.Add("source", "Document source cannot be empty")
 	}
 
-	return nil
+	return fields.Err()
 }
 
 // validateRequiredFields はドキュメントの必須フィールドを検証する
 func (s *DocumentService) validateRequiredFields(doc *entity.Document) error {
+	var fields errors.FieldErrors
+
 	// 例: インデックスに基づいて特定のフィールドが必須かを確認
 	switch doc.Index {
 	case "users":
 		if _, exists := doc.GetField("email"); !exists {
-			return errors.NewAppError(errors.ErrCodeValidationFailed, "Email field is required for users index")
+			fields.Add("source.email", "Email field is required for users index")
 		}
 		if _, exists := doc.GetField("name"); !exists {
-			return errors.NewAppError(errors.ErrCodeValidationFailed, "Name field is required for users index")
+			fields.Add("source.name", "Name field is required for users index")
 		}
 	case "products":
 		if _, exists := doc.GetField("name"); !exists {
-			return errors.NewAppError(errors.ErrCodeValidationFailed, "Name field is required for products index")
+			fields.Add("source.name", "Name field is required for products index")
 		}
 		if _, exists := doc.GetField("price"); !exists {
-			return errors.NewAppError(errors.ErrCodeValidationFailed, "Price field is required for products index")
+			fields.Add("source.price", "Price field is required for products index")
 		}
 	}
 
-	return nil
+	return fields.Err()
+}
+
+// prefixFieldErrors はエラーのフィールドパスにプレフィックスを付けて返す
+func prefixFieldErrors(err error, prefix string) errors.FieldErrors {
+	appErr := errors.GetAppError(err)
+	if appErr == nil || len(appErr.Fields) == 0 {
+		return errors.FieldErrors{{Field: prefix, Message: err.Error()}}
+	}
+
+	fields := make(errors.FieldErrors, len(appErr.Fields))
+	for i, field := range appErr.Fields {
+		fields[i] = errors.FieldError{
+			Field:   prefix + "." + field.Field,
+			Message: field.Message,
+		}
+	}
+	return fields
 }
 
 // applyDataTransformations はドキュメントにデータ変換を適用する
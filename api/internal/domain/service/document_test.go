@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// upsertRecorder は UpsertDocument に渡されたドキュメントを記録するリポジトリ
+// それ以外のメソッドは呼ばれない前提のため埋め込んだインターフェースは nil のまま
+type upsertRecorder struct {
+	repository.ElasticsearchRepository
+	doc *entity.Document
+}
+
+func (r *upsertRecorder) UpsertDocument(_ context.Context, doc *entity.Document) error {
+	r.doc = doc
+	return nil
+}
+
+func TestUpsertDocumentSplitsMergeAndCreate(t *testing.T) {
+	repo := &upsertRecorder{}
+	s := NewDocumentService(repo)
+
+	// users インデックスの必須フィールド（email）を含まない部分更新も受け付ける
+	if _, err := s.UpsertDocument(context.Background(), "users", "1", map[string]any{"name": "Alice"}); err != nil {
+		t.Fatalf("UpsertDocument() error = %v", err)
+	}
+
+	doc := repo.doc
+	if doc == nil {
+		t.Fatal("repository UpsertDocument was not called")
+	}
+	if _, ok := doc.Source["updated_at"]; !ok {
+		t.Error("partial document has no updated_at")
+	}
+	if _, ok := doc.Source["created_at"]; ok {
+		t.Error("partial document has created_at, want it only in the upsert body")
+	}
+	if _, ok := doc.Upsert["created_at"]; !ok {
+		t.Error("upsert body has no created_at")
+	}
+	if doc.Upsert["name"] != "Alice" {
+		t.Errorf("upsert name = %v, want Alice", doc.Upsert["name"])
+	}
+}
+
+func TestPrepareBulkDocumentUpsert(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        map[string]any
+		wantCreatedAt string // 期待する upsert 側の created_at（空の場合は現在時刻が設定されていること）
+	}{
+		{
+			name:   "partial document without required fields",
+			source: map[string]any{"name": "Alice"},
+		},
+		{
+			name:          "created_at supplied by the client",
+			source:        map[string]any{"name": "Alice", "created_at": "2020-01-01T00:00:00Z"},
+			wantCreatedAt: "2020-01-01T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDocumentService(nil)
+			doc := entity.NewDocument("users", tt.source)
+			doc.ID = "1"
+
+			if fields := s.prepareBulkDocument(doc, entity.BulkOpUpsert, "", "documents[0]"); len(fields) != 0 {
+				t.Fatalf("prepareBulkDocument() fields = %v, want none", fields)
+			}
+
+			if _, ok := doc.Source["updated_at"]; !ok {
+				t.Error("partial document has no updated_at")
+			}
+			if got, ok := doc.Source["created_at"]; ok && tt.wantCreatedAt == "" {
+				t.Errorf("partial document has created_at = %v, want it only in the upsert body", got)
+			}
+			if doc.Upsert == nil {
+				t.Fatal("Upsert = nil, want the document to create when missing")
+			}
+			createdAt, ok := doc.Upsert["created_at"]
+			if !ok {
+				t.Fatal("upsert body has no created_at")
+			}
+			if tt.wantCreatedAt != "" && createdAt != tt.wantCreatedAt {
+				t.Errorf("upsert created_at = %v, want %v", createdAt, tt.wantCreatedAt)
+			}
+		})
+	}
+}
+
+func TestPrepareBulkDocumentIndexValidatesRequiredFields(t *testing.T) {
+	s := NewDocumentService(nil)
+	doc := entity.NewDocument("users", map[string]any{"name": "Alice"})
+
+	fields := s.prepareBulkDocument(doc, entity.BulkOpIndex, "", "documents[0]")
+	if len(fields) != 1 || fields[0].Field != "documents[0].source.email" {
+		t.Errorf("prepareBulkDocument() fields = %v, want documents[0].source.email", fields)
+	}
+	if doc.Upsert != nil {
+		t.Errorf("Upsert = %v, want nil for index operations", doc.Upsert)
+	}
+}
+
+func TestValidateBulkDocuments(t *testing.T) {
+	docs := []*entity.Document{
+		entity.NewDocument("articles", map[string]any{"title": "Go"}),
+		entity.NewDocument("users", map[string]any{"name": "Alice"}),
+		nil,
+		entity.NewDocument("products", map[string]any{}),
+		entity.NewDocument("products", map[string]any{"name": "Laptop", "price": 1000}),
+	}
+
+	// リポジトリは nil のため、Elasticsearch への書き込みが行われれば panic する
+	s := NewDocumentService(nil)
+	result, err := s.ValidateBulkDocuments(context.Background(), docs, entity.BulkOpIndex)
+	if err != nil {
+		t.Fatalf("ValidateBulkDocuments() error = %v", err)
+	}
+
+	if result.Total != 5 || result.Valid != 2 || result.Invalid != 3 {
+		t.Errorf("total/valid/invalid = %d/%d/%d, want 5/2/3", result.Total, result.Valid, result.Invalid)
+	}
+
+	want := []struct {
+		position int
+		field    string
+	}{
+		{position: 1, field: "documents[1].source.email"},
+		{position: 2, field: "documents[2].document"},
+		{position: 3, field: "documents[3].source"},
+	}
+	if len(result.Failures) != len(want) {
+		t.Fatalf("failures = %+v, want %d", result.Failures, len(want))
+	}
+	for i, w := range want {
+		failure := result.Failures[i]
+		if failure.Position != w.position {
+			t.Errorf("failures[%d].Position = %d, want %d", i, failure.Position, w.position)
+		}
+		if len(failure.Errors) == 0 || failure.Errors[0].Field != w.field {
+			t.Errorf("failures[%d].Errors = %+v, want %s", i, failure.Errors, w.field)
+		}
+	}
+}
+
+func TestValidateBulkDocumentsUpsertRequiresID(t *testing.T) {
+	s := NewDocumentService(nil)
+	docs := []*entity.Document{entity.NewDocument("articles", map[string]any{"title": "Go"})}
+
+	result, err := s.ValidateBulkDocuments(context.Background(), docs, entity.BulkOpUpsert)
+	if err != nil {
+		t.Fatalf("ValidateBulkDocuments() error = %v", err)
+	}
+	if result.Invalid != 1 || result.Failures[0].Errors[0].Field != "documents[0].id" {
+		t.Errorf("failures = %+v, want documents[0].id", result.Failures)
+	}
+}
+
+// createRecorder は CreateDocument に渡されたドキュメントを記録するリポジトリ
+type createRecorder struct {
+	repository.ElasticsearchRepository
+	doc *entity.Document
+}
+
+func (r *createRecorder) CreateDocument(_ context.Context, doc *entity.Document) error {
+	r.doc = doc
+	return nil
+}
+
+func TestCreateRawDocumentPreservesSource(t *testing.T) {
+	repo := &createRecorder{}
+	s := NewDocumentService(repo)
+
+	raw := `{"id":12345678901234567890,"title":"Go","price":1.10}`
+	if _, err := s.CreateRawDocument(context.Background(), "articles", []byte(raw)); err != nil {
+		t.Fatalf("CreateRawDocument() error = %v", err)
+	}
+
+	doc := repo.doc
+	if doc == nil || doc.RawSource == nil {
+		t.Fatal("repository CreateDocument was not called with a raw source")
+	}
+	body := string(doc.RawSource.Bytes())
+	if !strings.HasPrefix(body, `{"id":12345678901234567890,"title":"Go","price":1.10,`) {
+		t.Errorf("raw source = %s, want the original fields unchanged and first", body)
+	}
+	for _, field := range []string{"created_at", "updated_at"} {
+		if _, ok := doc.GetField(field); !ok {
+			t.Errorf("raw source has no %s", field)
+		}
+	}
+}
+
+func TestCreateDocumentRefresh(t *testing.T) {
+	tests := []struct {
+		name   string
+		config entity.RefreshPolicy
+		opts   []repository.DocumentOption
+		want   entity.RefreshPolicy
+	}{
+		{name: "default refreshes immediately", want: entity.RefreshTrue},
+		{name: "configured default", config: entity.RefreshFalse, want: entity.RefreshFalse},
+		{name: "request overrides config", config: entity.RefreshFalse, opts: []repository.DocumentOption{repository.WithRefresh(entity.RefreshWaitFor)}, want: entity.RefreshWaitFor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &createRecorder{}
+			s := NewDocumentServiceWithConfig(repo, &DocumentConfig{WriteRefresh: tt.config})
+
+			if _, err := s.CreateDocument(context.Background(), "articles", map[string]any{"title": "Go"}, tt.opts...); err != nil {
+				t.Fatalf("CreateDocument() error = %v", err)
+			}
+			if repo.doc.Refresh != tt.want {
+				t.Errorf("refresh = %q, want %q", repo.doc.Refresh, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateRawDocumentRejectsInvalidSource(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "array", raw: `[{"title":"Go"}]`},
+		{name: "empty object", raw: `{}`},
+		{name: "trailing data", raw: `{"title":"Go"}{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewDocumentService(nil)
+			_, err := s.CreateRawDocument(context.Background(), "articles", []byte(tt.raw))
+			if !errors.HasCode(err, errors.ErrCodeValidationFailed) {
+				t.Errorf("CreateRawDocument() error = %v, want VALIDATION_FAILED", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// FieldValidationMode はフィルターやソートのフィールド名をマッピングに対して検証する方法を表す
+type FieldValidationMode string
+
+const (
+	// FieldValidationOff は検証しない
+	FieldValidationOff FieldValidationMode = "off"
+	// FieldValidationLenient はマッピングにないフィールドを警告としてログに記録し、検索は続行する
+	FieldValidationLenient FieldValidationMode = "lenient"
+	// FieldValidationStrict はマッピングにないフィールドを含む検索をバリデーションエラー（400）にする
+	FieldValidationStrict FieldValidationMode = "strict"
+)
+
+// IsValid はサポートされている検証方法かどうかを返す
+func (m FieldValidationMode) IsValid() bool {
+	switch m {
+	case FieldValidationOff, FieldValidationLenient, FieldValidationStrict:
+		return true
+	}
+	return false
+}
+
+// validateQueryFields はフィルターとソートのフィールド名が対象インデックスのマッピングに存在するかを確認する
+// term クエリは存在しないフィールドに対しても何も一致しないだけでエラーにならないため、タイプミスを検出する
+// 単一インデックスの検索のみを対象とし、_id などのメタデータフィールドやマッピングを取得できない場合は確認しない
+func (s *SearchService) validateQueryFields(ctx context.Context, query *entity.SearchQuery) error {
+	if s.config.FieldValidation == FieldValidationOff || !isConcreteIndex(query.Index) {
+		return nil
+	}
+
+	filterFields := make([]string, 0, len(query.Filters))
+	for field := range query.Filters {
+		filterFields = append(filterFields, field)
+	}
+	sort.Strings(filterFields)
+
+	var fields errors.FieldErrors
+	for _, field := range filterFields {
+		if !s.fieldExists(ctx, query.Index, field) {
+			fields.Add("filters."+field, fmt.Sprintf("Unknown field %s in index %s", field, query.Index))
+		}
+	}
+	for i, sortField := range query.Sort {
+		if !s.fieldExists(ctx, query.Index, sortField.Field) {
+			fields.Add(fmt.Sprintf("sort[%d].field", i), fmt.Sprintf("Unknown field %s in index %s", sortField.Field, query.Index))
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if s.config.FieldValidation == FieldValidationStrict {
+		return fields.Err()
+	}
+	for _, field := range fields {
+		s.config.Logger.Printf("WARN: %s: %s (the search may match nothing)", field.Field, field.Message)
+	}
+	return nil
+}
+
+// fieldExists はフィールドがインデックスのマッピングに存在するかを返す
+// メタデータフィールド（"_" で始まるもの）とマッピングを取得できない場合は存在するものとして扱う
+// 存在を確認したフィールドはテナントごとにキャッシュする
+func (s *SearchService) fieldExists(ctx context.Context, index, field string) bool {
+	if field == "" || strings.HasPrefix(field, "_") {
+		return true
+	}
+
+	key := auth.Tenant(ctx) + "/" + index + "/" + field
+	if s.knownFields.contains(key) {
+		return true
+	}
+
+	fieldType, err := s.repo.GetFieldType(ctx, index, field)
+	if err != nil {
+		return true
+	}
+	if fieldType == "" {
+		return false
+	}
+	s.knownFields.set(key)
+	return true
+}
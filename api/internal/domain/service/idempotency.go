@@ -0,0 +1,193 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// MaxIdempotencyKeyLength は冪等キーの最大長
+const MaxIdempotencyKeyLength = 255
+
+// idempotencySweepInterval は期限切れのエントリを削除する間隔（TTL がこれより短い場合は TTL ごと）
+const idempotencySweepInterval = time.Minute
+
+// idempotencyStore は冪等キーごとの作成結果を一定期間メモリに保持する
+// エントリ数が上限に達した場合は、登録の古いものから追い出す
+type idempotencyStore struct {
+	ttl        time.Duration
+	maxEntries int // 0の場合は上限なし
+	mu         sync.Mutex
+	entries    map[string]*idempotencyEntry
+	order      *list.List // 登録順のキー
+	lastSweep  time.Time
+}
+
+// idempotencyEntry は冪等キーに対応するリクエストの内容と結果を表す
+// done は最初のリクエストの完了時に閉じられる（処理中の再送はそれまで待つ）
+type idempotencyEntry struct {
+	fingerprint string
+	done        chan struct{}
+	doc         *entity.Document // 作成したドキュメント（失敗した場合は nil）
+	expires     time.Time        // 完了後に設定する有効期限（処理中はゼロ値）
+	element     *list.Element    // 登録順のリスト内の位置
+}
+
+// newIdempotencyStore は新しい idempotencyStore を作成する
+func newIdempotencyStore(ttl time.Duration, maxEntries int) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*idempotencyEntry),
+		order:      list.New(),
+	}
+}
+
+// acquire はキーのエントリを返す。新たにエントリを登録した場合（呼び出し元が作成を行う場合）は true も返す
+func (s *idempotencyStore) acquire(key, fingerprint string) (*idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok {
+		if entry.expires.IsZero() || now.Before(entry.expires) {
+			return entry, false
+		}
+		s.remove(key, entry)
+	}
+	s.evict()
+
+	entry := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	entry.element = s.order.PushBack(key)
+	s.entries[key] = entry
+	return entry, true
+}
+
+// complete は作成結果を記録し、待機中の再送に通知する
+// 作成に失敗した場合はエントリを削除し、同じキーでの再試行で改めて作成できるようにする
+func (s *idempotencyStore) complete(key string, entry *idempotencyEntry, doc *entity.Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.doc = doc
+	entry.expires = time.Now().Add(s.ttl)
+	if doc == nil && s.entries[key] == entry {
+		s.remove(key, entry)
+	}
+	close(entry.done)
+}
+
+// remove はエントリを削除する
+func (s *idempotencyStore) remove(key string, entry *idempotencyEntry) {
+	delete(s.entries, key)
+	s.order.Remove(entry.element)
+}
+
+// evict はエントリ数が上限に達している場合、新しいエントリの分を空けるまで古いものから追い出す
+// 完了済みのエントリを優先し、処理中のエントリは全て処理中の場合のみ追い出す（待機中の再送は結果を受け取れる）
+func (s *idempotencyStore) evict() {
+	for s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		victim := s.order.Front()
+		for e := victim; e != nil; e = e.Next() {
+			if !s.entries[e.Value.(string)].expires.IsZero() {
+				victim = e
+				break
+			}
+		}
+		key := victim.Value.(string)
+		s.remove(key, s.entries[key])
+	}
+}
+
+// sweep は期限切れのエントリを削除する（呼び出し頻度に関わらず idempotencySweepInterval ごとに1回まで）
+func (s *idempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < min(s.ttl, idempotencySweepInterval) {
+		return
+	}
+	s.lastSweep = now
+
+	for e := s.order.Front(); e != nil; {
+		next := e.Next()
+		key := e.Value.(string)
+		if entry := s.entries[key]; !entry.expires.IsZero() && now.After(entry.expires) {
+			s.remove(key, entry)
+		}
+		e = next
+	}
+}
+
+// idempotencyFingerprint はリクエストの内容（インデックス、ソース、ルーティング、パイプライン）のハッシュを返す
+// ソースのキーは JSON 変換時に並べ替えられるため、キーの順序が異なるだけの再送は同じ内容として扱う
+func idempotencyFingerprint(index string, source map[string]any, options *repository.DocumentOptions) (string, error) {
+	data, err := json.Marshal(map[string]any{
+		"index":    index,
+		"source":   source,
+		"routing":  options.Routing,
+		"pipeline": options.Pipeline,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CreateDocumentIdempotent は冪等キー付きでドキュメントを作成する
+// 同じキーで同じ内容のリクエストが有効期間内に再送された場合は新たに作成せず、最初の結果を返す（replayed が true）
+// 同じキーで内容が異なるリクエストは競合エラー（409）になる。キーはテナントごとに区別する
+func (s *DocumentService) CreateDocumentIdempotent(ctx context.Context, key, index string, source map[string]any, opts ...repository.DocumentOption) (*entity.Document, bool, error) {
+	if key == "" || s.idempotency == nil {
+		doc, err := s.CreateDocument(ctx, index, source, opts...)
+		return doc, false, err
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		return nil, false, errors.NewAppError(errors.ErrCodeValidationFailed, "Idempotency key is too long")
+	}
+
+	resolved, err := s.resolveIndex(index)
+	if err != nil {
+		return nil, false, err
+	}
+	fingerprint, err := idempotencyFingerprint(resolved, source, repository.NewDocumentOptions(opts...))
+	if err != nil {
+		return nil, false, errors.WrapError(err, errors.ErrCodeInvalidDocument, "Failed to marshal document")
+	}
+	scopedKey := auth.Tenant(ctx) + "/" + key
+
+	for {
+		entry, owner := s.idempotency.acquire(scopedKey, fingerprint)
+		if owner {
+			doc, err := s.CreateDocument(ctx, index, source, opts...)
+			s.idempotency.complete(scopedKey, entry, doc)
+			return doc, false, err
+		}
+
+		if entry.fingerprint != fingerprint {
+			return nil, false, errors.NewAppError(errors.ErrCodeIdempotencyConflict, "Idempotency key was already used with a different request")
+		}
+
+		// 最初のリクエストが処理中の場合は完了を待つ
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, false, errors.WrapError(ctx.Err(), errors.ErrCodeDocumentCreateFailed, "Failed to wait for the original request")
+		}
+
+		if entry.doc != nil {
+			doc := *entry.doc
+			return &doc, true, nil
+		}
+		// 最初のリクエストが失敗した場合は改めて作成する
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+func TestIdempotencyStoreEvictsOldestEntries(t *testing.T) {
+	store := newIdempotencyStore(time.Hour, 2)
+
+	for i := range 3 {
+		key := fmt.Sprintf("key-%d", i)
+		entry, owner := store.acquire(key, "fp")
+		if !owner {
+			t.Fatalf("acquire(%s) owner = false, want true", key)
+		}
+		store.complete(key, entry, &entity.Document{ID: key})
+	}
+
+	if len(store.entries) != 2 || store.order.Len() != 2 {
+		t.Fatalf("entries = %d, order = %d, want 2", len(store.entries), store.order.Len())
+	}
+	if _, ok := store.entries["key-0"]; ok {
+		t.Error("oldest key was not evicted")
+	}
+	if _, owner := store.acquire("key-2", "fp"); owner {
+		t.Error("acquire(key-2) owner = true, want the stored result")
+	}
+}
+
+func TestIdempotencyStoreEvictsCompletedBeforeInFlight(t *testing.T) {
+	store := newIdempotencyStore(time.Hour, 2)
+
+	// key-0 は処理中のまま、key-1 は完了済み
+	store.acquire("key-0", "fp")
+	entry, _ := store.acquire("key-1", "fp")
+	store.complete("key-1", entry, &entity.Document{ID: "key-1"})
+
+	store.acquire("key-2", "fp")
+
+	if _, ok := store.entries["key-0"]; !ok {
+		t.Error("in-flight key was evicted before the completed one")
+	}
+	if _, ok := store.entries["key-1"]; ok {
+		t.Error("completed key was not evicted")
+	}
+}
+
+func TestIdempotencyStoreSweep(t *testing.T) {
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		elapsed     time.Duration
+		wantEntries int
+	}{
+		{name: "before the sweep interval", ttl: time.Hour, elapsed: 30 * time.Second, wantEntries: 1},
+		{name: "expired after the sweep interval", ttl: time.Second, elapsed: 2 * time.Second, wantEntries: 0},
+		{name: "long TTL swept at the fixed interval", ttl: 24 * time.Hour, elapsed: 2 * idempotencySweepInterval, wantEntries: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newIdempotencyStore(tt.ttl, 0)
+			start := time.Now()
+			store.lastSweep = start
+
+			entry, _ := store.acquire("key", "fp")
+			store.complete("key", entry, &entity.Document{ID: "key"})
+			entry.expires = start.Add(tt.ttl)
+
+			store.sweep(start.Add(tt.elapsed))
+			if len(store.entries) != tt.wantEntries || store.order.Len() != tt.wantEntries {
+				t.Errorf("entries = %d, order = %d, want %d", len(store.entries), store.order.Len(), tt.wantEntries)
+			}
+			if tt.elapsed >= min(tt.ttl, idempotencySweepInterval) && !store.lastSweep.Equal(start.Add(tt.elapsed)) {
+				t.Error("sweep did not run after the interval")
+			}
+		})
+	}
+}
+
+func TestIdempotencyStoreRemovesFailedEntries(t *testing.T) {
+	store := newIdempotencyStore(time.Hour, 10)
+
+	entry, _ := store.acquire("key", "fp")
+	store.complete("key", entry, nil)
+
+	if len(store.entries) != 0 || store.order.Len() != 0 {
+		t.Errorf("entries = %d, order = %d, want the failed key removed", len(store.entries), store.order.Len())
+	}
+}
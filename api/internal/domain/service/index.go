@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// IndexManager はインデックスサービスのインターフェース
+type IndexManager interface {
+	CreateIndex(ctx context.Context, index string, mapping, settings map[string]any) error
+	GetIndexStats(ctx context.Context, index string) (map[string]any, error)
+	ExportDocuments(ctx context.Context, index string, query *entity.SearchQuery, fn func(hits []entity.Hit) error) error
+	OpenIndex(ctx context.Context, index string) error
+	CloseIndex(ctx context.Context, index string) error
+	RefreshIndex(ctx context.Context, index string) error
+	ForceMerge(ctx context.Context, index string, maxSegments int) (string, error)
+	DeleteIndex(ctx context.Context, index string, dryRun bool) (map[string]any, error)
+}
+
+// exportBatchSize はエクスポート時に1回のスクロールで取得するドキュメント数
+const exportBatchSize = 1000
+
+// IndexService はインデックス操作のビジネスロジックを提供する
+type IndexService struct {
+	repo repository.ElasticsearchRepository
+}
+
+// NewIndexService は新しいIndexServiceを作成する
+func NewIndexService(repo repository.ElasticsearchRepository) *IndexService {
+	return &IndexService{
+		repo: repo,
+	}
+}
+
+// GetIndexStats はインデックスの統計情報を取得する
+func (s *IndexService) GetIndexStats(ctx context.Context, index string) (map[string]any, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	stats, err := s.repo.IndexStats(ctx, index)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return nil, err
+		}
+		return nil, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to get index stats")
+	}
+
+	return stats, nil
+}
+
+// OpenIndex はクローズされたインデックスをオープンする
+func (s *IndexService) OpenIndex(ctx context.Context, index string) error {
+	if index == "" {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if err := s.repo.OpenIndex(ctx, index); err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return err
+		}
+		return errors.WrapError(err, errors.ErrCodeInternalError, "Failed to open index")
+	}
+
+	return nil
+}
+
+// CloseIndex はメンテナンスのためにインデックスをクローズする
+func (s *IndexService) CloseIndex(ctx context.Context, index string) error {
+	if index == "" {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if err := s.repo.CloseIndex(ctx, index); err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return err
+		}
+		return errors.WrapError(err, errors.ErrCodeInternalError, "Failed to close index")
+	}
+
+	return nil
+}
+
+// RefreshIndex はインデックスをリフレッシュし、書き込み済みのドキュメントを検索可能にする
+func (s *IndexService) RefreshIndex(ctx context.Context, index string) error {
+	if index == "" {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if err := s.repo.Refresh(ctx, index); err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return err
+		}
+		return errors.WrapError(err, errors.ErrCodeInternalError, "Failed to refresh index")
+	}
+
+	return nil
+}
+
+// ForceMerge はインデックスのセグメント数を削減するマージをバックグラウンドで開始する
+// 負荷の高い操作のため、大量登録の後など書き込みのない時間帯に実行する
+func (s *IndexService) ForceMerge(ctx context.Context, index string, maxSegments int) (string, error) {
+	if index == "" {
+		return "", errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if maxSegments < 0 {
+		return "", errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "max_num_segments",
+			Message: "Max number of segments must be non-negative",
+		}})
+	}
+
+	taskID, err := s.repo.ForceMerge(ctx, index, maxSegments)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return "", err
+		}
+		return "", errors.WrapError(err, errors.ErrCodeInternalError, "Failed to force merge index")
+	}
+
+	return taskID, nil
+}
+
+// CreateIndex はマッピングと設定（シャード数、レプリカ数、アナライザーなど）を指定してインデックスを作成する
+// 誤って複数のインデックスを指定しないよう、ワイルドカードやカンマ区切り、_all は受け付けない
+func (s *IndexService) CreateIndex(ctx context.Context, index string, mapping, settings map[string]any) error {
+	if index == "" {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+	if index == "_all" || strings.ContainsAny(index, "*,") {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index must be a single concrete index name")
+	}
+
+	if err := validateIndexSettings(settings); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateIndex(ctx, index, mapping, settings); err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexExists) || errors.HasCode(err, errors.ErrCodeInvalidMapping) {
+			return err
+		}
+		return errors.WrapError(err, errors.ErrCodeIndexCreateFailed, "Failed to create index")
+	}
+
+	return nil
+}
+
+// validateIndexSettings はシャード数が1以上、レプリカ数が0以上の整数であることを確認する
+// 設定は {"number_of_shards": 3}、{"index": {"number_of_shards": 3}}、{"index.number_of_shards": 3} のいずれの形式も受け付ける
+func validateIndexSettings(settings map[string]any) error {
+	var fields errors.FieldErrors
+	for _, setting := range []struct {
+		name string
+		min  int64
+	}{
+		{name: "number_of_shards", min: 1},
+		{name: "number_of_replicas", min: 0},
+	} {
+		path, value, ok := lookupIndexSetting(settings, setting.name)
+		if !ok {
+			continue
+		}
+		if n, isInt := settingInt(value); !isInt || n < setting.min {
+			fields.Add("settings."+path, fmt.Sprintf("%s must be an integer greater than or equal to %d", setting.name, setting.min))
+		}
+	}
+	return fields.Err()
+}
+
+// lookupIndexSetting は設定の値と、見つかった位置（フィールド名）を返す
+func lookupIndexSetting(settings map[string]any, name string) (string, any, bool) {
+	if value, ok := settings[name]; ok {
+		return name, value, true
+	}
+	if value, ok := settings["index."+name]; ok {
+		return "index." + name, value, true
+	}
+	if nested, ok := settings["index"].(map[string]any); ok {
+		if value, ok := nested[name]; ok {
+			return "index." + name, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// settingInt は JSON の数値または数字の文字列（Elasticsearch はどちらも受け付ける）を整数として返す
+func settingInt(value any) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case float64:
+		return int64(v), v == math.Trunc(v)
+	case int:
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// DeleteIndex はインデックスを削除し、削除前の統計情報（失われるドキュメント数とサイズ）を返す
+// dryRun が true の場合は削除せず、削除される内容の統計情報のみを返す
+// 誤って複数のインデックスを削除しないよう、ワイルドカードやカンマ区切り、_all は受け付けない
+func (s *IndexService) DeleteIndex(ctx context.Context, index string, dryRun bool) (map[string]any, error) {
+	if index == "" {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+	if index == "_all" || strings.ContainsAny(index, "*,") {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Index must be a single concrete index name")
+	}
+
+	stats, err := s.GetIndexStats(ctx, index)
+	if err != nil || dryRun {
+		return stats, err
+	}
+
+	if err := s.repo.DeleteIndex(ctx, index); err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return nil, err
+		}
+		return nil, errors.WrapError(err, errors.ErrCodeIndexDeleteFailed, "Failed to delete index")
+	}
+
+	return stats, nil
+}
+
+// ExportDocuments はインデックスの全ドキュメント（query 指定時は一致するもの）をバッチ単位で fn に渡す
+// query は SearchService.PrepareExportQuery で構築したもので、nil の場合は全ドキュメントを対象とする
+// fn がエラーを返すかコンテキストがキャンセルされた時点で中断する
+func (s *IndexService) ExportDocuments(ctx context.Context, index string, query *entity.SearchQuery, fn func(hits []entity.Hit) error) error {
+	if index == "" {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Index cannot be empty")
+	}
+
+	if err := s.repo.ScrollDocuments(ctx, index, query, exportBatchSize, fn); err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) || errors.HasCode(err, errors.ErrCodeInvalidQuery) {
+			return err
+		}
+		return errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to export documents")
+	}
+
+	return nil
+}
+
+// インターフェースの実装確認
+var _ IndexManager = (*IndexService)(nil)
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// isConcreteIndex は単一のインデックス（またはエイリアス）の指定かどうかを返す
+// 複数インデックス、ワイルドカード、_all などは存在確認の対象外とする
+// 日付計算式は解決されるインデックスが日付とともに変わり、結果をキャッシュできないため対象外とする
+func isConcreteIndex(index string) bool {
+	return index != "" && !strings.HasPrefix(index, "_") && !strings.ContainsAny(index, ",*") && !entity.IsDateMathIndex(index)
+}
+
+// checkIndexExists は単一インデックスの検索で対象が存在しない場合に IndexNotFound（404）を返す
+// 確認に失敗した場合は検索を続行し、Elasticsearch のエラーに任せる
+// キャッシュはテナントごとに分ける（マルチテナンシー有効時は同じインデックス名でも実体が異なるため）
+func (s *SearchService) checkIndexExists(ctx context.Context, query *entity.SearchQuery) error {
+	if !s.config.CheckIndexExists || !isConcreteIndex(query.Index) {
+		return nil
+	}
+
+	key := auth.Tenant(ctx) + "/" + query.Index
+	if s.indexExists.contains(key) {
+		return nil
+	}
+
+	exists, err := s.repo.IndexExists(ctx, query.Index)
+	if err != nil {
+		return nil
+	}
+	if !exists {
+		return errors.NewIndexNotFoundError(query.Index)
+	}
+	s.indexExists.set(key)
+	return nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+func TestValidateIndexSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  map[string]any
+		wantField string // 空の場合はエラーにならないこと
+	}{
+		{name: "no settings"},
+		{name: "flat", settings: map[string]any{"number_of_shards": json.Number("3"), "number_of_replicas": json.Number("1")}},
+		{name: "nested", settings: map[string]any{"index": map[string]any{"number_of_shards": 1, "number_of_replicas": 0}}},
+		{name: "dotted string values", settings: map[string]any{"index.number_of_shards": "2", "index.number_of_replicas": "0"}},
+		{name: "analysis only", settings: map[string]any{"analysis": map[string]any{"analyzer": map[string]any{}}}},
+		{name: "zero shards", settings: map[string]any{"number_of_shards": json.Number("0")}, wantField: "settings.number_of_shards"},
+		{name: "negative replicas", settings: map[string]any{"index": map[string]any{"number_of_replicas": -1}}, wantField: "settings.index.number_of_replicas"},
+		{name: "fractional shards", settings: map[string]any{"number_of_shards": 1.5}, wantField: "settings.number_of_shards"},
+		{name: "non-numeric replicas", settings: map[string]any{"index.number_of_replicas": "two"}, wantField: "settings.index.number_of_replicas"},
+		{name: "boolean shards", settings: map[string]any{"number_of_shards": true}, wantField: "settings.number_of_shards"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIndexSettings(tt.settings)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("validateIndexSettings() error = %v, want nil", err)
+				}
+				return
+			}
+
+			appErr := errors.GetAppError(err)
+			if appErr == nil || len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.wantField {
+				t.Errorf("validateIndexSettings() error = %v, want a field error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// presenceCache は存在を確認できたもの（インデックスやマッピング上のフィールド）を一定期間キャッシュする
+// 存在しない結果はキャッシュしないため、作成直後のインデックスやフィールドもすぐに利用できる
+type presenceCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+// newPresenceCache は新しい presenceCache を作成する
+func newPresenceCache(ttl time.Duration) *presenceCache {
+	return &presenceCache{
+		ttl:     ttl,
+		expires: make(map[string]time.Time),
+	}
+}
+
+// contains は有効期限内に存在を確認済みかどうかを返す
+func (c *presenceCache) contains(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.expires[key]
+	return ok && time.Now().Before(expires)
+}
+
+// set は存在を確認したことを記録する（TTLが0の場合はキャッシュしない）
+func (c *presenceCache) set(key string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires[key] = time.Now().Add(c.ttl)
+}
+
+// clear は match に一致するキーを削除し、削除した件数を返す（match が nil の場合はすべて削除する）
+func (c *presenceCache) clear(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evicted := 0
+	for key := range c.expires {
+		if match == nil || match(key) {
+			delete(c.expires, key)
+			evicted++
+		}
+	}
+	return evicted
+}
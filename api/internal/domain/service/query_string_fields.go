@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// existsFieldRef は値にフィールド名を取る query_string の特殊なフィールド
+const existsFieldRef = "_exists_"
+
+// validateSearchableFields は query_string / simple_query_string モードの検索が参照するフィールドを検証する
+// リクエストの fields と、query_string の検索語に書かれた "field:" の指定（_exists_:field を含む）は、
+// 検索対象として設定されたフィールド（QueryFields）に一致し、機密フィールドでないものに限る
+// 検索語でフィールドを指定できると、レスポンスから除いている機密フィールドの値を一致の有無で推測できるため
+func (s *SearchService) validateSearchableFields(query *entity.SearchQuery) error {
+	if query.Mode != entity.SearchModeQueryString && query.Mode != entity.SearchModeSimpleQueryString {
+		return nil
+	}
+
+	var fields errors.FieldErrors
+	for i, field := range query.Fields {
+		if !s.isSearchableField(field) {
+			fields.Add(fmt.Sprintf("fields[%d]", i), fmt.Sprintf("Field %s is not searchable", field))
+		}
+	}
+	if query.Mode == entity.SearchModeQueryString {
+		for _, field := range queryStringFieldRefs(query.Query) {
+			if !s.isSearchableField(field) {
+				fields.Add("query", fmt.Sprintf("Field %s is not searchable", field))
+			}
+		}
+	}
+	return fields.Err()
+}
+
+// isSearchableField はフィールド（ワイルドカードとブースト指定を含む）が検索対象として許可されているかを返す
+// 設定と同じ指定（デフォルトの "*" など）はそのまま許可し、それ以外のワイルドカードは機密フィールドに一致し得るものを拒否する
+func (s *SearchService) isSearchableField(field string) bool {
+	field, _, _ = strings.Cut(field, "^")
+	if field == "" || isSensitiveField(field) {
+		return false
+	}
+
+	allowedFields := make([]string, len(s.config.QueryFields))
+	for i, allowed := range s.config.QueryFields {
+		allowedFields[i], _, _ = strings.Cut(allowed, "^")
+		if field == allowedFields[i] {
+			return true
+		}
+	}
+	for _, sensitive := range SensitiveFields() {
+		if matchFieldPattern(field, sensitive) {
+			return false
+		}
+	}
+	for _, allowed := range allowedFields {
+		if matchFieldPattern(allowed, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFieldPattern は Elasticsearch と同じく * と ? をワイルドカードとしてフィールド名を照合する
+func matchFieldPattern(pattern, field string) bool {
+	if !strings.ContainsAny(pattern, "*?") {
+		return false
+	}
+	matched, err := path.Match(pattern, field)
+	return err == nil && matched
+}
+
+// queryStringFieldRefs は query_string の検索語から "field:" で指定されたフィールド名を抽出する
+// 引用符の中とバックスラッシュでエスケープされたコロンは対象外で、_exists_:field はその値のフィールドを返す
+func queryStringFieldRefs(query string) []string {
+	var refs []string
+	runes := []rune(query)
+	inQuote := false
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\':
+			i++ // エスケープされた次の文字を読み飛ばす
+		case r == '"':
+			inQuote = !inQuote
+		case r == ':' && !inQuote:
+			start := i
+			for start > 0 && isFieldNameRune(runes[start-1]) {
+				start--
+			}
+			// 単語の途中のコロン（"12:30" の後半など）はフィールド指定ではない
+			if start == i || (start > 0 && !isFieldBoundaryRune(runes[start-1])) {
+				continue
+			}
+			field := string(runes[start:i])
+			if field == existsFieldRef {
+				end := i + 1
+				for end < len(runes) && isFieldNameRune(runes[end]) {
+					end++
+				}
+				field = string(runes[i+1 : end])
+			}
+			if field != "" {
+				refs = append(refs, field)
+			}
+		}
+	}
+	return refs
+}
+
+// isFieldNameRune はフィールド名（ワイルドカードを含む）に使われる文字かを返す
+func isFieldNameRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("_.@*?", r)
+}
+
+// isFieldBoundaryRune はフィールド指定の直前に置ける文字（空白、グループ、演算子）かを返す
+func isFieldBoundaryRune(r rune) bool {
+	return strings.ContainsRune(" \t\r\n(+-!", r)
+}
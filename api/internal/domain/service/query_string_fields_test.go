@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+func TestQueryStringFieldRefs(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "laptop", want: nil},
+		{query: "title:laptop AND price:[0 TO 1000]", want: []string{"title", "price"}},
+		{query: "(password:foo)", want: []string{"password"}},
+		{query: "+secret:x -token:y !api_key:z", want: []string{"secret", "token", "api_key"}},
+		{query: "_exists_:password", want: []string{"password"}},
+		{query: `title:"12:30 meeting"`, want: []string{"title"}},
+		{query: `time:12\:30`, want: []string{"time"}},
+		{query: "pass*:foo", want: []string{"pass*"}},
+		{query: "user.email:a@example.com", want: []string{"user.email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := queryStringFieldRefs(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queryStringFieldRefs(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSearchableFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		queryFields []string
+		mode        entity.SearchMode
+		query       string
+		fields      []string
+		wantErr     bool
+	}{
+		{name: "field on the default wildcard", queryFields: []string{"*"}, mode: entity.SearchModeQueryString, query: "title:laptop"},
+		{name: "sensitive field", queryFields: []string{"*"}, mode: entity.SearchModeQueryString, query: "password:foo", wantErr: true},
+		{name: "sensitive subfield", queryFields: []string{"*"}, mode: entity.SearchModeQueryString, query: "token.raw:foo", wantErr: true},
+		{name: "exists on sensitive field", queryFields: []string{"*"}, mode: entity.SearchModeQueryString, query: "_exists_:ssn", wantErr: true},
+		{name: "wildcard matching sensitive field", queryFields: []string{"*"}, mode: entity.SearchModeQueryString, query: "pass*:foo", wantErr: true},
+		{name: "field outside the configured list", queryFields: []string{"title", "body"}, mode: entity.SearchModeQueryString, query: "email:a", wantErr: true},
+		{name: "field on the configured list", queryFields: []string{"title^2", "body"}, mode: entity.SearchModeQueryString, query: "title:laptop"},
+		{name: "request fields on the configured list", queryFields: []string{"title", "body"}, mode: entity.SearchModeSimpleQueryString, query: "laptop", fields: []string{"title^3"}},
+		{name: "sensitive request field", queryFields: []string{"*"}, mode: entity.SearchModeSimpleQueryString, query: "foo", fields: []string{"password"}, wantErr: true},
+		{name: "match mode is not checked", queryFields: []string{"*"}, mode: entity.SearchModeMatch, query: "password:foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultSearchConfig()
+			config.QueryFields = tt.queryFields
+			s := NewSearchServiceWithConfig(resultWindowRepository{}, config)
+
+			query := entity.NewSearchQuery(tt.query)
+			query.Index = "articles"
+			query.Mode = tt.mode
+			query.Fields = tt.fields
+			err := s.applySearchBusinessRules(context.Background(), query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applySearchBusinessRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.HasCode(err, errors.ErrCodeValidationFailed) {
+				t.Errorf("error = %v, want VALIDATION_FAILED", err)
+			}
+		})
+	}
+}
+
+func TestValidateSearchableFieldsTrustedCaller(t *testing.T) {
+	s := NewSearchServiceWithConfig(resultWindowRepository{}, DefaultSearchConfig())
+
+	query := entity.NewSearchQuery("password:foo")
+	query.Index = "articles"
+	query.Mode = entity.SearchModeQueryString
+	if err := s.applySearchBusinessRules(auth.WithTrusted(context.Background()), query); err != nil {
+		t.Errorf("applySearchBusinessRules() error = %v, want trusted callers to skip the check", err)
+	}
+}
+
+func TestPrepareExportQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantQuery string
+		wantErr   bool
+	}{
+		{name: "field on the configured list", query: "title:laptop", wantQuery: "title:laptop"},
+		{name: "sensitive field", query: "password:foo", wantErr: true},
+		{name: "field outside the configured list", query: "email:a", wantErr: true},
+		{name: "stripped characters", query: "<b>laptop</b>", wantQuery: "blaptop/b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultSearchConfig()
+			config.QueryFields = []string{"title", "body"}
+			config.QuerySanitizePolicy = QuerySanitizePolicy{StripChars: "<>"}
+			s := NewSearchServiceWithConfig(resultWindowRepository{}, config)
+
+			query, err := s.PrepareExportQuery(context.Background(), "articles", tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrepareExportQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.HasCode(err, errors.ErrCodeValidationFailed) {
+					t.Errorf("error = %v, want VALIDATION_FAILED", err)
+				}
+				return
+			}
+			if query.Query != tt.wantQuery || query.Mode != entity.SearchModeQueryString {
+				t.Errorf("query = %q (%s), want %q (query_string)", query.Query, query.Mode, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(query.Fields, config.QueryFields) {
+				t.Errorf("fields = %v, want %v", query.Fields, config.QueryFields)
+			}
+		})
+	}
+}
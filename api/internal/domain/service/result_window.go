@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// DefaultMaxResultWindow はインデックスの設定を取得できない場合に使う index.max_result_window（Elasticsearchのデフォルト値）
+const DefaultMaxResultWindow = 10000
+
+// resultWindowCache はインデックスごとの max_result_window を一定期間キャッシュする
+type resultWindowCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]resultWindowEntry
+}
+
+// resultWindowEntry はキャッシュしたmax_result_windowと有効期限を表す
+type resultWindowEntry struct {
+	window  int
+	expires time.Time
+}
+
+// newResultWindowCache は新しい resultWindowCache を作成する
+func newResultWindowCache(ttl time.Duration) *resultWindowCache {
+	return &resultWindowCache{
+		ttl:     ttl,
+		entries: make(map[string]resultWindowEntry),
+	}
+}
+
+// get は有効期限内のキャッシュがあればその値を返す
+func (c *resultWindowCache) get(key string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return 0, false
+	}
+	return entry.window, true
+}
+
+// set は値をキャッシュする（TTLが0の場合はキャッシュしない）
+func (c *resultWindowCache) set(key string, window int) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = resultWindowEntry{window: window, expires: time.Now().Add(c.ttl)}
+}
+
+// clear は match に一致するキーを削除し、削除した件数を返す（match が nil の場合はすべて削除する）
+func (c *resultWindowCache) clear(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evicted := 0
+	for key := range c.entries {
+		if match == nil || match(key) {
+			delete(c.entries, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// maxResultWindow は検索対象インデックスの max_result_window を返す
+// 設定を取得できない場合（インデックスが存在しない場合など）は DefaultMaxResultWindow を返し、キャッシュしない
+// キャッシュはテナントごとに分ける（マルチテナンシー有効時は同じインデックス名でも実体が異なるため）
+func (s *SearchService) maxResultWindow(ctx context.Context, index string) int {
+	key := auth.Tenant(ctx) + "/" + index
+	if window, ok := s.resultWindows.get(key); ok {
+		return window
+	}
+
+	window, err := s.repo.MaxResultWindow(ctx, index)
+	if err != nil || window <= 0 {
+		return DefaultMaxResultWindow
+	}
+	s.resultWindows.set(key, window)
+	return window
+}
+
+// checkResultWindow は from + size がインデックスの max_result_window を超える検索を拒否する
+// 深いページングには search_after を使うよう案内する
+func (s *SearchService) checkResultWindow(ctx context.Context, query *entity.SearchQuery) error {
+	window := s.maxResultWindow(ctx, query.Index)
+	if query.From+query.Size <= window {
+		return nil
+	}
+
+	return errors.NewFieldValidationError([]errors.FieldError{{
+		Field: "from",
+		Message: fmt.Sprintf("from + size must be less than or equal to %d (index.max_result_window of %s); use search_after for deep pagination",
+			window, query.Index),
+	}})
+}
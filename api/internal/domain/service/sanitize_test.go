@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy QuerySanitizePolicy
+		mode   entity.SearchMode
+		query  string
+		want   string
+	}{
+		// すべてのモードで制御文字を除去し、前後の空白を取り除く
+		{name: "control characters", mode: entity.SearchModeMatch, query: " go\x00lang\x1b ", want: "golang"},
+		{name: "whitespace inside is kept", mode: entity.SearchModeMatch, query: "go\tlang", want: "go\tlang"},
+
+		// match モードは multi_match の文字列として送るため、Lucene の演算子や < > もそのまま
+		{name: "match keeps operators", mode: entity.SearchModeMatch, query: `"exact" +go -java <b>`, want: `"exact" +go -java <b>`},
+		{name: "default mode keeps operators", query: `a:b OR (c)`, want: `a:b OR (c)`},
+		{name: "search as you type keeps text", mode: entity.SearchModeSearchAsYouType, query: "go la", want: "go la"},
+		{name: "match strips policy characters", policy: QuerySanitizePolicy{StripChars: "<>"}, mode: entity.SearchModeMatch, query: "<b>go</b>", want: "bgo/b"},
+		{name: "match ignores escape characters", policy: QuerySanitizePolicy{EscapeChars: ":"}, mode: entity.SearchModeMatch, query: "a:b", want: "a:b"},
+
+		// query_string / simple_query_string モードは構文を保持し、ポリシーの文字のみエスケープする
+		{name: "query_string keeps syntax", mode: entity.SearchModeQueryString, query: `title:"go lang" AND price:[0 TO 10]`, want: `title:"go lang" AND price:[0 TO 10]`},
+		{name: "query_string escapes policy characters", policy: QuerySanitizePolicy{EscapeChars: "/~"}, mode: entity.SearchModeQueryString, query: "a/b~2", want: `a\/b\~2`},
+		{name: "escaping also escapes backslashes", policy: QuerySanitizePolicy{EscapeChars: ":"}, mode: entity.SearchModeQueryString, query: `a\:b`, want: `a\\\:b`},
+		{name: "backslashes untouched without escape characters", mode: entity.SearchModeQueryString, query: `a\:b`, want: `a\:b`},
+		{name: "simple_query_string escapes policy characters", policy: QuerySanitizePolicy{EscapeChars: "|"}, mode: entity.SearchModeSimpleQueryString, query: "go | java", want: `go \| java`},
+		{name: "strip wins over escape", policy: QuerySanitizePolicy{StripChars: "*", EscapeChars: "*?"}, mode: entity.SearchModeQueryString, query: "go* la?", want: `go la\?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultSearchConfig()
+			config.QuerySanitizePolicy = tt.policy
+			s := NewSearchServiceWithConfig(nil, config)
+
+			if got := s.sanitizeQuery(tt.query, tt.mode); got != tt.want {
+				t.Errorf("sanitizeQuery(%q, %q) = %q, want %q", tt.query, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySearchBusinessRulesSanitizePolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      QuerySanitizePolicy
+		trusted     bool
+		query       string
+		want        string
+		wantApplied bool
+	}{
+		{name: "default policy keeps comparison characters", query: "C++ > C", want: "C++ > C"},
+		{name: "policy strips characters", policy: QuerySanitizePolicy{StripChars: "<>"}, query: "<b>go</b>", want: "bgo/b", wantApplied: true},
+		{name: "trusted caller is not sanitized", policy: QuerySanitizePolicy{StripChars: "<>"}, trusted: true, query: "<b>go</b>", want: "<b>go</b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultSearchConfig()
+			config.QuerySanitizePolicy = tt.policy
+			s := NewSearchServiceWithConfig(resultWindowRepository{}, config)
+
+			ctx := context.Background()
+			if tt.trusted {
+				ctx = auth.WithTrusted(ctx)
+			}
+			query := entity.NewSearchQuery(tt.query)
+			query.Index = "articles"
+			query.Debug = true
+			if err := s.applySearchBusinessRules(ctx, query); err != nil {
+				t.Fatalf("applySearchBusinessRules() error = %v", err)
+			}
+
+			if query.Query != tt.want {
+				t.Errorf("query = %q, want %q", query.Query, tt.want)
+			}
+			applied := false
+			for _, rule := range query.AppliedRules {
+				applied = applied || rule.Rule == "query_sanitized"
+			}
+			if applied != tt.wantApplied {
+				t.Errorf("query_sanitized applied = %v, want %v", applied, tt.wantApplied)
+			}
+		})
+	}
+}
@@ -3,68 +3,252 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
 	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 )
 
 // Searcher は検索サービスのインターフェース
 type Searcher interface {
+	ExecuteSearch(ctx context.Context, query *entity.SearchQuery, opts ...repository.SearchOption) (*entity.SearchResult, error)
+	RenderSearch(ctx context.Context, query *entity.SearchQuery) (map[string]any, error)
+	ValidateSearch(ctx context.Context, query *entity.SearchQuery) (*entity.QueryValidation, error)
+	PrepareExportQuery(ctx context.Context, index, queryStr string) (*entity.SearchQuery, error)
 	Search(ctx context.Context, queryStr string, index string, from, size int) (*entity.SearchResult, error)
 	AdvancedSearch(ctx context.Context, queryStr string, index string, filters map[string]string, sortFields []entity.SortField, from, size int) (*entity.SearchResult, error)
 	MultiSearch(ctx context.Context, queries []entity.SearchQuery) ([]*entity.SearchResult, error)
-	SuggestSearch(ctx context.Context, queryStr string, index string, field string, size int) (*entity.SearchResult, error)
+	SuggestSearch(ctx context.Context, queryStr string, index string, field string, mode entity.SuggestMode, size int) (*entity.SearchResult, error)
 	FacetedSearch(ctx context.Context, queryStr string, index string, facetFields []string, from, size int) (*entity.SearchResult, error)
+	MoreLikeThisSearch(ctx context.Context, query *entity.MoreLikeThisQuery) (*entity.SearchResult, error)
+	ListDocuments(ctx context.Context, index string, from, size int, sortFields []entity.SortField) (*entity.SearchResult, error)
+	CountByField(ctx context.Context, index, field string, size int) (*entity.TermsResult, error)
+	ClearCaches(index string) map[string]int
+}
+
+// SearchConfig は検索サービスの設定を表す
+type SearchConfig struct {
+	DefaultSize int
+	MaxSize     int
+	// DefaultOperator はクエリ内の語句を結合するデフォルト演算子（"and" または "or"）
+	DefaultOperator string
+	// QueryFields はフィールド指定のない検索で対象とするフィールド
+	QueryFields []string
+	// FieldBoosts はフィールド指定のない検索に適用するインデックスごとのフィールドブースト
+	FieldBoosts map[string]map[string]float64
+	// DefaultSorts はソート指定のない検索に適用するインデックスごとのソート（未設定のインデックスは _score の降順）
+	DefaultSorts map[string][]entity.SortField
+	// QuerySanitizePolicy は公開トラフィックの検索語から取り除く・エスケープする文字（ゼロ値の場合は制御文字の除去のみ）
+	QuerySanitizePolicy QuerySanitizePolicy
+	// CursorTiebreaker は一意な値を持つフィールド（doc_values のある keyword など）で、全ての検索のソートの最後に追加する
+	// 空の場合は追加せず、ソートが _id で終わる検索のみをカーソルでページングできる
+	CursorTiebreaker string
+	// AllowedAnalyzers はリクエストで指定できるアナライザー（空の場合はアナライザーの指定を受け付けない）
+	AllowedAnalyzers []string
+	// DefaultIndex はリクエストでインデックスが指定されなかった場合の検索対象（空の場合は指定必須）
+	DefaultIndex string
+	// ResultWindowCacheTTL はインデックスの max_result_window をキャッシュする期間（0の場合は毎回取得する）
+	ResultWindowCacheTTL time.Duration
+	// CheckIndexExists は単一インデックスの検索の前に存在を確認し、存在しなければ IndexNotFound（404）を返す
+	CheckIndexExists bool
+	// IndexExistsCacheTTL は存在を確認したインデックスをキャッシュする期間（0の場合は毎回確認する）
+	IndexExistsCacheTTL time.Duration
+	// FieldValidation はフィルターとソートのフィールド名をマッピングに対して検証する方法
+	FieldValidation FieldValidationMode
+	// FieldMappingCacheTTL はマッピングに存在を確認したフィールドをキャッシュする期間（0の場合は毎回確認する）
+	FieldMappingCacheTTL time.Duration
+	// ResultCacheTTL は検索結果をキャッシュする期間（0の場合はキャッシュしない）
+	// 期間内はドキュメントの更新が検索結果に反映されないため、更新の反映より応答速度を優先する場合のみ指定する
+	ResultCacheTTL time.Duration
+	// ResultCacheMaxEntries はキャッシュする検索結果の上限
+	ResultCacheMaxEntries int
+	// Logger はフィールド検証の警告などを記録するロガー（nil の場合は標準のロガー）
+	Logger *log.Logger
+}
+
+// QuerySanitizePolicy は公開トラフィックの検索語に適用する文字単位のサニタイズを表す
+// 検索語の除去は XSS 対策にはならない（検索語は JSON のレスポンスに含まれるだけで、
+// HTML への埋め込み時に呼び出し元がエンコードする必要がある）ため、デフォルトでは何も除去しない
+type QuerySanitizePolicy struct {
+	// StripChars はすべての検索モードで検索語から取り除く文字
+	StripChars string
+	// EscapeChars は query_string / simple_query_string モードでバックスラッシュでエスケープし、
+	// クエリ構文ではなく文字として扱う文字（StripChars にも含まれる文字は取り除かれる）
+	// 指定した場合はエスケープを打ち消されないよう、バックスラッシュもエスケープする
+	EscapeChars string
+}
+
+// DefaultSearchConfig はデフォルトの検索設定を返す
+func DefaultSearchConfig() *SearchConfig {
+	return &SearchConfig{
+		DefaultSize:     10,
+		MaxSize:         1000,
+		DefaultOperator: "or",
+		QueryFields:     []string{"*"},
+
+		ResultWindowCacheTTL: time.Minute,
+		IndexExistsCacheTTL:  time.Minute,
+		FieldValidation:      FieldValidationLenient,
+		FieldMappingCacheTTL: time.Minute,
+
+		ResultCacheMaxEntries: 1000,
+	}
 }
 
 // SearchService は検索操作のビジネスロジックを提供する
 type SearchService struct {
-	repo repository.ElasticsearchRepository
+	repo          repository.ElasticsearchRepository
+	config        *SearchConfig
+	resultWindows *resultWindowCache
+	indexExists   *presenceCache
+	knownFields   *presenceCache
+	results       *searchResultCache
 }
 
 // NewSearchService は新しいSearchServiceを作成する
 func NewSearchService(repo repository.ElasticsearchRepository) *SearchService {
-	return &SearchService{
-		repo: repo,
-	}
+	return NewSearchServiceWithConfig(repo, DefaultSearchConfig())
 }
 
-// Search は検索操作を実行する
-func (s *SearchService) Search(ctx context.Context, queryStr string, index string, from, size int) (*entity.SearchResult, error) {
-	// 入力を検証
-	if queryStr == "" {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Search query cannot be empty")
+// Validate は設定値を検証する（インデックスごとのデフォルトソートはソート可能なフィールドと順序のみ許可する）
+func (c *SearchConfig) Validate() error {
+	if c.FieldValidation != "" && !c.FieldValidation.IsValid() {
+		return fmt.Errorf("invalid field validation mode: %s (must be off, lenient or strict)", c.FieldValidation)
 	}
-
-	if size < 0 {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Size must be non-negative")
+	for index, sorts := range c.DefaultSorts {
+		for _, sort := range sorts {
+			if !allowedSortFields[sort.Field] {
+				return fmt.Errorf("invalid default sort field for index %s: %s is not a sortable field", index, sort.Field)
+			}
+			if !entity.IsValidSortOrder(sort.Order) {
+				return fmt.Errorf("invalid default sort order for %s.%s: %s", index, sort.Field, sort.Order)
+			}
+		}
 	}
+	return nil
+}
 
-	if from < 0 {
-		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "From must be non-negative")
+// NewSearchServiceWithConfig は設定を指定して新しいSearchServiceを作成する
+func NewSearchServiceWithConfig(repo repository.ElasticsearchRepository, config *SearchConfig) *SearchService {
+	defaults := DefaultSearchConfig()
+	if config == nil {
+		config = defaults
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = defaults.MaxSize
+	}
+	if config.DefaultSize <= 0 {
+		config.DefaultSize = defaults.DefaultSize
+	}
+	if config.DefaultSize > config.MaxSize {
+		config.DefaultSize = config.MaxSize
+	}
+	if config.DefaultOperator == "" || !entity.IsValidOperator(config.DefaultOperator) {
+		config.DefaultOperator = defaults.DefaultOperator
+	}
+	if len(config.QueryFields) == 0 {
+		config.QueryFields = defaults.QueryFields
+	}
+	if config.ResultWindowCacheTTL < 0 {
+		config.ResultWindowCacheTTL = defaults.ResultWindowCacheTTL
+	}
+	if config.IndexExistsCacheTTL < 0 {
+		config.IndexExistsCacheTTL = defaults.IndexExistsCacheTTL
+	}
+	if config.FieldValidation == "" {
+		config.FieldValidation = defaults.FieldValidation
+	}
+	if config.FieldMappingCacheTTL < 0 {
+		config.FieldMappingCacheTTL = defaults.FieldMappingCacheTTL
+	}
+	if config.ResultCacheTTL < 0 {
+		config.ResultCacheTTL = defaults.ResultCacheTTL
+	}
+	if config.ResultCacheMaxEntries <= 0 {
+		config.ResultCacheMaxEntries = defaults.ResultCacheMaxEntries
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
 	}
 
-	// デフォルト値を適用
-	if size == 0 {
-		size = 10
+	return &SearchService{
+		repo:          repo,
+		config:        config,
+		resultWindows: newResultWindowCache(config.ResultWindowCacheTTL),
+		indexExists:   newPresenceCache(config.IndexExistsCacheTTL),
+		knownFields:   newPresenceCache(config.FieldMappingCacheTTL),
+		results:       newSearchResultCache(config.ResultCacheTTL, config.ResultCacheMaxEntries),
 	}
+}
 
+// GetConfig は検索サービスの設定を返す
+func (s *SearchService) GetConfig() *SearchConfig {
+	return s.config
+}
+
+// Search は検索操作を実行する
+func (s *SearchService) Search(ctx context.Context, queryStr string, index string, from, size int) (*entity.SearchResult, error) {
 	// 検索クエリを作成
 	query := entity.NewSearchQuery(queryStr)
 	query.SetIndex(index)
 	query.SetPagination(from, size)
 
+	return s.ExecuteSearch(ctx, query)
+}
+
+// ExecuteSearch は構築済みの検索クエリで検索を実行する
+// query.Index にはカンマ区切りの複数インデックスやワイルドカードパターンを指定できる
+func (s *SearchService) ExecuteSearch(ctx context.Context, query *entity.SearchQuery, opts ...repository.SearchOption) (*entity.SearchResult, error) {
+	// 入力を検証
+	if err := s.applyDefaultIndex(query); err != nil {
+		return nil, err
+	}
+	if err := s.validateSearchQuery(query); err != nil {
+		return nil, err
+	}
+	if err := validateSearchOptions(repository.NewSearchOptions(opts...)); err != nil {
+		return nil, err
+	}
+	if err := s.checkIndexExists(ctx, query); err != nil {
+		return nil, err
+	}
+	if err := s.validateQueryFields(ctx, query); err != nil {
+		return nil, err
+	}
+	if err := s.validateCollapseField(ctx, query); err != nil {
+		return nil, err
+	}
+	if err := s.validateFunctionScoreFields(ctx, query); err != nil {
+		return nil, err
+	}
+
 	// クエリにビジネスルールを適用
-	if err := s.applySearchBusinessRules(query); err != nil {
+	if err := s.applySearchBusinessRules(ctx, query); err != nil {
 		return nil, err
 	}
 
+	// 同じ内容の検索の結果がキャッシュにあれば返す（処理時間の内訳は検索ごとに異なるためキャッシュしない）
+	var cacheKey string
+	if !query.Profile {
+		cacheKey = searchResultCacheKey(ctx, query, repository.NewSearchOptions(opts...))
+		if cached, ok := s.results.get(cacheKey); ok {
+			// キャッシュは指定順などが異なるクエリと共有するため、結果のクエリは今回のものにする
+			result := *cached
+			result.Query = *query
+			return &result, nil
+		}
+	}
+
 	// 検索を実行
-	result, err := s.repo.Search(ctx, query)
+	result, err := s.repo.Search(ctx, query, opts...)
 	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Search operation failed")
+		return nil, wrapSearchError(err, "Search operation failed")
 	}
 
 	// 結果を後処理
@@ -72,9 +256,69 @@ func (s *SearchService) Search(ctx context.Context, queryStr string, index strin
 		return nil, err
 	}
 
+	if cacheKey != "" {
+		s.results.set(cacheKey, result)
+	}
 	return result, nil
 }
 
+// RenderSearch は検索を実行せずに、ExecuteSearch が送信するElasticsearchクエリを返す
+func (s *SearchService) RenderSearch(ctx context.Context, query *entity.SearchQuery) (map[string]any, error) {
+	if err := s.prepareQuery(ctx, query); err != nil {
+		return nil, err
+	}
+
+	return s.repo.RenderSearchQuery(query), nil
+}
+
+// ValidateSearch は検索を実行せずに、ExecuteSearch が送信するクエリを対象インデックスのマッピングに対して
+// Elasticsearch で検証する。クエリが不正な場合もエラーではなく valid=false の結果を返す
+func (s *SearchService) ValidateSearch(ctx context.Context, query *entity.SearchQuery) (*entity.QueryValidation, error) {
+	if err := s.prepareQuery(ctx, query); err != nil {
+		return nil, err
+	}
+
+	validation, err := s.repo.ValidateQuery(ctx, query)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return nil, err
+		}
+		return nil, wrapSearchError(err, "Query validation failed")
+	}
+
+	return validation, nil
+}
+
+// PrepareExportQuery はエクスポートの絞り込み条件を query_string モードの検索クエリとして構築する
+// 検索と同じ検証と無害化を適用し、参照できるフィールドも検索と同じく制限する
+func (s *SearchService) PrepareExportQuery(ctx context.Context, index, queryStr string) (*entity.SearchQuery, error) {
+	query := entity.NewSearchQuery(queryStr)
+	query.SetIndex(index)
+	query.Mode = entity.SearchModeQueryString
+
+	if err := s.prepareQuery(ctx, query); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// prepareQuery は ExecuteSearch と同じ検証とビジネスルールを適用する
+func (s *SearchService) prepareQuery(ctx context.Context, query *entity.SearchQuery) error {
+	if err := s.applyDefaultIndex(query); err != nil {
+		return err
+	}
+	if err := s.validateSearchQuery(query); err != nil {
+		return err
+	}
+	if err := s.validateCollapseField(ctx, query); err != nil {
+		return err
+	}
+	if err := s.validateFunctionScoreFields(ctx, query); err != nil {
+		return err
+	}
+	return s.applySearchBusinessRules(ctx, query)
+}
+
 // AdvancedSearch はフィルターとソートを含む高度な検索を実行する
 func (s *SearchService) AdvancedSearch(ctx context.Context, queryStr string, index string, filters map[string]string, sortFields []entity.SortField, from, size int) (*entity.SearchResult, error) {
 	// 入力を検証
@@ -92,7 +336,7 @@ func (s *SearchService) AdvancedSearch(ctx context.Context, queryStr string, ind
 
 	// デフォルト値を適用
 	if size == 0 {
-		size = 10
+		size = s.config.DefaultSize
 	}
 
 	// 検索クエリを作成
@@ -109,20 +353,20 @@ func (s *SearchService) AdvancedSearch(ctx context.Context, queryStr string, ind
 
 	// ソートを追加
 	for _, sortField := range sortFields {
-		if sortField.Field != "" && (sortField.Order == "asc" || sortField.Order == "desc") {
-			query.AddSort(sortField.Field, sortField.Order)
+		if sortField.Field != "" && entity.IsValidSortOrder(sortField.Order) {
+			query.AddSort(sortField.Field, entity.NormalizeSortOrder(sortField.Order))
 		}
 	}
 
 	// クエリにビジネスルールを適用
-	if err := s.applySearchBusinessRules(query); err != nil {
+	if err := s.applySearchBusinessRules(ctx, query); err != nil {
 		return nil, err
 	}
 
 	// 検索を実行
 	result, err := s.repo.Search(ctx, query)
 	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Advanced search operation failed")
+		return nil, wrapSearchError(err, "Advanced search operation failed")
 	}
 
 	// 結果を後処理
@@ -140,13 +384,14 @@ func (s *SearchService) MultiSearch(ctx context.Context, queries []entity.Search
 	}
 
 	// 全てのクエリを検証
-	for i, query := range queries {
-		if err := s.validateSearchQuery(&query); err != nil {
+	for i := range queries {
+		query := &queries[i]
+		if err := s.validateSearchQuery(query); err != nil {
 			return nil, errors.NewAppError(errors.ErrCodeValidationFailed, fmt.Sprintf("Query %d validation failed: %v", i, err))
 		}
 
 		// 各クエリにビジネスルールを適用
-		if err := s.applySearchBusinessRules(&query); err != nil {
+		if err := s.applySearchBusinessRules(ctx, query); err != nil {
 			return nil, errors.NewAppError(errors.ErrCodeValidationFailed, fmt.Sprintf("Query %d business rule validation failed: %v", i, err))
 		}
 	}
@@ -160,7 +405,7 @@ func (s *SearchService) MultiSearch(ctx context.Context, queries []entity.Search
 	// マルチ検索を実行
 	results, err := s.repo.MultiSearch(ctx, queryPointers)
 	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Multi-search operation failed")
+		return nil, wrapSearchError(err, "Multi-search operation failed")
 	}
 
 	// 全ての結果を後処理
@@ -174,7 +419,7 @@ func (s *SearchService) MultiSearch(ctx context.Context, queries []entity.Search
 }
 
 // SuggestSearch はサジェスト/オートコンプリート検索を実行する
-func (s *SearchService) SuggestSearch(ctx context.Context, queryStr string, index string, field string, size int) (*entity.SearchResult, error) {
+func (s *SearchService) SuggestSearch(ctx context.Context, queryStr string, index string, field string, mode entity.SuggestMode, size int) (*entity.SearchResult, error) {
 	if queryStr == "" {
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Search query cannot be empty")
 	}
@@ -183,27 +428,48 @@ func (s *SearchService) SuggestSearch(ctx context.Context, queryStr string, inde
 		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Field for suggestion cannot be empty")
 	}
 
+	if !mode.IsValid() {
+		return nil, errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "mode",
+			Message: "Suggest mode must be 'prefix' or 'search_as_you_type'",
+		}})
+	}
+
 	if size <= 0 {
 		size = 5 // デフォルトサジェストサイズ
 	}
 
-	// サジェスト用のプレフィックスクエリを作成
-	suggestQuery := fmt.Sprintf("%s*", queryStr)
+	var query *entity.SearchQuery
+	if mode == entity.SuggestModeSearchAsYouType {
+		// search_as_you_type としてマッピングされたフィールドのみを対象にする
+		resolved, err := s.resolveIndex(index)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.validateSearchAsYouTypeField(ctx, resolved, field); err != nil {
+			return nil, err
+		}
 
-	// 検索クエリを作成
-	query := entity.NewSearchQuery(suggestQuery)
+		query = entity.NewSearchQuery(queryStr)
+		query.Mode = entity.SearchModeSearchAsYouType
+		query.Fields = []string{field}
+		index = resolved
+	} else {
+		// サジェスト用のプレフィックスクエリを作成
+		query = entity.NewSearchQuery(fmt.Sprintf("%s*", queryStr))
+	}
 	query.SetIndex(index)
 	query.SetPagination(0, size)
 
 	// ビジネスルールを適用
-	if err := s.applySearchBusinessRules(query); err != nil {
+	if err := s.applySearchBusinessRules(ctx, query); err != nil {
 		return nil, err
 	}
 
 	// 検索を実行
 	result, err := s.repo.Search(ctx, query)
 	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Suggest search operation failed")
+		return nil, wrapSearchError(err, "Suggest search operation failed")
 	}
 
 	// 結果を後処理
@@ -226,7 +492,7 @@ func (s *SearchService) FacetedSearch(ctx context.Context, queryStr string, inde
 
 	// Apply default values
 	if size == 0 {
-		size = 10
+		size = s.config.DefaultSize
 	}
 
 	// Create search query
@@ -239,14 +505,14 @@ func (s *SearchService) FacetedSearch(ctx context.Context, queryStr string, inde
 	query.AddFilter("_facets", strings.Join(facetFields, ","))
 
 	// Apply business rules
-	if err := s.applySearchBusinessRules(query); err != nil {
+	if err := s.applySearchBusinessRules(ctx, query); err != nil {
 		return nil, err
 	}
 
 	// Perform search
 	result, err := s.repo.Search(ctx, query)
 	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Faceted search operation failed")
+		return nil, wrapSearchError(err, "Faceted search operation failed")
 	}
 
 	// Post-process results
@@ -257,36 +523,252 @@ func (s *SearchService) FacetedSearch(ctx context.Context, queryStr string, inde
 	return result, nil
 }
 
-// applySearchBusinessRules applies business rules to search queries
-func (s *SearchService) applySearchBusinessRules(query *entity.SearchQuery) error {
+// ListDocuments は検索語を指定せずにインデックス内のドキュメントを一覧する（match_all）
+// 他の検索と異なり空のクエリ文字列を許可する
+func (s *SearchService) ListDocuments(ctx context.Context, index string, from, size int, sortFields []entity.SortField) (*entity.SearchResult, error) {
+	// 入力を検証
+	if size < 0 {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "Size must be non-negative")
+	}
+
+	if from < 0 {
+		return nil, errors.NewAppError(errors.ErrCodeValidationFailed, "From must be non-negative")
+	}
+
+	// 全件一致の検索クエリを作成
+	query := entity.NewSearchQuery("")
+	query.SetIndex(index)
+	query.SetPagination(from, size)
+	for _, sortField := range sortFields {
+		query.AddSort(sortField.Field, sortField.Order)
+	}
+
+	// クエリにビジネスルールを適用（インデックスの解決とソートの検証を含む）
+	if err := s.applySearchBusinessRules(ctx, query); err != nil {
+		return nil, err
+	}
+
+	// 検索を実行
+	result, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, wrapSearchError(err, "List documents operation failed")
+	}
+
+	// 結果を後処理
+	if err := s.postProcessSearchResults(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MoreLikeThisSearch は任意のテキストに類似したドキュメントを検索する
+func (s *SearchService) MoreLikeThisSearch(ctx context.Context, query *entity.MoreLikeThisQuery) (*entity.SearchResult, error) {
+	// 入力を検証
+	var fields errors.FieldErrors
+	if strings.TrimSpace(query.Text) == "" {
+		fields.Add("text", "Text cannot be empty")
+	}
+	if query.MinTermFreq < 0 {
+		fields.Add("min_term_freq", "Min term frequency must be non-negative")
+	}
+	if query.MaxQueryTerms < 0 {
+		fields.Add("max_query_terms", "Max query terms must be non-negative")
+	}
+	if query.MinDocFreq < 0 {
+		fields.Add("min_doc_freq", "Min document frequency must be non-negative")
+	}
+	if query.Size < 0 {
+		fields.Add("size", "Size must be non-negative")
+	}
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+
+	// デフォルト値とサイズ上限を適用
+	index, err := s.resolveIndex(query.Index)
+	if err != nil {
+		return nil, err
+	}
+	query.Index = index
+	if len(query.Fields) == 0 {
+		query.Fields = append([]string(nil), s.config.QueryFields...)
+	}
+	if query.Size == 0 {
+		query.Size = s.config.DefaultSize
+	}
+	if query.Size > s.config.MaxSize {
+		query.Size = s.config.MaxSize
+	}
+
+	// 検索を実行
+	result, err := s.repo.MoreLikeThis(ctx, query)
+	if err != nil {
+		return nil, wrapSearchError(err, "More like this search operation failed")
+	}
+
+	// 結果を後処理
+	if err := s.postProcessSearchResults(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// applyDefaultIndex fills in the configured default index when the query has none
+func (s *SearchService) applyDefaultIndex(query *entity.SearchQuery) error {
+	index, err := s.resolveIndex(query.Index)
+	if err != nil {
+		return err
+	}
+	if query.Index == "" {
+		query.RecordRule("default_index", "index defaulted to %s", index)
+	}
+	query.Index = index
+	return nil
+}
+
+// resolveIndex returns the requested index, falling back to the configured default.
+// Searching without either is rejected rather than silently searching all indices.
+func (s *SearchService) resolveIndex(index string) (string, error) {
+	if index != "" {
+		return index, nil
+	}
+	if s.config.DefaultIndex != "" {
+		return s.config.DefaultIndex, nil
+	}
+	return "", errors.NewFieldValidationError([]errors.FieldError{{
+		Field:   "index",
+		Message: "Index is required when no default index is configured",
+	}})
+}
+
+// applySearchBusinessRules applies business rules to search queries.
+// Trusted callers (see auth.IsTrusted) skip query sanitization and the sort field allowlist;
+// public traffic always gets the strict defaults.
+func (s *SearchService) applySearchBusinessRules(ctx context.Context, query *entity.SearchQuery) error {
+	trusted := auth.IsTrusted(ctx)
+
+	// Resolve the target index
+	if err := s.applyDefaultIndex(query); err != nil {
+		return err
+	}
+
 	// Sanitize query string
-	query.Query = s.sanitizeQuery(query.Query)
+	if !trusted {
+		sanitized := s.sanitizeQuery(query.Query, query.Mode)
+		if sanitized != query.Query {
+			query.RecordRule("query_sanitized", "query sanitized from %q to %q", query.Query, sanitized)
+		}
+		query.Query = sanitized
+	}
+
+	// Fields referenced by query syntax must be searchable; checked before the default fields are applied
+	if !trusted {
+		if err := s.validateSearchableFields(query); err != nil {
+			return err
+		}
+	}
+
+	// Profiling is expensive, so only trusted callers may request it
+	if query.Profile && !trusted {
+		return errors.NewAppError(errors.ErrCodeForbidden, "Search profiling is only available to trusted callers")
+	}
+
+	// Apply configured query defaults; per-index field boosts only apply
+	// when the request leaves the fields to the server
+	if len(query.Fields) == 0 {
+		query.Fields = append([]string(nil), s.config.QueryFields...)
+		query.FieldBoosts = s.config.FieldBoosts[query.Index]
+		if len(query.Fields) > 0 {
+			query.RecordRule("default_fields", "query fields defaulted to %s", strings.Join(query.Fields, ","))
+		}
+		if len(query.FieldBoosts) > 0 {
+			query.RecordRule("field_boosts", "field boosts for index %s applied", query.Index)
+		}
+	}
+	if query.DefaultOperator == "" && s.config.DefaultOperator != "" {
+		query.DefaultOperator = s.config.DefaultOperator
+		query.RecordRule("default_operator", "default_operator defaulted to %s", query.DefaultOperator)
+	}
+	query.DefaultOperator = strings.ToLower(query.DefaultOperator)
+
+	// Apply default result size
+	if query.Size == 0 {
+		query.Size = s.config.DefaultSize
+		query.RecordRule("default_size", "size defaulted to %d", query.Size)
+	}
 
 	// Apply maximum result size limit
-	if query.Size > 1000 {
-		query.Size = 1000
+	if query.Size > s.config.MaxSize {
+		query.RecordRule("size_clamped", "size clamped from %d to %d", query.Size, s.config.MaxSize)
+		query.Size = s.config.MaxSize
 	}
 
-	// Apply maximum offset limit
-	if query.From > 10000 {
-		return errors.NewAppError(errors.ErrCodeValidationFailed, "From offset cannot exceed 10000")
+	// Apply the index's maximum result window
+	if err := s.checkResultWindow(ctx, query); err != nil {
+		return err
 	}
 
-	// Add default sorting if none specified
+	// Add the index's configured default sorting if none specified, falling back to relevance
+	if len(query.Sort) == 0 {
+		query.Sort = slices.Clone(s.config.DefaultSorts[query.Index])
+		if len(query.Sort) > 0 {
+			query.RecordRule("default_sort", "default sort for index %s added: %s", query.Index, formatSortFields(query.Sort))
+		}
+	}
 	if len(query.Sort) == 0 {
 		query.AddSort("_score", "desc")
+		query.RecordRule("default_sort", "default sort added: _score:desc")
 	}
 
-	// Validate sort fields
-	for _, sortField := range query.Sort {
-		if !s.isValidSortField(sortField.Field) {
+	// Validate sort fields and normalize sort orders
+	for i := range query.Sort {
+		sortField := &query.Sort[i]
+		if !trusted && !s.isValidSortField(sortField.Field) {
 			return errors.NewAppError(errors.ErrCodeValidationFailed, fmt.Sprintf("Invalid sort field: %s", sortField.Field))
 		}
+		if !entity.IsValidSortOrder(sortField.Order) {
+			return errors.NewAppError(errors.ErrCodeValidationFailed, fmt.Sprintf("Invalid sort order: %s", sortField.Order))
+		}
+		if normalized := entity.NormalizeSortOrder(sortField.Order); normalized != sortField.Order {
+			query.RecordRule("sort_order_normalized", "sort order of %s normalized from %q to %q", sortField.Field, sortField.Order, normalized)
+			sortField.Order = normalized
+		}
+	}
+
+	// End the sort with a field of unique values so that search_after pages never skip or repeat ties.
+	// The tiebreaker is configured by the server, so it is not checked against the sortable fields.
+	if tiebreaker := s.config.CursorTiebreaker; tiebreaker != "" {
+		if last := query.Sort[len(query.Sort)-1].Field; last != tiebreaker && last != "_id" {
+			query.AddSort(tiebreaker, "asc")
+			query.RecordRule("cursor_tiebreaker", "tiebreaker sort added: %s:asc", tiebreaker)
+		}
+	}
+	query.UniqueSort = isUniqueSortField(query.Sort[len(query.Sort)-1].Field, s.config.CursorTiebreaker)
+
+	// The _facets filter is a marker for facet aggregations, not a filter on the results
+	if facets, ok := query.Filters["_facets"]; ok {
+		query.RecordRule("facets", "_facets is not applied as a filter; facet aggregations requested for %s", facets)
 	}
 
 	return nil
 }
 
+// isUniqueSortField reports whether field holds a unique value per document
+func isUniqueSortField(field, tiebreaker string) bool {
+	return field == "_id" || (tiebreaker != "" && field == tiebreaker)
+}
+
+// formatSortFields formats sort fields as field:order pairs for applied rule descriptions
+func formatSortFields(sorts []entity.SortField) string {
+	parts := make([]string, len(sorts))
+	for i, sort := range sorts {
+		parts[i] = sort.Field + ":" + sort.Order
+	}
+	return strings.Join(parts, ",")
+}
+
 // postProcessSearchResults post-processes search results
 func (s *SearchService) postProcessSearchResults(result *entity.SearchResult) error {
 	if result == nil {
@@ -294,18 +776,36 @@ func (s *SearchService) postProcessSearchResults(result *entity.SearchResult) er
 	}
 
 	// Apply business rules to results
-	for i := range result.Hits {
-		hit := &result.Hits[i]
+	return s.postProcessHits(result.Hits)
+}
+
+// postProcessHits applies result business rules to hits, including collapsed and nested inner hits
+func (s *SearchService) postProcessHits(hits []entity.Hit) error {
+	for i := range hits {
+		hit := &hits[i]
 
-		// Remove sensitive fields from results
+		// Remove sensitive fields from results, including their highlighted fragments and stored or doc values
 		if hit.Source != nil {
 			s.removeSensitiveFields(hit.Source)
 		}
+		if hit.Highlight != nil {
+			removeSensitiveSubfields(hit.Highlight)
+		}
+		if hit.Fields != nil {
+			removeSensitiveSubfields(hit.Fields)
+		}
 
 		// Add computed fields
 		if err := s.addComputedFields(hit); err != nil {
 			return err
 		}
+
+		if err := s.postProcessHits(hit.Collapsed); err != nil {
+			return err
+		}
+		if err := s.postProcessHits(hit.Nested); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -325,43 +825,311 @@ func (s *SearchService) validateSearchQuery(query *entity.SearchQuery) error {
 		return errors.NewAppError(errors.ErrCodeValidationFailed, "From must be non-negative")
 	}
 
+	var fields errors.FieldErrors
+	if query.Index != "" && len(query.Indices()) != len(strings.Split(query.Index, ",")) {
+		fields.Add("index", "Index list cannot contain empty names")
+	}
+	if query.MinScore < 0 {
+		fields.Add("min_score", "Min score must be non-negative")
+	}
+	if query.Collapse != nil {
+		if query.Collapse.Field == "" {
+			fields.Add("collapse.field", "Collapse field is required")
+		}
+		if query.Collapse.InnerHitsSize < 0 {
+			fields.Add("collapse.inner_hits_size", "Inner hits size must be non-negative")
+		}
+	}
+	if query.Nested != nil {
+		if query.Nested.Path == "" {
+			fields.Add("nested.path", "Nested path is required")
+		}
+		if len(query.Nested.Must) == 0 && len(query.Nested.Filter) == 0 {
+			fields.Add("nested", "Nested query requires at least one must or filter clause")
+		}
+		if query.Nested.InnerHitsSize < 0 {
+			fields.Add("nested.inner_hits_size", "Inner hits size must be non-negative")
+		}
+	}
+	if query.FunctionScore != nil {
+		validateFunctionScore(query.FunctionScore, &fields)
+	}
+	if len(query.SearchAfter) > 0 && query.From > 0 {
+		fields.Add("from", "From must be 0 when search_after is set")
+	}
+	if !query.Mode.IsValid() {
+		fields.Add("mode", fmt.Sprintf("Unsupported search mode: %s", query.Mode))
+	}
+	if !entity.IsValidOperator(query.DefaultOperator) {
+		fields.Add("default_operator", "Default operator must be 'and' or 'or'")
+	}
+	if query.Analyzer != "" && !slices.Contains(s.config.AllowedAnalyzers, query.Analyzer) {
+		fields.Add("analyzer", fmt.Sprintf("Analyzer is not allowed: %s", query.Analyzer))
+	}
+
+	return fields.Err()
+}
+
+// numericFieldTypes lists the numeric mapping types
+var numericFieldTypes = map[string]bool{
+	"long":          true,
+	"integer":       true,
+	"short":         true,
+	"byte":          true,
+	"double":        true,
+	"float":         true,
+	"half_float":    true,
+	"scaled_float":  true,
+	"unsigned_long": true,
+}
+
+// dateFieldTypes lists the date mapping types
+var dateFieldTypes = map[string]bool{
+	"date":       true,
+	"date_nanos": true,
+}
+
+// collapsibleFieldTypes lists the mapping types that support field collapsing
+var collapsibleFieldTypes = map[string]bool{
+	"keyword":          true,
+	"constant_keyword": true,
+	"long":             true,
+	"integer":          true,
+	"short":            true,
+	"byte":             true,
+	"double":           true,
+	"float":            true,
+	"half_float":       true,
+	"scaled_float":     true,
+	"unsigned_long":    true,
+}
+
+// validateFunctionScore validates the shape of a function score configuration
+func validateFunctionScore(fs *entity.FunctionScore, fields *errors.FieldErrors) {
+	if fs.FieldValueFactor == nil && fs.Decay == nil {
+		fields.Add("function_score", "Function score requires field_value_factor or decay")
+	}
+	if !entity.IsValidBoostMode(fs.BoostMode) {
+		fields.Add("function_score.boost_mode", fmt.Sprintf("Unsupported boost mode: %s", fs.BoostMode))
+	}
+
+	if fvf := fs.FieldValueFactor; fvf != nil {
+		if fvf.Field == "" {
+			fields.Add("function_score.field_value_factor.field", "Field is required")
+		}
+		if fvf.Factor < 0 {
+			fields.Add("function_score.field_value_factor.factor", "Factor must be non-negative")
+		}
+		if !entity.IsValidFieldValueModifier(fvf.Modifier) {
+			fields.Add("function_score.field_value_factor.modifier", fmt.Sprintf("Unsupported modifier: %s", fvf.Modifier))
+		}
+	}
+
+	if decay := fs.Decay; decay != nil {
+		if !decay.Function.IsValid() {
+			fields.Add("function_score.decay.function", "Decay function must be 'gauss', 'linear' or 'exp'")
+		}
+		if decay.Field == "" {
+			fields.Add("function_score.decay.field", "Field is required")
+		}
+		if decay.Scale == "" {
+			fields.Add("function_score.decay.scale", "Scale is required")
+		}
+		if decay.Decay < 0 || decay.Decay >= 1 {
+			fields.Add("function_score.decay.decay", "Decay must be between 0 and 1")
+		}
+	}
+}
+
+// validateSearchOptions validates the request options passed to the search API
+func validateSearchOptions(options *repository.SearchOptions) error {
+	var fields errors.FieldErrors
+	if options.Timeout < 0 {
+		fields.Add("timeout", "Timeout must be non-negative")
+	}
+	for _, state := range options.ExpandWildcards {
+		if !entity.IsValidExpandWildcards(state) {
+			fields.Add("expand_wildcards", fmt.Sprintf("Unsupported expand_wildcards value: %s", state))
+		}
+	}
+	return fields.Err()
+}
+
+// validateFunctionScoreFields checks that the fields referenced by a function score
+// have a mapping type the function can operate on.
+// Fields that cannot be resolved are left for Elasticsearch to reject.
+func (s *SearchService) validateFunctionScoreFields(ctx context.Context, query *entity.SearchQuery) error {
+	if query.FunctionScore == nil || query.Index == "" {
+		return nil
+	}
+
+	var fields errors.FieldErrors
+	if fvf := query.FunctionScore.FieldValueFactor; fvf != nil {
+		fieldType, err := s.repo.GetFieldType(ctx, query.Index, fvf.Field)
+		switch {
+		case err != nil:
+		case fieldType == "" && fvf.Missing == nil:
+			fields.Add("function_score.field_value_factor.field", fmt.Sprintf("Field %s does not exist; set missing to score documents without it", fvf.Field))
+		case fieldType != "" && !numericFieldTypes[fieldType]:
+			fields.Add("function_score.field_value_factor.field", fmt.Sprintf("Field value factor requires a numeric field, got %s", fieldType))
+		}
+	}
+
+	if decay := query.FunctionScore.Decay; decay != nil {
+		fieldType, err := s.repo.GetFieldType(ctx, query.Index, decay.Field)
+		switch {
+		case err != nil || fieldType == "":
+		case !numericFieldTypes[fieldType] && !dateFieldTypes[fieldType] && fieldType != "geo_point":
+			fields.Add("function_score.decay.field", fmt.Sprintf("Decay requires a numeric, date or geo_point field, got %s", fieldType))
+		case numericFieldTypes[fieldType] && decay.Origin == "":
+			fields.Add("function_score.decay.origin", "Origin is required for numeric fields")
+		}
+	}
+
+	return fields.Err()
+}
+
+// validateCollapseField checks that the collapse field is a keyword (or numeric) field.
+// The check is skipped when the mapping cannot be resolved, e.g. for an unknown field or
+// a search across all indices; Elasticsearch then reports an invalid field itself.
+func (s *SearchService) validateCollapseField(ctx context.Context, query *entity.SearchQuery) error {
+	if query.Collapse == nil || query.Index == "" {
+		return nil
+	}
+
+	fieldType, err := s.repo.GetFieldType(ctx, query.Index, query.Collapse.Field)
+	if err != nil || fieldType == "" {
+		return nil
+	}
+
+	if !collapsibleFieldTypes[fieldType] {
+		return errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "collapse.field",
+			Message: fmt.Sprintf("Collapse field must be a keyword or numeric field, got %s", fieldType),
+		}})
+	}
+
 	return nil
 }
 
-// sanitizeQuery sanitizes a search query string
-func (s *SearchService) sanitizeQuery(query string) string {
-	// Remove potentially dangerous characters
-	query = strings.ReplaceAll(query, "<", "")
-	query = strings.ReplaceAll(query, ">", "")
-	query = strings.ReplaceAll(query, "\"", "\\\"")
+// validateSearchAsYouTypeField checks that the field exists and is mapped as search_as_you_type
+func (s *SearchService) validateSearchAsYouTypeField(ctx context.Context, index, field string) error {
+	fieldType, err := s.repo.GetFieldType(ctx, index, field)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return err
+		}
+		return wrapSearchError(err, "Failed to resolve suggest field")
+	}
+
+	switch fieldType {
+	case "search_as_you_type":
+		return nil
+	case "":
+		return errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "field",
+			Message: fmt.Sprintf("Field %s does not exist in %s", field, index),
+		}})
+	default:
+		return errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "field",
+			Message: fmt.Sprintf("Field must be a search_as_you_type field, got %s", fieldType),
+		}})
+	}
+}
+
+// wrapSearchError wraps a repository search error, keeping errors caused by the
+// query itself (such as query_string parse errors) so they surface as client errors
+func wrapSearchError(err error, message string) error {
+	if errors.HasCode(err, errors.ErrCodeInvalidQuery) {
+		return err
+	}
+	return errors.WrapError(err, errors.ErrCodeSearchFailed, message)
+}
+
+// sanitizeQuery sanitizes a search query string according to the search mode.
+//
+// In every mode control characters and the policy's StripChars are removed and
+// surrounding whitespace is trimmed. In match mode (the default) the string is sent as
+// the literal multi_match text and is never parsed as Lucene syntax, so no further
+// escaping is applied and characters such as quotes, "<" or ">" are preserved. In
+// query_string and simple_query_string modes the caller deliberately writes query syntax,
+// so operators are preserved unless the policy lists them in EscapeChars; leading
+// wildcards are rejected by the query builder and syntax errors are reported by
+// Elasticsearch.
+//
+// Stripping characters such as "<" and ">" is not a defense against XSS: the query is
+// only echoed back inside JSON responses, and any client that renders it (or hit
+// content) into HTML must encode it on output. The policy exists so that deployments
+// can reject characters their analyzers or indices cannot handle, not to make the
+// query safe to render.
+func (s *SearchService) sanitizeQuery(query string, mode entity.SearchMode) string {
+	policy := s.config.QuerySanitizePolicy
+
+	// Remove control characters and the characters the policy strips
+	query = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		if strings.ContainsRune(policy.StripChars, r) {
+			return -1
+		}
+		return r
+	}, query)
 
 	// Trim whitespace
 	query = strings.TrimSpace(query)
 
-	return query
+	switch mode {
+	case entity.SearchModeQueryString, entity.SearchModeSimpleQueryString:
+		return escapeChars(query, policy.EscapeChars)
+	default:
+		// Match and search-as-you-type queries are sent as literal multi_match text
+		return query
+	}
 }
 
-// isValidSortField checks if a field is valid for sorting
-func (s *SearchService) isValidSortField(field string) bool {
-	// Define allowed sort fields
-	allowedFields := map[string]bool{
-		"_score":     true,
-		"_id":        true,
-		"created_at": true,
-		"updated_at": true,
-		"name":       true,
-		"title":      true,
-		"date":       true,
-		"price":      true,
-		"rating":     true,
+// escapeChars prefixes every occurrence of the given characters with a backslash.
+// Backslashes are escaped too, so that a caller cannot cancel the escaping by
+// writing its own backslash before an escaped character.
+func escapeChars(text, chars string) string {
+	if chars == "" || !strings.ContainsAny(text, chars+`\`) {
+		return text
 	}
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if r == '\\' || strings.ContainsRune(chars, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
 
-	return allowedFields[field]
+// allowedSortFields lists the fields untrusted callers may sort by
+var allowedSortFields = map[string]bool{
+	"_score":     true,
+	"_id":        true,
+	"_doc":       true,
+	"@timestamp": true,
+	"created_at": true,
+	"updated_at": true,
+	"name":       true,
+	"title":      true,
+	"date":       true,
+	"price":      true,
+	"rating":     true,
 }
 
-// removeSensitiveFields removes sensitive fields from search results
-func (s *SearchService) removeSensitiveFields(source map[string]any) {
-	sensitiveFields := []string{
+// isValidSortField checks if a field is valid for sorting
+func (s *SearchService) isValidSortField(field string) bool {
+	return allowedSortFields[field]
+}
+
+// SensitiveFields returns the field names that must never be exposed in responses or logs
+func SensitiveFields() []string {
+	return []string{
 		"password",
 		"password_hash",
 		"secret",
@@ -371,30 +1139,46 @@ func (s *SearchService) removeSensitiveFields(source map[string]any) {
 		"ssn",
 		"credit_card",
 	}
+}
 
-	for _, field := range sensitiveFields {
+// isSensitiveField reports whether field is a sensitive field or one of its subfields
+func isSensitiveField(field string) bool {
+	for _, sensitive := range SensitiveFields() {
+		if field == sensitive || strings.HasPrefix(field, sensitive+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSensitiveFields removes sensitive fields from search results
+func (s *SearchService) removeSensitiveFields(source map[string]any) {
+	for _, field := range SensitiveFields() {
 		delete(source, field)
 	}
 }
 
-// addComputedFields adds computed fields to search results
-func (s *SearchService) addComputedFields(hit *entity.Hit) error {
-	if hit.Source == nil {
-		return nil
+// removeSensitiveSubfields removes entries keyed by a sensitive field or one of its subfields,
+// such as highlighted fragments or stored field values
+func removeSensitiveSubfields[V any](values map[string]V) {
+	for field := range values {
+		if isSensitiveField(field) {
+			delete(values, field)
+		}
 	}
+}
 
-	// Add a computed field showing the match score category
+// addComputedFields sets computed fields on the hit itself, leaving the document source untouched
+func (s *SearchService) addComputedFields(hit *entity.Hit) error {
+	// Categorize the match score
 	if hit.Score >= 0.8 {
-		hit.Source["_match_quality"] = "high"
+		hit.MatchQuality = "high"
 	} else if hit.Score >= 0.5 {
-		hit.Source["_match_quality"] = "medium"
+		hit.MatchQuality = "medium"
 	} else {
-		hit.Source["_match_quality"] = "low"
+		hit.MatchQuality = "low"
 	}
 
-	// Add index metadata
-	hit.Source["_source_index"] = hit.Index
-
 	return nil
 }
 
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+// searchResultCache は検索結果を一定期間キャッシュする
+// キャッシュした結果は呼び出し元の間で共有するため、取得した結果を変更してはならない
+type searchResultCache struct {
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]searchResultEntry
+}
+
+// searchResultEntry はキャッシュした検索結果と有効期限を表す
+type searchResultEntry struct {
+	result  *entity.SearchResult
+	expires time.Time
+}
+
+// newSearchResultCache は新しい searchResultCache を作成する
+func newSearchResultCache(ttl time.Duration, maxEntries int) *searchResultCache {
+	return &searchResultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]searchResultEntry),
+	}
+}
+
+// get は有効期限内のキャッシュがあればその結果を返す
+func (c *searchResultCache) get(key string) (*entity.SearchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// set は結果をキャッシュする（TTLが0の場合はキャッシュしない）
+// 上限に達している場合は期限切れのエントリを削除し、それでも空きがなければキャッシュしない
+func (c *searchResultCache) set(key string, result *entity.SearchResult) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = searchResultEntry{result: result, expires: time.Now().Add(c.ttl)}
+}
+
+// clear は match に一致するキーを削除し、削除した件数を返す（match が nil の場合はすべて削除する）
+func (c *searchResultCache) clear(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evicted := 0
+	for key := range c.entries {
+		if match == nil || match(key) {
+			delete(c.entries, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// searchResultCacheKey は検索結果のキャッシュのキー（"テナント/インデックス/正規化したクエリ/検索オプション"）を返す
+// テナントごとに検索できるドキュメントが異なるため、同じクエリでもテナントが違えば別のエントリとする
+func searchResultCacheKey(ctx context.Context, query *entity.SearchQuery, options *repository.SearchOptions) string {
+	indices := query.Indices()
+	slices.Sort(indices)
+	opts, _ := json.Marshal(options) // 文字列・真偽値・数値のみのため失敗しない
+	return auth.Tenant(ctx) + "/" + strings.Join(indices, ",") + "/" + query.CanonicalKey() + "/" + string(opts)
+}
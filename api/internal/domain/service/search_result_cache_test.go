@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+// countingSearchRepository は検索の呼び出し回数を数えるリポジトリ
+type countingSearchRepository struct {
+	resultWindowRepository
+	searches int
+}
+
+func (r *countingSearchRepository) Search(_ context.Context, query *entity.SearchQuery, _ ...repository.SearchOption) (*entity.SearchResult, error) {
+	r.searches++
+	return entity.NewSearchResult(*query), nil
+}
+
+func TestExecuteSearchResultCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		ttl          time.Duration
+		second       func(q *entity.SearchQuery)
+		secondTenant string
+		wantSearches int
+	}{
+		{name: "same query", ttl: time.Minute, second: func(q *entity.SearchQuery) {}, wantSearches: 1},
+		{name: "reordered indices and filters", ttl: time.Minute, second: func(q *entity.SearchQuery) {
+			q.Index = "blogs,articles"
+			q.Filters = map[string]string{"status": "published", "category": "tech"}
+		}, wantSearches: 1},
+		{name: "different query", ttl: time.Minute, second: func(q *entity.SearchQuery) { q.Query = "rust" }, wantSearches: 2},
+		{name: "different tenant", ttl: time.Minute, second: func(q *entity.SearchQuery) {}, secondTenant: "other", wantSearches: 2},
+		{name: "profile", ttl: time.Minute, second: func(q *entity.SearchQuery) { q.Profile = true }, wantSearches: 2},
+		{name: "disabled", second: func(q *entity.SearchQuery) {}, wantSearches: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &countingSearchRepository{}
+			config := DefaultSearchConfig()
+			config.ResultCacheTTL = tt.ttl
+			s := NewSearchServiceWithConfig(repo, config)
+
+			newQuery := func() *entity.SearchQuery {
+				query := entity.NewSearchQuery("golang")
+				query.Index = "articles,blogs"
+				query.Filters = map[string]string{"category": "tech", "status": "published"}
+				return query
+			}
+
+			ctx := auth.WithTenant(context.Background(), "acme")
+			if _, err := s.ExecuteSearch(ctx, newQuery()); err != nil {
+				t.Fatalf("ExecuteSearch() error = %v", err)
+			}
+
+			second := newQuery()
+			tt.second(second)
+			if tt.secondTenant != "" {
+				ctx = auth.WithTenant(context.Background(), tt.secondTenant)
+			}
+			result, err := s.ExecuteSearch(auth.WithTrusted(ctx), second)
+			if err != nil {
+				t.Fatalf("ExecuteSearch() error = %v", err)
+			}
+
+			if repo.searches != tt.wantSearches {
+				t.Errorf("repository searches = %d, want %d", repo.searches, tt.wantSearches)
+			}
+			if result.Query.Index != second.Index {
+				t.Errorf("result query index = %q, want %q", result.Query.Index, second.Index)
+			}
+		})
+	}
+}
+
+func TestClearCachesSearchResult(t *testing.T) {
+	repo := &countingSearchRepository{}
+	config := DefaultSearchConfig()
+	config.ResultCacheTTL = time.Minute
+	s := NewSearchServiceWithConfig(repo, config)
+
+	for _, index := range []string{"articles", "blogs"} {
+		query := entity.NewSearchQuery("golang")
+		query.Index = index
+		if _, err := s.ExecuteSearch(context.Background(), query); err != nil {
+			t.Fatalf("ExecuteSearch() error = %v", err)
+		}
+	}
+
+	if got := s.ClearCaches("articles")[CacheSearchResult]; got != 1 {
+		t.Errorf("ClearCaches(articles) evicted %d search results, want 1", got)
+	}
+	if got := s.ClearCaches("")[CacheSearchResult]; got != 1 {
+		t.Errorf("ClearCaches() evicted %d search results, want 1", got)
+	}
+}
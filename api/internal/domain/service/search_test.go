@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// resultWindowRepository は max_result_window のみを返すリポジトリ
+// 検索のビジネスルールの適用では他のメソッドは呼ばれない
+type resultWindowRepository struct {
+	repository.ElasticsearchRepository
+}
+
+func (resultWindowRepository) MaxResultWindow(context.Context, string) (int, error) {
+	return DefaultMaxResultWindow, nil
+}
+
+func TestApplySearchBusinessRulesCursorTiebreaker(t *testing.T) {
+	tests := []struct {
+		name       string
+		tiebreaker string
+		sort       []entity.SortField
+		wantSort   []string
+		wantUnique bool
+	}{
+		{
+			name:       "default relevance sort without tiebreaker",
+			wantSort:   []string{"_score"},
+			wantUnique: false,
+		},
+		{
+			name:       "sort ending with _id",
+			sort:       []entity.SortField{{Field: "price", Order: "asc"}, {Field: "_id", Order: "asc"}},
+			wantSort:   []string{"price", "_id"},
+			wantUnique: true,
+		},
+		{
+			name:       "tiebreaker appended to relevance sort",
+			tiebreaker: "doc_key",
+			wantSort:   []string{"_score", "doc_key"},
+			wantUnique: true,
+		},
+		{
+			name:       "tiebreaker appended to field sort",
+			tiebreaker: "doc_key",
+			sort:       []entity.SortField{{Field: "price", Order: "desc"}},
+			wantSort:   []string{"price", "doc_key"},
+			wantUnique: true,
+		},
+		{
+			name:       "tiebreaker not appended after _id",
+			tiebreaker: "doc_key",
+			sort:       []entity.SortField{{Field: "_id", Order: "asc"}},
+			wantSort:   []string{"_id"},
+			wantUnique: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultSearchConfig()
+			config.CursorTiebreaker = tt.tiebreaker
+			s := NewSearchServiceWithConfig(resultWindowRepository{}, config)
+
+			query := entity.NewSearchQuery("golang")
+			query.Index = "articles"
+			query.Sort = tt.sort
+			if err := s.applySearchBusinessRules(context.Background(), query); err != nil {
+				t.Fatalf("applySearchBusinessRules() error = %v", err)
+			}
+
+			var got []string
+			for _, sort := range query.Sort {
+				got = append(got, sort.Field)
+			}
+			if len(got) != len(tt.wantSort) {
+				t.Fatalf("sort = %v, want %v", got, tt.wantSort)
+			}
+			for i := range got {
+				if got[i] != tt.wantSort[i] {
+					t.Fatalf("sort = %v, want %v", got, tt.wantSort)
+				}
+			}
+			if query.UniqueSort != tt.wantUnique {
+				t.Errorf("UniqueSort = %v, want %v", query.UniqueSort, tt.wantUnique)
+			}
+		})
+	}
+}
+
+func TestApplySearchBusinessRulesRecordsAppliedRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		debug     bool
+		modify    func(q *entity.SearchQuery)
+		wantRules []string
+	}{
+		{
+			name:      "defaults",
+			debug:     true,
+			modify:    func(q *entity.SearchQuery) {},
+			wantRules: []string{"default_fields", "default_operator", "default_size", "default_sort"},
+		},
+		{
+			name:  "clamped size, normalized sort and facets",
+			debug: true,
+			modify: func(q *entity.SearchQuery) {
+				q.Fields = []string{"title"}
+				q.DefaultOperator = "and"
+				q.Size = 5000
+				q.Sort = []entity.SortField{{Field: "price", Order: "DESC"}}
+				q.Filters["_facets"] = "category"
+			},
+			wantRules: []string{"size_clamped", "sort_order_normalized", "facets"},
+		},
+		{
+			name:      "sanitized query",
+			debug:     true,
+			modify:    func(q *entity.SearchQuery) { q.Query = " golang\x00 " },
+			wantRules: []string{"query_sanitized", "default_fields", "default_operator", "default_size", "default_sort"},
+		},
+		{
+			name:   "debug off",
+			modify: func(q *entity.SearchQuery) { q.Size = 5000 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSearchServiceWithConfig(resultWindowRepository{}, DefaultSearchConfig())
+
+			query := entity.NewSearchQuery("golang")
+			query.Index = "articles"
+			query.Size = 0 // リクエストでサイズを指定しなかった場合
+			query.Debug = tt.debug
+			tt.modify(query)
+			if err := s.applySearchBusinessRules(context.Background(), query); err != nil {
+				t.Fatalf("applySearchBusinessRules() error = %v", err)
+			}
+
+			var got []string
+			for _, rule := range query.AppliedRules {
+				got = append(got, rule.Rule)
+				if rule.Description == "" {
+					t.Errorf("rule %s has no description", rule.Rule)
+				}
+			}
+			if !slices.Equal(got, tt.wantRules) {
+				t.Errorf("applied rules = %v, want %v", got, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestValidateSearchQueryAnalyzer(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		analyzer string
+		wantErr  bool
+	}{
+		{name: "no analyzer", allowed: nil},
+		{name: "allowed analyzer", allowed: []string{"standard", "kuromoji"}, analyzer: "kuromoji"},
+		{name: "analyzer outside the allowlist", allowed: []string{"standard"}, analyzer: "kuromoji", wantErr: true},
+		{name: "empty allowlist rejects overrides", analyzer: "standard", wantErr: true},
+		{name: "names are case-sensitive", allowed: []string{"standard"}, analyzer: "Standard", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultSearchConfig()
+			config.AllowedAnalyzers = tt.allowed
+			s := NewSearchServiceWithConfig(resultWindowRepository{}, config)
+
+			query := entity.NewSearchQuery("golang")
+			query.Index = "articles"
+			query.Analyzer = tt.analyzer
+			err := s.validateSearchQuery(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateSearchQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				appErr := errors.GetAppError(err)
+				if appErr == nil || len(appErr.Fields) != 1 || appErr.Fields[0].Field != "analyzer" {
+					t.Errorf("validateSearchQuery() error = %v, want a field error on analyzer", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// DefaultTermsSize はフィールドの値ごとの件数集計で返す値の数のデフォルト
+const DefaultTermsSize = 10
+
+// MaxTermsSize はフィールドの値ごとの件数集計で返す値の数の上限
+const MaxTermsSize = 1000
+
+// CountByField はフィールドの値ごとのドキュメント数を多い順に size 件まで集計する
+// フィールドは keyword や数値など集約に使える型である必要がある（fielddata のない text は不可）
+func (s *SearchService) CountByField(ctx context.Context, index, field string, size int) (*entity.TermsResult, error) {
+	// 入力を検証
+	var fields errors.FieldErrors
+	if field == "" {
+		fields.Add("field", "Field is required")
+	} else if isSensitiveField(field) {
+		fields.Add("field", fmt.Sprintf("Field %s cannot be aggregated", field))
+	}
+	if size == 0 {
+		size = DefaultTermsSize
+	}
+	if size < 0 || size > MaxTermsSize {
+		fields.Add("size", fmt.Sprintf("Size must be between 1 and %d", MaxTermsSize))
+	}
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+
+	index, err := s.resolveIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	// 集約に使えるフィールドかどうかを確認する
+	if err := s.validateAggregatableField(ctx, index, field); err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.TermsAggregation(ctx, index, field, size)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) {
+			return nil, err
+		}
+		return nil, wrapSearchError(err, "Terms aggregation failed")
+	}
+
+	return result, nil
+}
+
+// validateAggregatableField はフィールドが存在し、terms 集約に使えることを確認する
+// text フィールドは fielddata を有効にした場合のみ集約できるため、keyword のサブフィールドを使うよう案内する
+func (s *SearchService) validateAggregatableField(ctx context.Context, index, field string) error {
+	capability, err := s.repo.FieldCapabilities(ctx, index, field)
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeIndexNotFound) || errors.HasCode(err, errors.ErrCodeForbidden) {
+			return err
+		}
+		return wrapSearchError(err, "Failed to resolve field capabilities")
+	}
+
+	switch {
+	case capability == nil:
+		return errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "field",
+			Message: fmt.Sprintf("Field %s does not exist in %s", field, index),
+		}})
+	case capability.Aggregatable:
+		return nil
+	case capability.HasType("text"):
+		return errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "field",
+			Message: fmt.Sprintf("Field %s is a text field and cannot be aggregated without fielddata; use a keyword subfield such as %s.keyword", field, field),
+		}})
+	default:
+		return errors.NewFieldValidationError([]errors.FieldError{{
+			Field:   "field",
+			Message: fmt.Sprintf("Field %s (%v) is not aggregatable; use a keyword, numeric, date or boolean field", field, capability.Types),
+		}})
+	}
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+// JSONLogger は監査イベントを1行1件のJSONとして書き込む AuditLogger の実装
+type JSONLogger struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLogger は w に書き込む新しい JSONLogger を作成する
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Record は監査イベントを書き込む（書き込みエラーは変更操作に影響させないため無視する）
+func (l *JSONLogger) Record(_ context.Context, event entity.AuditEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_ = l.encoder.Encode(event)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/config"
@@ -15,10 +16,14 @@ import (
 	"github.com/elastic/go-elasticsearch/v9"
 )
 
+// PrimaryCluster is the name of the cluster configured by ELASTICSEARCH_URL, which serves all requests
+const PrimaryCluster = "primary"
+
 // Client wraps the Elasticsearch client with additional functionality
 type Client struct {
 	es     *elasticsearch.Client
 	config *config.Config
+	name   string
 }
 
 // ClientConfig represents the configuration for the Elasticsearch client
@@ -34,7 +39,7 @@ type ClientConfig struct {
 	RetryOnTimeout         bool
 	EnableMetrics          bool
 	EnableDebugLogger      bool
-	EnableCompression      bool
+	EnableCompression      bool // Request gzip-compressed responses (Accept-Encoding: gzip)
 	MaxIdleConnsPerHost    int
 	ResponseHeaderTimeout  time.Duration
 	RequestTimeout         time.Duration
@@ -46,14 +51,31 @@ type ClientConfig struct {
 	EnableRetryOnTimeout   bool
 	DisableRetry           bool
 	UseResponseCheckOnly   bool
-	CompressRequestBody    bool
+	CompressRequestBody    bool // Gzip request bodies (Content-Encoding: gzip)
 }
 
-// NewClient creates a new Elasticsearch client
+// NewClient creates a new Elasticsearch client for the primary cluster
 func NewClient(conf *config.Config) (*Client, error) {
+	client, err := NewClusterClient(conf, PrimaryCluster, []string{conf.ElasticsearchURL})
+	if err != nil {
+		return nil, err
+	}
+
+	// Test the connection
+	if err := client.ping(); err != nil {
+		return nil, errors.NewElasticsearchConnectionError(err)
+	}
+
+	return client, nil
+}
+
+// NewClusterClient creates a named Elasticsearch client for the given endpoint set.
+// Unlike NewClient it does not test the connection, so an unreachable secondary cluster
+// does not prevent startup; its status is reported by health checks instead.
+func NewClusterClient(conf *config.Config, name string, addresses []string) (*Client, error) {
 	// Create Elasticsearch configuration
 	esConfig := elasticsearch.Config{
-		Addresses: []string{conf.ElasticsearchURL},
+		Addresses: addresses,
 
 		// Transport configuration
 		Transport: &http.Transport{
@@ -62,8 +84,14 @@ func NewClient(conf *config.Config) (*Client, error) {
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: false,
 			},
+			// Without DisableCompression the transport asks for gzip and decompresses transparently
+			DisableCompression: !conf.ElasticsearchCompressResponses,
 		},
 
+		// Compression configuration
+		CompressRequestBody: conf.ElasticsearchCompressRequests,
+		PoolCompressor:      conf.ElasticsearchCompressRequests,
+
 		// Retry configuration
 		RetryOnStatus: []int{502, 503, 504, 429},
 		RetryBackoff: func(i int) time.Duration {
@@ -86,17 +114,39 @@ func NewClient(conf *config.Config) (*Client, error) {
 		return nil, errors.NewElasticsearchConnectionError(err)
 	}
 
-	client := &Client{
+	return &Client{
 		es:     es,
 		config: conf,
+		name:   name,
+	}, nil
+}
+
+// NewClusterClients creates the primary client followed by a client for each additional
+// cluster (name to addresses), ordered by name
+func NewClusterClients(conf *config.Config, clusters map[string][]string) ([]*Client, error) {
+	primary, err := NewClient(conf)
+	if err != nil {
+		return nil, err
 	}
 
-	// Test the connection
-	if err := client.ping(); err != nil {
-		return nil, errors.NewElasticsearchConnectionError(err)
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		if name == PrimaryCluster {
+			return nil, fmt.Errorf("cluster name %q is reserved for ELASTICSEARCH_URL", PrimaryCluster)
+		}
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return client, nil
+	clients := []*Client{primary}
+	for _, name := range names {
+		client, err := NewClusterClient(conf, name, clusters[name])
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", name, err)
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
 }
 
 // NewClientWithConfig creates a new Elasticsearch client with custom configuration
@@ -116,8 +166,13 @@ func NewClientWithConfig(clientConfig *ClientConfig) (*Client, error) {
 			TLSClientConfig: &tls.Config{
 				InsecureSkipVerify: false,
 			},
+			DisableCompression: !clientConfig.EnableCompression,
 		},
 
+		// Compression configuration
+		CompressRequestBody: clientConfig.CompressRequestBody,
+		PoolCompressor:      clientConfig.CompressRequestBody,
+
 		// Retry configuration
 		RetryOnStatus: clientConfig.RetryOnStatus,
 		RetryBackoff: func(i int) time.Duration {
@@ -152,6 +207,14 @@ func NewClientWithConfig(clientConfig *ClientConfig) (*Client, error) {
 	return client, nil
 }
 
+// Name returns the cluster name of the client (PrimaryCluster unless created by NewClusterClient)
+func (c *Client) Name() string {
+	if c.name == "" {
+		return PrimaryCluster
+	}
+	return c.name
+}
+
 // GetClient returns the underlying Elasticsearch client
 func (c *Client) GetClient() *elasticsearch.Client {
 	return c.es
@@ -229,6 +292,30 @@ func (c *Client) Health(ctx context.Context) (map[string]any, error) {
 	return health, nil
 }
 
+// IndexHealth returns the health status of a specific index
+func (c *Client) IndexHealth(ctx context.Context, index string) (map[string]any, error) {
+	res, err := c.es.Cluster.Health(
+		c.es.Cluster.Health.WithContext(ctx),
+		c.es.Cluster.Health.WithIndex(pathIndex(index)),
+		c.es.Cluster.Health.WithLevel("indices"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index health: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("index health request failed with status: %s", res.Status())
+	}
+
+	var health map[string]any
+	if err := c.parseResponse(res.Body, &health); err != nil {
+		return nil, fmt.Errorf("failed to parse index health response: %w", err)
+	}
+
+	return health, nil
+}
+
 // Stats returns cluster statistics
 func (c *Client) Stats(ctx context.Context) (map[string]any, error) {
 	res, err := c.es.Cluster.Stats(
@@ -354,9 +441,15 @@ func (c *Client) GetConfig() *config.Config {
 	return c.config
 }
 
-// parseResponse is a helper function to parse JSON response
+// parseResponse is a helper function to parse JSON response.
+// Unless disabled by config, numbers are decoded as json.Number so that large integers
+// (versions, counts, numeric document fields) keep their precision.
 func (c *Client) parseResponse(body io.Reader, v any) error {
-	return json.NewDecoder(body).Decode(v)
+	decoder := json.NewDecoder(body)
+	if c.config == nil || c.config.ElasticsearchUseNumber {
+		decoder.UseNumber()
+	}
+	return decoder.Decode(v)
 }
 
 // DefaultClientConfig returns a default client configuration
@@ -368,7 +461,7 @@ func DefaultClientConfig() *ClientConfig {
 		RetryOnTimeout:        true,
 		EnableMetrics:         true,
 		EnableDebugLogger:     false,
-		EnableCompression:     false,
+		EnableCompression:     true,
 		MaxIdleConnsPerHost:   10,
 		ResponseHeaderTimeout: 10 * time.Second,
 		RequestTimeout:        30 * time.Second,
@@ -0,0 +1,160 @@
+package elasticsearch
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/config"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+)
+
+// compressionRecorder は _bulk リクエストのヘッダーと展開したボディを記録する
+type compressionRecorder struct {
+	contentEncoding string
+	acceptEncoding  string
+	body            string
+}
+
+func (rec *compressionRecorder) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+			writeResponse(w, http.StatusOK, `{}`)
+			return
+		}
+
+		rec.contentEncoding = r.Header.Get("Content-Encoding")
+		rec.acceptEncoding = r.Header.Get("Accept-Encoding")
+		var body io.Reader = r.Body
+		if rec.contentEncoding == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("gzip.NewReader() error = %v", err)
+				writeResponse(w, http.StatusBadRequest, `{}`)
+				return
+			}
+			body = gz
+		}
+		data, _ := io.ReadAll(body)
+		rec.body = string(data)
+		writeResponse(w, http.StatusOK, `{"took":1,"errors":false,"items":[]}`)
+	}
+}
+
+func TestNewClusterClientCompression(t *testing.T) {
+	tests := []struct {
+		name                string
+		conf                config.Config
+		wantContentEncoding string
+		wantAcceptEncoding  string
+	}{
+		{name: "disabled"},
+		{name: "requests", conf: config.Config{ElasticsearchCompressRequests: true}, wantContentEncoding: "gzip"},
+		{name: "responses", conf: config.Config{ElasticsearchCompressResponses: true}, wantAcceptEncoding: "gzip"},
+		{name: "both", conf: config.Config{ElasticsearchCompressRequests: true, ElasticsearchCompressResponses: true}, wantContentEncoding: "gzip", wantAcceptEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &compressionRecorder{}
+			repo := NewRepository(newTestClient(t, &tt.conf, rec.handler(t)))
+
+			doc := entity.NewDocument("articles", map[string]any{"title": "golang"})
+			if _, err := repo.BulkIndex(context.Background(), []*entity.Document{doc}, entity.BulkOpIndex); err != nil {
+				t.Fatalf("BulkIndex() error = %v", err)
+			}
+
+			if rec.contentEncoding != tt.wantContentEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", rec.contentEncoding, tt.wantContentEncoding)
+			}
+			if rec.acceptEncoding != tt.wantAcceptEncoding {
+				t.Errorf("Accept-Encoding = %q, want %q", rec.acceptEncoding, tt.wantAcceptEncoding)
+			}
+			if !strings.Contains(rec.body, `"title":"golang"`) {
+				t.Errorf("bulk body = %q, want the document source", rec.body)
+			}
+		})
+	}
+}
+
+func TestNewClientWithConfigCompression(t *testing.T) {
+	rec := &compressionRecorder{}
+	server := httptest.NewServer(rec.handler(t))
+	defer server.Close()
+
+	clientConfig := DefaultClientConfig()
+	clientConfig.URLs = []string{server.URL}
+	clientConfig.CompressRequestBody = true
+	client, err := NewClientWithConfig(clientConfig)
+	if err != nil {
+		t.Fatalf("NewClientWithConfig() error = %v", err)
+	}
+
+	res, err := client.es.Bulk(strings.NewReader(`{"index":{"_index":"articles"}}`+"\n"+`{"title":"golang"}`+"\n"), client.es.Bulk.WithContext(context.Background()))
+	if err != nil {
+		t.Fatalf("Bulk() error = %v", err)
+	}
+	res.Body.Close()
+
+	if rec.contentEncoding != "gzip" || rec.acceptEncoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, Accept-Encoding = %q, want gzip for both", rec.contentEncoding, rec.acceptEncoding)
+	}
+	if !strings.Contains(rec.body, `"title":"golang"`) {
+		t.Errorf("bulk body = %q, want the document source", rec.body)
+	}
+}
+
+// BenchmarkBulkIndexCompression は低速な回線（10MB/s）を模したクラスターへの一括登録で、リクエストの圧縮の有無を比較する
+// サーバーは受信したバイト数に比例して待機するため、圧縮による転送量の削減が所要時間に表れる
+func BenchmarkBulkIndexCompression(b *testing.B) {
+	const bytesPerSecond = 10 << 20
+
+	// 同じ文の繰り返しでは圧縮率が実際より高くなるため、語彙からランダムに選んだ単語で本文を作る
+	words := strings.Fields("search index document cluster shard replica query filter score field mapping analyzer token node refresh bulk golang server request response")
+	rng := rand.New(rand.NewPCG(1, 2))
+	docs := make([]*entity.Document, 500)
+	for i := range docs {
+		body := make([]string, 80)
+		for j := range body {
+			body[j] = words[rng.IntN(len(words))]
+		}
+		docs[i] = entity.NewDocument("articles", map[string]any{
+			"title":      fmt.Sprintf("Article %d", i),
+			"body":       strings.Join(body, " "),
+			"views":      rng.IntN(100000),
+			"created_at": time.Unix(1700000000+rng.Int64N(1e7), 0).UTC().Format(time.RFC3339),
+		})
+	}
+
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compress=%t", compress), func(b *testing.B) {
+			var wireBytes atomic.Int64
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				n, _ := io.Copy(io.Discard, r.Body)
+				if strings.HasSuffix(r.URL.Path, "/_bulk") {
+					wireBytes.Add(n)
+					time.Sleep(time.Duration(n) * time.Second / bytesPerSecond)
+				}
+				writeResponse(w, http.StatusOK, `{"took":1,"errors":false,"items":[]}`)
+			}
+			repo := NewRepository(newTestClient(b, &config.Config{ElasticsearchCompressRequests: compress}, handler))
+
+			requests := 0
+			for b.Loop() {
+				if _, err := repo.BulkIndex(context.Background(), docs, entity.BulkOpIndex); err != nil {
+					b.Fatal(err)
+				}
+				requests++
+			}
+			b.ReportMetric(float64(wireBytes.Load())/float64(requests), "wire-B/op")
+		})
+	}
+}
@@ -5,10 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
 	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
 	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+	"github.com/elastic/go-elasticsearch/v9/esapi"
 )
 
 // Repository はElasticsearchRepositoryインターフェースを実装する
@@ -25,19 +33,37 @@ func NewRepository(client *Client) repository.ElasticsearchRepository {
 
 // CreateDocument はElasticsearchに新しいドキュメントを作成する
 func (r *Repository) CreateDocument(ctx context.Context, doc *entity.Document) error {
-	// ドキュメントをJSONに変換
-	body, err := json.Marshal(doc.Source)
-	if err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to marshal document")
+	// ドキュメントをJSONに変換（未加工のJSONの場合はそのまま送信する）
+	var body []byte
+	if doc.RawSource != nil {
+		body = doc.RawSource.Bytes()
+	} else {
+		var err error
+		body, err = json.Marshal(doc.Source)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to marshal document")
+		}
 	}
 
 	// ドキュメントを作成
-	res, err := r.client.es.Index(
-		doc.Index,
-		bytes.NewReader(body),
+	options := []func(*esapi.IndexRequest){
 		r.client.es.Index.WithContext(ctx),
 		r.client.es.Index.WithDocumentID(doc.ID),
-		r.client.es.Index.WithRefresh("true"),
+	}
+	if doc.Refresh != "" {
+		options = append(options, r.client.es.Index.WithRefresh(string(doc.Refresh)))
+	}
+	if doc.Routing != "" {
+		options = append(options, r.client.es.Index.WithRouting(doc.Routing))
+	}
+	if doc.Pipeline != "" {
+		options = append(options, r.client.es.Index.WithPipeline(doc.Pipeline))
+	}
+	options = append(options, r.externalVersionOptions(doc)...)
+	res, err := r.client.es.Index(
+		pathIndex(doc.Index),
+		bytes.NewReader(body),
+		options...,
 	)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to index document")
@@ -45,12 +71,18 @@ func (r *Repository) CreateDocument(ctx context.Context, doc *entity.Document) e
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		if res.StatusCode == 409 && doc.ExternalVersion > 0 {
+			return errors.NewVersionConflictError(doc.Index, doc.ID, doc.ExternalVersion)
+		}
 		return errors.NewAppError(errors.ErrCodeDocumentCreateFailed, fmt.Sprintf("Document indexing failed with status: %s", res.Status()))
 	}
 
 	// レスポンスを解析してドキュメントIDを取得
 	var result map[string]any
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
 		return errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to parse index response")
 	}
 
@@ -58,16 +90,35 @@ func (r *Repository) CreateDocument(ctx context.Context, doc *entity.Document) e
 	if id, ok := result["_id"].(string); ok {
 		doc.SetID(id)
 	}
+	setConcurrencyFields(doc, result)
 
 	return nil
 }
 
+// externalVersionOptions はドキュメントに外部バージョンがある場合のインデックスオプションを返す
+func (r *Repository) externalVersionOptions(doc *entity.Document) []func(*esapi.IndexRequest) {
+	if doc.ExternalVersion <= 0 {
+		return nil
+	}
+	return []func(*esapi.IndexRequest){
+		r.client.es.Index.WithVersion(int(doc.ExternalVersion)),
+		r.client.es.Index.WithVersionType("external"),
+	}
+}
+
 // GetDocument はIDでドキュメントを取得する
-func (r *Repository) GetDocument(ctx context.Context, index, id string) (*entity.Document, error) {
+// ルーティング値を指定せずにカスタムルーティングされたドキュメントを取得すると見つからない
+func (r *Repository) GetDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) (*entity.Document, error) {
+	options := []func(*esapi.GetRequest){
+		r.client.es.Get.WithContext(ctx),
+	}
+	if routing := repository.NewDocumentOptions(opts...).Routing; routing != "" {
+		options = append(options, r.client.es.Get.WithRouting(routing))
+	}
 	res, err := r.client.es.Get(
-		index,
+		pathIndex(index),
 		id,
-		r.client.es.Get.WithContext(ctx),
+		options...,
 	)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Failed to get document")
@@ -75,6 +126,9 @@ func (r *Repository) GetDocument(ctx context.Context, index, id string) (*entity
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
 		if res.StatusCode == 404 {
 			return nil, errors.NewDocumentNotFoundError(index, id)
 		}
@@ -83,74 +137,239 @@ func (r *Repository) GetDocument(ctx context.Context, index, id string) (*entity
 
 	// レスポンスを解析
 	var result map[string]any
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Failed to parse get response")
 	}
 
-	// ドキュメントデータを抽出
-	source, ok := result["_source"].(map[string]any)
-	if !ok {
-		return nil, errors.NewAppError(errors.ErrCodeDocumentNotFound, "Invalid document format")
+	// ドキュメントデータを抽出（_source がない場合も見つかったドキュメントとして空のソースで返す）
+	source := getSource(result)
+	if source == nil {
+		source = map[string]any{}
 	}
 
 	// ドキュメントエンティティを作成
 	doc := entity.NewDocument(index, source)
 	doc.SetID(id)
+	doc.Routing = getString(result, "_routing")
 
 	// バージョンが利用可能な場合は設定
-	if version, ok := result["_version"].(float64); ok {
-		doc.Version = int64(version)
+	if version, ok := getInt64(result, "_version"); ok {
+		doc.Version = version
 	}
+	setConcurrencyFields(doc, result)
 
 	return doc, nil
 }
 
+// GetDocumentRaw はドキュメントの_sourceをデコードせずにストリームとして返す
+// 呼び出し元は返されたストリームをCloseする必要がある
+func (r *Repository) GetDocumentRaw(ctx context.Context, index, id string, opts ...repository.DocumentOption) (io.ReadCloser, error) {
+	options := []func(*esapi.GetSourceRequest){
+		r.client.es.GetSource.WithContext(ctx),
+	}
+	if routing := repository.NewDocumentOptions(opts...).Routing; routing != "" {
+		options = append(options, r.client.es.GetSource.WithRouting(routing))
+	}
+	res, err := r.client.es.GetSource(
+		pathIndex(index),
+		id,
+		options...,
+	)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentNotFound, "Failed to get document source")
+	}
+
+	if res.IsError() {
+		defer res.Body.Close()
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			return nil, errors.NewDocumentNotFoundError(index, id)
+		}
+		return nil, errors.NewAppError(errors.ErrCodeDocumentNotFound, fmt.Sprintf("Document source retrieval failed with status: %s", res.Status()))
+	}
+
+	return res.Body, nil
+}
+
 // UpdateDocument は既存のドキュメントを更新する
 func (r *Repository) UpdateDocument(ctx context.Context, doc *entity.Document) error {
+	_, err := r.updateDocument(ctx, doc)
+	return err
+}
+
+// ReplaceDocument はドキュメントを作成または置き換え、新規作成された場合に true を返す
+func (r *Repository) ReplaceDocument(ctx context.Context, doc *entity.Document) (bool, error) {
+	return r.updateDocument(ctx, doc)
+}
+
+// UpdateDocumentIfMatch はシーケンス番号とプライマリタームが一致する場合のみドキュメントを更新する
+func (r *Repository) UpdateDocumentIfMatch(ctx context.Context, doc *entity.Document, seqNo, primaryTerm int64) error {
+	_, err := r.updateDocument(ctx, doc,
+		r.client.es.Index.WithIfSeqNo(int(seqNo)),
+		r.client.es.Index.WithIfPrimaryTerm(int(primaryTerm)),
+	)
+	return err
+}
+
+// updateDocument は追加オプション付きでドキュメントを更新し、新規作成された場合に true を返す
+func (r *Repository) updateDocument(ctx context.Context, doc *entity.Document, opts ...func(*esapi.IndexRequest)) (bool, error) {
 	// ドキュメントをJSONに変換
 	body, err := json.Marshal(doc.Source)
 	if err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to marshal document")
+		return false, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to marshal document")
 	}
 
 	// ドキュメントを更新
-	res, err := r.client.es.Index(
-		doc.Index,
-		bytes.NewReader(body),
+	options := append([]func(*esapi.IndexRequest){
 		r.client.es.Index.WithContext(ctx),
 		r.client.es.Index.WithDocumentID(doc.ID),
-		r.client.es.Index.WithRefresh("true"),
+	}, opts...)
+	if doc.Refresh != "" {
+		options = append(options, r.client.es.Index.WithRefresh(string(doc.Refresh)))
+	}
+	if doc.Routing != "" {
+		options = append(options, r.client.es.Index.WithRouting(doc.Routing))
+	}
+	if doc.Pipeline != "" {
+		options = append(options, r.client.es.Index.WithPipeline(doc.Pipeline))
+	}
+	options = append(options, r.externalVersionOptions(doc)...)
+	res, err := r.client.es.Index(
+		pathIndex(doc.Index),
+		bytes.NewReader(body),
+		options...,
+	)
+	if err != nil {
+		return false, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to update document")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return false, err
+		}
+		if res.StatusCode == 409 && doc.ExternalVersion > 0 {
+			return false, errors.NewVersionConflictError(doc.Index, doc.ID, doc.ExternalVersion)
+		}
+		if res.StatusCode == 409 {
+			return false, errors.NewPreconditionFailedError(doc.Index, doc.ID)
+		}
+		return false, errors.NewAppError(errors.ErrCodeDocumentUpdateFailed, fmt.Sprintf("Document update failed with status: %s", res.Status()))
+	}
+
+	// レスポンスを解析してバージョンを取得
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return false, errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to parse update response")
+	}
+
+	// ドキュメントバージョンを更新
+	if version, ok := getInt64(result, "_version"); ok {
+		doc.Version = version
+	}
+	setConcurrencyFields(doc, result)
+
+	return getString(result, "result") == "created", nil
+}
+
+// UpsertDocument は_update APIでドキュメントを作成または更新する
+func (r *Repository) UpsertDocument(ctx context.Context, doc *entity.Document) error {
+	// 更新ボディをJSONに変換
+	body, err := json.Marshal(upsertBody(doc))
+	if err != nil {
+		return errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to marshal upsert body")
+	}
+
+	// ドキュメントをアップサート
+	options := []func(*esapi.UpdateRequest){
+		r.client.es.Update.WithContext(ctx),
+	}
+	if doc.Refresh != "" {
+		options = append(options, r.client.es.Update.WithRefresh(string(doc.Refresh)))
+	}
+	if doc.Routing != "" {
+		options = append(options, r.client.es.Update.WithRouting(doc.Routing))
+	}
+	if doc.RetryOnConflict > 0 {
+		options = append(options, r.client.es.Update.WithRetryOnConflict(doc.RetryOnConflict))
+	}
+	res, err := r.client.es.Update(
+		pathIndex(doc.Index),
+		doc.ID,
+		bytes.NewReader(body),
+		options...,
 	)
 	if err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to update document")
+		return errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to upsert document")
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return errors.NewAppError(errors.ErrCodeDocumentUpdateFailed, fmt.Sprintf("Document update failed with status: %s", res.Status()))
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		// 再試行回数を使い切っても競合が解消しなかった場合
+		if res.StatusCode == 409 {
+			return errors.NewUpdateConflictError(doc.Index, doc.ID)
+		}
+		return errors.NewAppError(errors.ErrCodeDocumentUpdateFailed, fmt.Sprintf("Document upsert failed with status: %s", res.Status()))
 	}
 
 	// レスポンスを解析してバージョンを取得
 	var result map[string]any
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to parse update response")
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return errors.WrapError(err, errors.ErrCodeDocumentUpdateFailed, "Failed to parse upsert response")
 	}
 
 	// ドキュメントバージョンを更新
-	if version, ok := result["_version"].(float64); ok {
-		doc.Version = int64(version)
+	if version, ok := getInt64(result, "_version"); ok {
+		doc.Version = version
 	}
+	setConcurrencyFields(doc, result)
 
 	return nil
 }
 
+// upsertBody は部分更新のボディを構築する
+// 作成用のソース（Upsert）がある場合は存在しないドキュメントをそれで作成し、なければ部分ドキュメントをそのまま作成する
+func upsertBody(doc *entity.Document) map[string]any {
+	if doc.Upsert != nil {
+		return map[string]any{
+			"doc":    doc.Source,
+			"upsert": doc.Upsert,
+		}
+	}
+	return map[string]any{
+		"doc":           doc.Source,
+		"doc_as_upsert": true,
+	}
+}
+
 // DeleteDocument はIDでドキュメントを削除する
-func (r *Repository) DeleteDocument(ctx context.Context, index, id string) error {
+func (r *Repository) DeleteDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) error {
+	options := []func(*esapi.DeleteRequest){
+		r.client.es.Delete.WithContext(ctx),
+	}
+	documentOptions := repository.NewDocumentOptions(opts...)
+	if documentOptions.Refresh != "" {
+		options = append(options, r.client.es.Delete.WithRefresh(string(documentOptions.Refresh)))
+	}
+	if documentOptions.Routing != "" {
+		options = append(options, r.client.es.Delete.WithRouting(documentOptions.Routing))
+	}
+	// 条件付き削除（取得後に変更されていれば409になる）
+	if documentOptions.IfSeqNo != nil && documentOptions.IfPrimaryTerm != nil {
+		options = append(options,
+			r.client.es.Delete.WithIfSeqNo(int(*documentOptions.IfSeqNo)),
+			r.client.es.Delete.WithIfPrimaryTerm(int(*documentOptions.IfPrimaryTerm)),
+		)
+	}
 	res, err := r.client.es.Delete(
-		index,
+		pathIndex(index),
 		id,
-		r.client.es.Delete.WithContext(ctx),
-		r.client.es.Delete.WithRefresh("true"),
+		options...,
 	)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrCodeDocumentDeleteFailed, "Failed to delete document")
@@ -158,9 +377,15 @@ func (r *Repository) DeleteDocument(ctx context.Context, index, id string) error
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
 		if res.StatusCode == 404 {
 			return errors.NewDocumentNotFoundError(index, id)
 		}
+		if res.StatusCode == 409 {
+			return errors.NewPreconditionFailedError(index, id)
+		}
 		return errors.NewAppError(errors.ErrCodeDocumentDeleteFailed, fmt.Sprintf("Document deletion failed with status: %s", res.Status()))
 	}
 
@@ -168,36 +393,209 @@ func (r *Repository) DeleteDocument(ctx context.Context, index, id string) error
 }
 
 // Search は検索操作を実行する
-func (r *Repository) Search(ctx context.Context, query *entity.SearchQuery) (*entity.SearchResult, error) {
-	// 検索クエリを構築
-	searchQuery := r.buildSearchQuery(query)
+func (r *Repository) Search(ctx context.Context, query *entity.SearchQuery, opts ...repository.SearchOption) (*entity.SearchResult, error) {
+	return r.search(ctx, query, r.buildSearchQuery(query), opts...)
+}
 
-	// クエリをJSONに変換
-	body, err := json.Marshal(searchQuery)
+// RenderSearchQuery は検索を実行せずに送信されるElasticsearchクエリを返す
+func (r *Repository) RenderSearchQuery(query *entity.SearchQuery) map[string]any {
+	return r.buildSearchQuery(query)
+}
+
+// ValidateQuery は検索を実行せずに、構築したクエリ句を _validate/query?explain=true で検証する
+// マッピングと合わないクエリなどは valid=false と Elasticsearch の説明として返す
+func (r *Repository) ValidateQuery(ctx context.Context, query *entity.SearchQuery) (*entity.QueryValidation, error) {
+	clause, _ := r.buildSearchQuery(query)["query"].(map[string]any)
+	body, err := json.Marshal(map[string]any{"query": clause})
 	if err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to marshal search query")
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to marshal validate query")
+	}
+
+	res, err := r.client.es.Indices.ValidateQuery(
+		r.client.es.Indices.ValidateQuery.WithContext(ctx),
+		r.client.es.Indices.ValidateQuery.WithIndex(pathIndices(query.Indices())...),
+		r.client.es.Indices.ValidateQuery.WithBody(bytes.NewReader(body)),
+		r.client.es.Indices.ValidateQuery.WithExplain(true),
+	)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to validate query")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			return nil, errors.NewIndexNotFoundError(query.Index)
+		}
+		if res.StatusCode == 400 {
+			return nil, errors.NewAppErrorWithDetails(errors.ErrCodeInvalidQuery, "Invalid search query", decodeErrorReason(res.Body))
+		}
+		return nil, errors.NewAppError(errors.ErrCodeSearchFailed, fmt.Sprintf("Query validation failed with status: %s", res.Status()))
+	}
+
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to parse validate query response")
+	}
+
+	validation := &entity.QueryValidation{
+		Query: clause,
+		Error: getString(result, "error"),
+	}
+	validation.Valid, _ = result["valid"].(bool)
+	explanations, _ := result["explanations"].([]any)
+	for _, item := range explanations {
+		explanation, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		valid, _ := explanation["valid"].(bool)
+		validation.Explanations = append(validation.Explanations, entity.QueryExplanation{
+			Index:       getString(explanation, "index"),
+			Valid:       valid,
+			Explanation: getString(explanation, "explanation"),
+			Error:       getString(explanation, "error"),
+		})
+	}
+
+	return validation, nil
+}
+
+// TermsAggregation はフィールドの値ごとのドキュメント数を上位 size 件まで集計する（ヒットは取得しない）
+func (r *Repository) TermsAggregation(ctx context.Context, index, field string, size int) (*entity.TermsResult, error) {
+	body, err := json.Marshal(map[string]any{
+		"size":             0,
+		"track_total_hits": true,
+		"aggs": map[string]any{
+			"terms": map[string]any{
+				"terms": map[string]any{
+					"field": field,
+					"size":  size,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to marshal terms aggregation")
 	}
 
-	// 検索を実行
 	res, err := r.client.es.Search(
 		r.client.es.Search.WithContext(ctx),
-		r.client.es.Search.WithIndex(query.Index),
+		r.client.es.Search.WithIndex(pathIndices(strings.Split(index, ","))...),
 		r.client.es.Search.WithBody(bytes.NewReader(body)),
-		r.client.es.Search.WithFrom(query.From),
-		r.client.es.Search.WithSize(query.Size),
 	)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to perform terms aggregation")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			return nil, errors.NewIndexNotFoundError(index)
+		}
+		if res.StatusCode == 400 {
+			return nil, errors.NewAppErrorWithDetails(errors.ErrCodeInvalidQuery, "Invalid terms aggregation", decodeErrorReason(res.Body))
+		}
+		return nil, errors.NewAppError(errors.ErrCodeSearchFailed, fmt.Sprintf("Terms aggregation failed with status: %s", res.Status()))
+	}
+
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to parse terms aggregation response")
+	}
+
+	// {"took": 3, "hits": {"total": {"value": 120}}, "aggregations": {"terms": {"sum_other_doc_count": 5, "buckets": [...]}}}
+	terms := getMap(getMap(result, "aggregations"), "terms")
+	termsResult := &entity.TermsResult{
+		Index:         index,
+		Field:         field,
+		Buckets:       []entity.TermsBucket{},
+		OtherDocCount: getInt64Value(terms, "sum_other_doc_count"),
+		Total:         getInt64Value(getMap(getMap(result, "hits"), "total"), "value"),
+		Took:          getInt64Value(result, "took"),
+	}
+	for _, bucket := range asMaps(terms["buckets"]) {
+		termsResult.Buckets = append(termsResult.Buckets, entity.TermsBucket{
+			Key:         bucket["key"],
+			KeyAsString: getString(bucket, "key_as_string"),
+			DocCount:    getInt64Value(bucket, "doc_count"),
+		})
+	}
+
+	return termsResult, nil
+}
+
+// MoreLikeThis は任意のテキストに類似したドキュメントを検索する
+func (r *Repository) MoreLikeThis(ctx context.Context, query *entity.MoreLikeThisQuery) (*entity.SearchResult, error) {
+	moreLikeThis := map[string]any{
+		"like":   query.Text,
+		"fields": query.Fields,
+	}
+	if query.MinTermFreq > 0 {
+		moreLikeThis["min_term_freq"] = query.MinTermFreq
+	}
+	if query.MaxQueryTerms > 0 {
+		moreLikeThis["max_query_terms"] = query.MaxQueryTerms
+	}
+	if query.MinDocFreq > 0 {
+		moreLikeThis["min_doc_freq"] = query.MinDocFreq
+	}
+
+	searchQuery := &entity.SearchQuery{
+		Query:  query.Text,
+		Fields: query.Fields,
+		Index:  query.Index,
+		Size:   query.Size,
+	}
+	esQuery := map[string]any{
+		"query": map[string]any{
+			"more_like_this": moreLikeThis,
+		},
+		"size": query.Size,
+	}
+
+	return r.search(ctx, searchQuery, esQuery)
+}
+
+// search は構築済みのElasticsearchクエリで検索を実行する
+// query.Index はカンマ区切りの複数インデックスやワイルドカードパターンを受け付ける
+func (r *Repository) search(ctx context.Context, query *entity.SearchQuery, searchQuery map[string]any, opts ...repository.SearchOption) (*entity.SearchResult, error) {
+	// クエリをJSONに変換
+	body, err := json.Marshal(searchQuery)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to marshal search query")
+	}
+
+	// 検索を実行
+	res, err := r.client.es.Search(r.searchRequestOptions(ctx, query, body, repository.NewSearchOptions(opts...))...)
 	if err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to perform search")
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			// 存在しないインデックスを指定した場合（ワイルドカードは allow_no_indices で制御される）
+			return nil, errors.NewAppErrorWithDetails(errors.ErrCodeIndexNotFound, fmt.Sprintf("Index not found: %s", query.Index), decodeErrorReason(res.Body))
+		}
+		if res.StatusCode == 400 {
+			// クエリ構文エラーなどリクエスト起因のエラーは理由を詳細に含める
+			return nil, errors.NewAppErrorWithDetails(errors.ErrCodeInvalidQuery, "Invalid search query", decodeErrorReason(res.Body))
+		}
 		return nil, errors.NewAppError(errors.ErrCodeSearchFailed, fmt.Sprintf("Search failed with status: %s", res.Status()))
 	}
 
 	// レスポンスを解析
 	var result map[string]any
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to parse search response")
 	}
 
@@ -207,6 +605,39 @@ func (r *Repository) Search(ctx context.Context, query *entity.SearchQuery) (*en
 	return searchResult, nil
 }
 
+// searchRequestOptions は検索APIに渡すリクエストオプションを組み立てる
+func (r *Repository) searchRequestOptions(ctx context.Context, query *entity.SearchQuery, body []byte, options *repository.SearchOptions) []func(*esapi.SearchRequest) {
+	search := r.client.es.Search
+	requestOptions := []func(*esapi.SearchRequest){
+		search.WithContext(ctx),
+		search.WithIndex(pathIndices(query.Indices())...),
+		search.WithBody(bytes.NewReader(body)),
+		search.WithFrom(query.From),
+		search.WithSize(query.Size),
+	}
+
+	if options.Timeout > 0 {
+		requestOptions = append(requestOptions, search.WithTimeout(options.Timeout))
+	}
+	if options.Preference != "" {
+		requestOptions = append(requestOptions, search.WithPreference(options.Preference))
+	}
+	if options.Routing != "" {
+		requestOptions = append(requestOptions, search.WithRouting(options.Routing))
+	}
+	if len(options.ExpandWildcards) > 0 {
+		requestOptions = append(requestOptions, search.WithExpandWildcards(strings.Join(options.ExpandWildcards, ",")))
+	}
+	if options.AllowNoIndices != nil {
+		requestOptions = append(requestOptions, search.WithAllowNoIndices(*options.AllowNoIndices))
+	}
+	if options.IgnoreUnavailable {
+		requestOptions = append(requestOptions, search.WithIgnoreUnavailable(true))
+	}
+
+	return requestOptions
+}
+
 // MultiSearch は複数の検索操作を実行する
 func (r *Repository) MultiSearch(ctx context.Context, queries []*entity.SearchQuery) ([]*entity.SearchResult, error) {
 	// マルチ検索ボディを構築
@@ -238,40 +669,69 @@ func (r *Repository) MultiSearch(ctx context.Context, queries []*entity.SearchQu
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
 		return nil, errors.NewAppError(errors.ErrCodeSearchFailed, fmt.Sprintf("Multi-search failed with status: %s", res.Status()))
 	}
 
 	// レスポンスを解析
 	var result map[string]any
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
 		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to parse multi-search response")
 	}
 
-	// 検索結果を構築
-	var results []*entity.SearchResult
-	if responses, ok := result["responses"].([]any); ok {
-		for i, response := range responses {
-			if responseMap, ok := response.(map[string]any); ok {
-				searchResult := r.buildSearchResult(queries[i], responseMap)
-				results = append(results, searchResult)
-			}
+	return r.buildMultiSearchResults(queries, result), nil
+}
+
+// buildMultiSearchResults は _msearch のレスポンスから検索結果を構築する
+// レスポンスは位置でクエリに対応付け、クエリごとに必ず1件の結果を返す（不足や失敗したサブクエリは Error に理由を設定する）
+func (r *Repository) buildMultiSearchResults(queries []*entity.SearchQuery, result map[string]any) []*entity.SearchResult {
+	responses, _ := result["responses"].([]any)
+	results := make([]*entity.SearchResult, len(queries))
+	for i, query := range queries {
+		if i >= len(responses) {
+			results[i] = newErrorSearchResult(query, "no response returned for query")
+			continue
+		}
+
+		responseMap, ok := responses[i].(map[string]any)
+		if !ok {
+			results[i] = newErrorSearchResult(query, "invalid response format")
+			continue
+		}
+
+		// サブクエリのエラーを結果に含める
+		if errorInfo, exists := responseMap["error"]; exists {
+			results[i] = newErrorSearchResult(query, extractErrorReason(errorInfo))
+			continue
 		}
+
+		results[i] = r.buildSearchResult(query, responseMap)
 	}
 
-	return results, nil
+	return results
 }
 
 // CreateIndex は新しいインデックスを作成する
-func (r *Repository) CreateIndex(ctx context.Context, index string, mapping map[string]any) error {
-	// マッピングをJSONに変換
-	body, err := json.Marshal(mapping)
+// mapping と settings はそれぞれ作成リクエストの mappings と settings に設定する（空の場合は省略する）
+func (r *Repository) CreateIndex(ctx context.Context, index string, mapping, settings map[string]any) error {
+	// マッピングと設定をJSONに変換
+	request := make(map[string]any)
+	if len(mapping) > 0 {
+		request["mappings"] = mapping
+	}
+	if len(settings) > 0 {
+		request["settings"] = settings
+	}
+	body, err := json.Marshal(request)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrCodeIndexCreateFailed, "Failed to marshal index mapping")
 	}
 
 	// インデックスを作成
 	res, err := r.client.es.Indices.Create(
-		index,
+		pathIndex(index),
 		r.client.es.Indices.Create.WithContext(ctx),
 		r.client.es.Indices.Create.WithBody(bytes.NewReader(body)),
 	)
@@ -281,6 +741,16 @@ func (r *Repository) CreateIndex(ctx context.Context, index string, mapping map[
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		if res.StatusCode == 400 {
+			reason := decodeErrorReason(res.Body)
+			if strings.HasPrefix(reason, "resource_already_exists_exception") {
+				return errors.NewIndexExistsError(index)
+			}
+			return errors.NewAppErrorWithDetails(errors.ErrCodeInvalidMapping, "Invalid index mapping or settings", reason)
+		}
 		return errors.NewAppError(errors.ErrCodeIndexCreateFailed, fmt.Sprintf("Index creation failed with status: %s", res.Status()))
 	}
 
@@ -290,7 +760,7 @@ func (r *Repository) CreateIndex(ctx context.Context, index string, mapping map[
 // DeleteIndex はインデックスを削除する
 func (r *Repository) DeleteIndex(ctx context.Context, index string) error {
 	res, err := r.client.es.Indices.Delete(
-		[]string{index},
+		[]string{pathIndex(index)},
 		r.client.es.Indices.Delete.WithContext(ctx),
 	)
 	if err != nil {
@@ -299,6 +769,9 @@ func (r *Repository) DeleteIndex(ctx context.Context, index string) error {
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
 		if res.StatusCode == 404 {
 			return errors.NewIndexNotFoundError(index)
 		}
@@ -311,7 +784,7 @@ func (r *Repository) DeleteIndex(ctx context.Context, index string) error {
 // IndexExists はインデックスが存在するかを確認する
 func (r *Repository) IndexExists(ctx context.Context, index string) (bool, error) {
 	res, err := r.client.es.Indices.Exists(
-		[]string{index},
+		[]string{pathIndex(index)},
 		r.client.es.Indices.Exists.WithContext(ctx),
 	)
 	if err != nil {
@@ -322,87 +795,520 @@ func (r *Repository) IndexExists(ctx context.Context, index string) (bool, error
 	return res.StatusCode == 200, nil
 }
 
-// BulkIndex はドキュメントのバルクインデックスを実行する
-func (r *Repository) BulkIndex(ctx context.Context, documents []*entity.Document) error {
-	// バルクボディを構築
-	var body bytes.Buffer
-	for _, doc := range documents {
-		// アクションとメタデータ
-		action := map[string]any{
-			"index": map[string]any{
-				"_index": doc.Index,
-				"_id":    doc.ID,
-			},
-		}
-		actionJSON, _ := json.Marshal(action)
-		body.Write(actionJSON)
-		body.WriteByte('\n')
-
-		// ドキュメントソース
-		sourceJSON, _ := json.Marshal(doc.Source)
-		body.Write(sourceJSON)
-		body.WriteByte('\n')
-	}
-
-	// バルク操作を実行
-	res, err := r.client.es.Bulk(
-		&body,
-		r.client.es.Bulk.WithContext(ctx),
-		r.client.es.Bulk.WithRefresh("true"),
+// IndexStats はインデックスの統計情報（ドキュメント数、サイズ、セグメント数）を返す
+func (r *Repository) IndexStats(ctx context.Context, index string) (map[string]any, error) {
+	res, err := r.client.es.Indices.Stats(
+		r.client.es.Indices.Stats.WithContext(ctx),
+		r.client.es.Indices.Stats.WithIndex(pathIndex(index)),
+		r.client.es.Indices.Stats.WithMetric("docs", "store", "segments"),
 	)
 	if err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to perform bulk indexing")
+		return nil, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to get index stats")
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return errors.NewAppError(errors.ErrCodeDocumentCreateFailed, fmt.Sprintf("Bulk indexing failed with status: %s", res.Status()))
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			return nil, errors.NewIndexNotFoundError(index)
+		}
+		return nil, errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Index stats request failed with status: %s", res.Status()))
 	}
 
-	return nil
-}
-
-// BulkDelete はドキュメントのバルク削除を実行する
-func (r *Repository) BulkDelete(ctx context.Context, indices []string, ids []string) error {
-	if len(indices) != len(ids) {
-		return errors.NewAppError(errors.ErrCodeValidationFailed, "Indices and IDs arrays must have the same length")
+	// レスポンスを解析
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to parse index stats response")
 	}
 
-	// バルクボディを構築
-	var body bytes.Buffer
-	for i, index := range indices {
-		// アクションとメタデータ
-		action := map[string]any{
-			"delete": map[string]any{
-				"_index": index,
-				"_id":    ids[i],
-			},
-		}
-		actionJSON, _ := json.Marshal(action)
-		body.Write(actionJSON)
-		body.WriteByte('\n')
-	}
+	// 対象インデックス全体の集計値を抽出
+	all := getMap(result, "_all")
+	primaries := getMap(all, "primaries")
+	total := getMap(all, "total")
+
+	return map[string]any{
+		"index":             index,
+		"doc_count":         getInt64Value(getMap(primaries, "docs"), "count"),
+		"deleted_doc_count": getInt64Value(getMap(primaries, "docs"), "deleted"),
+		"store_size_bytes":  getInt64Value(getMap(total, "store"), "size_in_bytes"),
+		"segment_count":     getInt64Value(getMap(total, "segments"), "count"),
+	}, nil
+}
 
-	// バルク操作を実行
-	res, err := r.client.es.Bulk(
-		&body,
-		r.client.es.Bulk.WithContext(ctx),
-		r.client.es.Bulk.WithRefresh("true"),
+// OpenIndex はクローズされたインデックスをオープンする
+func (r *Repository) OpenIndex(ctx context.Context, index string) error {
+	res, err := r.client.es.Indices.Open(
+		[]string{pathIndex(index)},
+		r.client.es.Indices.Open.WithContext(ctx),
 	)
 	if err != nil {
-		return errors.WrapError(err, errors.ErrCodeDocumentDeleteFailed, "Failed to perform bulk deletion")
+		return errors.WrapError(err, errors.ErrCodeInternalError, "Failed to open index")
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return errors.NewAppError(errors.ErrCodeDocumentDeleteFailed, fmt.Sprintf("Bulk deletion failed with status: %s", res.Status()))
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		if res.StatusCode == 404 {
+			return errors.NewIndexNotFoundError(index)
+		}
+		return errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Index open failed with status: %s", res.Status()))
 	}
 
 	return nil
 }
 
-// Health はElasticsearchクラスターの健康状態を返す
-func (r *Repository) Health(ctx context.Context) error {
+// Refresh はインデックスをリフレッシュし、直前までに書き込まれたドキュメントを検索可能にする
+func (r *Repository) Refresh(ctx context.Context, index string) error {
+	res, err := r.client.es.Indices.Refresh(
+		r.client.es.Indices.Refresh.WithContext(ctx),
+		r.client.es.Indices.Refresh.WithIndex(pathIndex(index)),
+	)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrCodeInternalError, "Failed to refresh index")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		if res.StatusCode == 404 {
+			return errors.NewIndexNotFoundError(index)
+		}
+		return errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Index refresh failed with status: %s", res.Status()))
+	}
+
+	return nil
+}
+
+// CloseIndex はインデックスをクローズする（クローズ中は読み書きできない）
+func (r *Repository) CloseIndex(ctx context.Context, index string) error {
+	res, err := r.client.es.Indices.Close(
+		[]string{pathIndex(index)},
+		r.client.es.Indices.Close.WithContext(ctx),
+	)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrCodeInternalError, "Failed to close index")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		if res.StatusCode == 404 {
+			return errors.NewIndexNotFoundError(index)
+		}
+		return errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Index close failed with status: %s", res.Status()))
+	}
+
+	return nil
+}
+
+// ForceMerge はインデックスのセグメントをマージするタスクを開始し、タスクIDを返す
+// 完了を待たずに戻るため、進捗はタスクAPIで確認する。maxSegments が0の場合はElasticsearchに任せる
+func (r *Repository) ForceMerge(ctx context.Context, index string, maxSegments int) (string, error) {
+	options := []func(*esapi.IndicesForcemergeRequest){
+		r.client.es.Indices.Forcemerge.WithContext(ctx),
+		r.client.es.Indices.Forcemerge.WithIndex(pathIndex(index)),
+		r.client.es.Indices.Forcemerge.WithWaitForCompletion(false),
+	}
+	if maxSegments > 0 {
+		options = append(options, r.client.es.Indices.Forcemerge.WithMaxNumSegments(maxSegments))
+	}
+	res, err := r.client.es.Indices.Forcemerge(options...)
+	if err != nil {
+		return "", errors.WrapError(err, errors.ErrCodeInternalError, "Failed to force merge index")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return "", err
+		}
+		if res.StatusCode == 404 {
+			return "", errors.NewIndexNotFoundError(index)
+		}
+		return "", errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Force merge failed with status: %s", res.Status()))
+	}
+
+	// レスポンスからタスクIDを取得
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return "", errors.WrapError(err, errors.ErrCodeInternalError, "Failed to parse force merge response")
+	}
+
+	return getString(result, "task"), nil
+}
+
+// ScrollDocuments はスクロールAPIでインデックスの全ドキュメントを batchSize 件ずつ fn に渡す
+// query が nil でなければ検索と同じクエリ句で対象を絞り込む。スクロールは終了時に必ず解放する
+func (r *Repository) ScrollDocuments(ctx context.Context, index string, query *entity.SearchQuery, batchSize int, fn func(hits []entity.Hit) error) error {
+	clause := map[string]any{"match_all": map[string]any{}}
+	if query != nil {
+		clause = buildQueryClause(query)
+	}
+	body, err := json.Marshal(map[string]any{
+		"query": clause,
+		"sort":  []string{"_doc"},
+	})
+	if err != nil {
+		return errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to marshal export query")
+	}
+
+	// 最初のバッチを取得してスクロールを開始
+	res, err := r.client.es.Search(
+		r.client.es.Search.WithContext(ctx),
+		r.client.es.Search.WithIndex(pathIndex(index)),
+		r.client.es.Search.WithBody(bytes.NewReader(body)),
+		r.client.es.Search.WithSize(batchSize),
+		r.client.es.Search.WithScroll(scrollKeepAlive),
+	)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to start scroll")
+	}
+
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			r.clearScroll(scrollID)
+		}
+	}()
+
+	for {
+		result, err := r.decodeScrollResponse(res, index)
+		if err != nil {
+			return err
+		}
+		if id := getString(result, "_scroll_id"); id != "" {
+			scrollID = id
+		}
+
+		hits := parseHits(getMap(result, "hits"))
+		if len(hits) == 0 {
+			return nil
+		}
+		if err := fn(hits); err != nil {
+			return err
+		}
+
+		// クライアントが切断した場合はスクロールを中断する
+		if err := ctx.Err(); err != nil {
+			return errors.WrapError(err, errors.ErrCodeSearchFailed, "Scroll aborted")
+		}
+
+		res, err = r.client.es.Scroll(
+			r.client.es.Scroll.WithContext(ctx),
+			r.client.es.Scroll.WithScrollID(scrollID),
+			r.client.es.Scroll.WithScroll(scrollKeepAlive),
+		)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to continue scroll")
+		}
+	}
+}
+
+// decodeScrollResponse はスクロールのレスポンスを解析してボディを閉じる
+func (r *Repository) decodeScrollResponse(res *esapi.Response, index string) (map[string]any, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			return nil, errors.NewIndexNotFoundError(index)
+		}
+		if res.StatusCode == 400 {
+			return nil, errors.NewAppErrorWithDetails(errors.ErrCodeInvalidQuery, "Invalid export query", decodeErrorReason(res.Body))
+		}
+		return nil, errors.NewAppError(errors.ErrCodeSearchFailed, fmt.Sprintf("Scroll request failed with status: %s", res.Status()))
+	}
+
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeSearchFailed, "Failed to parse scroll response")
+	}
+	return result, nil
+}
+
+// clearScroll はスクロールコンテキストを解放する
+// リクエストのコンテキストがキャンセルされていても解放できるよう独立したコンテキストを使う
+func (r *Repository) clearScroll(scrollID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.client.es.ClearScroll(
+		r.client.es.ClearScroll.WithContext(ctx),
+		r.client.es.ClearScroll.WithScrollID(scrollID),
+	)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+}
+
+// GetFieldType はインデックスのマッピングからフィールドの型を取得する
+// フィールドがマッピングに存在しない場合は空文字を返す
+func (r *Repository) GetFieldType(ctx context.Context, index, field string) (string, error) {
+	res, err := r.client.es.Indices.GetFieldMapping(
+		[]string{field},
+		r.client.es.Indices.GetFieldMapping.WithContext(ctx),
+		r.client.es.Indices.GetFieldMapping.WithIndex(pathIndex(index)),
+	)
+	if err != nil {
+		return "", errors.WrapError(err, errors.ErrCodeInternalError, "Failed to get field mapping")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return "", err
+		}
+		if res.StatusCode == 404 {
+			return "", errors.NewIndexNotFoundError(index)
+		}
+		return "", errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Failed to get field mapping with status: %s", res.Status()))
+	}
+
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return "", errors.WrapError(err, errors.ErrCodeInternalError, "Failed to parse field mapping response")
+	}
+
+	// {"<index>": {"mappings": {"<field>": {"mapping": {"<leaf>": {"type": "..."}}}}}}
+	for _, indexMapping := range result {
+		indexMap, _ := indexMapping.(map[string]any)
+		fieldMapping := getMap(getMap(getMap(indexMap, "mappings"), field), "mapping")
+		for _, leaf := range fieldMapping {
+			if leafMap, ok := leaf.(map[string]any); ok {
+				if fieldType := getString(leafMap, "type"); fieldType != "" {
+					return fieldType, nil
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// FieldCapabilities はフィールドの型と集約に使えるかどうかを _field_caps で取得する
+// フィールドがマッピングに存在しない場合は nil を返す
+func (r *Repository) FieldCapabilities(ctx context.Context, index, field string) (*entity.FieldCapability, error) {
+	res, err := r.client.es.FieldCaps(
+		r.client.es.FieldCaps.WithContext(ctx),
+		r.client.es.FieldCaps.WithIndex(pathIndices(strings.Split(index, ","))...),
+		r.client.es.FieldCaps.WithFields(field),
+	)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to get field capabilities")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == 404 {
+			return nil, errors.NewIndexNotFoundError(index)
+		}
+		return nil, errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Failed to get field capabilities with status: %s", res.Status()))
+	}
+
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to parse field capabilities response")
+	}
+
+	// {"fields": {"<field>": {"<type>": {"type": "keyword", "aggregatable": true}}}}
+	// インデックスごとに型が異なる場合は型ごとのエントリになる
+	types := getMap(getMap(result, "fields"), field)
+	if len(types) == 0 {
+		return nil, nil
+	}
+	capability := &entity.FieldCapability{Aggregatable: true}
+	for fieldType := range types {
+		capability.Types = append(capability.Types, fieldType)
+		if aggregatable, _ := getMap(types, fieldType)["aggregatable"].(bool); !aggregatable {
+			capability.Aggregatable = false
+		}
+	}
+	sort.Strings(capability.Types)
+
+	return capability, nil
+}
+
+// MaxResultWindow はインデックスの index.max_result_window を返す（未設定の場合はElasticsearchのデフォルト値）
+// 複数インデックスやワイルドカードを指定した場合は、対象インデックスの中で最小の値を返す
+func (r *Repository) MaxResultWindow(ctx context.Context, index string) (int, error) {
+	res, err := r.client.es.Indices.GetSettings(
+		r.client.es.Indices.GetSettings.WithContext(ctx),
+		r.client.es.Indices.GetSettings.WithIndex(pathIndices(strings.Split(index, ","))...),
+		r.client.es.Indices.GetSettings.WithName("index.max_result_window"),
+		r.client.es.Indices.GetSettings.WithIncludeDefaults(true),
+		r.client.es.Indices.GetSettings.WithFlatSettings(true),
+	)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to get index settings")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return 0, err
+		}
+		if res.StatusCode == 404 {
+			return 0, errors.NewIndexNotFoundError(index)
+		}
+		return 0, errors.NewAppError(errors.ErrCodeInternalError, fmt.Sprintf("Failed to get index settings with status: %s", res.Status()))
+	}
+
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return 0, errors.WrapError(err, errors.ErrCodeInternalError, "Failed to parse index settings response")
+	}
+
+	// {"<index>": {"settings": {"index.max_result_window": "20000"}, "defaults": {...}}}
+	// 設定値は文字列で返されるため整数に変換する
+	window := 0
+	for _, indexSettings := range result {
+		indexMap, _ := indexSettings.(map[string]any)
+		value := getString(getMap(indexMap, "settings"), "index.max_result_window")
+		if value == "" {
+			value = getString(getMap(indexMap, "defaults"), "index.max_result_window")
+		}
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && (window == 0 || n < window) {
+			window = n
+		}
+	}
+
+	return window, nil
+}
+
+// BulkIndex はドキュメントのバルクインデックスを実行する
+// opType が create の場合は既存IDを上書きせず、アイテムごとに競合として報告する
+// opType が upsert の場合は doc_as_upsert の update アクションで既存ドキュメントにマージする（パイプラインは適用されない）
+func (r *Repository) BulkIndex(ctx context.Context, documents []*entity.Document, opType entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkResult, error) {
+	if opType == "" {
+		opType = entity.BulkOpIndex
+	}
+	actionName := string(opType)
+	if opType == entity.BulkOpUpsert {
+		actionName = "update"
+	}
+
+	// バルクボディを構築
+	var body bytes.Buffer
+	for _, doc := range documents {
+		// アクションとメタデータ
+		metadata := map[string]any{
+			"_index": doc.Index,
+		}
+		if doc.ID != "" {
+			metadata["_id"] = doc.ID
+		}
+		if doc.Routing != "" {
+			metadata["routing"] = doc.Routing
+		}
+		if doc.Pipeline != "" && opType != entity.BulkOpUpsert {
+			metadata["pipeline"] = doc.Pipeline
+		}
+		if doc.RetryOnConflict > 0 && opType == entity.BulkOpUpsert {
+			metadata["retry_on_conflict"] = doc.RetryOnConflict
+		}
+		action := map[string]any{
+			actionName: metadata,
+		}
+		actionJSON, _ := json.Marshal(action)
+		body.Write(actionJSON)
+		body.WriteByte('\n')
+
+		// ドキュメントソース（upsert の場合は部分更新のボディ）
+		var source any = doc.Source
+		if opType == entity.BulkOpUpsert {
+			source = upsertBody(doc)
+		}
+		sourceJSON, _ := json.Marshal(source)
+		body.Write(sourceJSON)
+		body.WriteByte('\n')
+	}
+
+	// バルク操作を実行
+	res, err := r.client.es.Bulk(&body, r.bulkOptions(ctx, opts...)...)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to perform bulk indexing")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return nil, err
+		}
+		return nil, errors.NewAppError(errors.ErrCodeDocumentCreateFailed, fmt.Sprintf("Bulk indexing failed with status: %s", res.Status()))
+	}
+
+	// レスポンスを解析
+	var result map[string]any
+	if err := r.client.parseResponse(res.Body, &result); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeDocumentCreateFailed, "Failed to parse bulk response")
+	}
+
+	return buildBulkResult(result), nil
+}
+
+// bulkOptions はバルクリクエストのオプションを構築する（リフレッシュ未指定の場合はElasticsearchのデフォルト）
+func (r *Repository) bulkOptions(ctx context.Context, opts ...repository.DocumentOption) []func(*esapi.BulkRequest) {
+	options := []func(*esapi.BulkRequest){r.client.es.Bulk.WithContext(ctx)}
+	if refresh := repository.NewDocumentOptions(opts...).Refresh; refresh != "" {
+		options = append(options, r.client.es.Bulk.WithRefresh(string(refresh)))
+	}
+	return options
+}
+
+// BulkDelete はドキュメントのバルク削除を実行する
+func (r *Repository) BulkDelete(ctx context.Context, indices []string, ids []string, opts ...repository.DocumentOption) error {
+	if len(indices) != len(ids) {
+		return errors.NewAppError(errors.ErrCodeValidationFailed, "Indices and IDs arrays must have the same length")
+	}
+
+	// バルクボディを構築
+	var body bytes.Buffer
+	for i, index := range indices {
+		// アクションとメタデータ
+		action := map[string]any{
+			"delete": map[string]any{
+				"_index": index,
+				"_id":    ids[i],
+			},
+		}
+		actionJSON, _ := json.Marshal(action)
+		body.Write(actionJSON)
+		body.WriteByte('\n')
+	}
+
+	// バルク操作を実行
+	res, err := r.client.es.Bulk(&body, r.bulkOptions(ctx, opts...)...)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrCodeDocumentDeleteFailed, "Failed to perform bulk deletion")
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if err := responseLimitError(res); err != nil {
+			return err
+		}
+		return errors.NewAppError(errors.ErrCodeDocumentDeleteFailed, fmt.Sprintf("Bulk deletion failed with status: %s", res.Status()))
+	}
+
+	return nil
+}
+
+// Health はElasticsearchクラスターの健康状態を返す
+func (r *Repository) Health(ctx context.Context) error {
 	healthy, err := r.client.IsHealthy(ctx)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrCodeElasticsearchDown, "Failed to check cluster health")
@@ -427,42 +1333,118 @@ func (r *Repository) Info(ctx context.Context) (map[string]any, error) {
 
 // ヘルパーメソッド
 
+// collapseInnerHitsName はフィールドコラプスのインナーヒットに付ける名前
+const collapseInnerHitsName = "collapsed"
+
+// nestedInnerHitsName はネストクエリのインナーヒットに付ける名前
+const nestedInnerHitsName = "nested"
+
+// scrollKeepAlive はスクロールのバッチ間でコンテキストを保持する時間
+const scrollKeepAlive = time.Minute
+
 // buildSearchQuery はSearchQueryエンティティからElasticsearchクエリを構築する
 func (r *Repository) buildSearchQuery(query *entity.SearchQuery) map[string]any {
 	esQuery := map[string]any{
-		"query": map[string]any{
-			"multi_match": map[string]any{
-				"query":  query.Query,
-				"fields": []string{"*"},
-			},
-		},
-		"from": query.From,
-		"size": query.Size,
+		"query": buildQueryClause(query),
+		"from":  query.From,
+		"size":  query.Size,
 	}
 
-	// フィルターを追加
-	if len(query.Filters) > 0 {
-		filters := make([]map[string]any, 0, len(query.Filters))
-		for field, value := range query.Filters {
-			if field == "_facets" {
-				// ファセット集約を処理
-				continue
-			}
-			filters = append(filters, map[string]any{
-				"term": map[string]any{
-					field: value,
-				},
-			})
+	// フィルターを追加（同じ検索で毎回同じクエリになるようフィールド名順に並べる）
+	filters := make([]map[string]any, 0, len(query.Filters))
+	for _, field := range sortedKeys(query.Filters) {
+		if field == "_facets" {
+			// ファセット集約を処理
+			continue
 		}
+		filters = append(filters, map[string]any{
+			"term": map[string]any{
+				field: query.Filters[field],
+			},
+		})
+	}
 
+	// ネストクエリは全文検索句と同じ must に追加する
+	var must any = esQuery["query"]
+	if query.Nested != nil && query.Nested.Path != "" {
+		must = []any{esQuery["query"], buildNestedClause(query.Nested)}
+	}
+
+	if len(filters) > 0 || query.Nested != nil {
+		boolQuery := map[string]any{
+			"must": must,
+		}
 		if len(filters) > 0 {
-			esQuery["query"] = map[string]any{
-				"bool": map[string]any{
-					"must":   esQuery["query"],
-					"filter": filters,
-				},
+			boolQuery["filter"] = filters
+		}
+		esQuery["query"] = map[string]any{
+			"bool": boolQuery,
+		}
+	}
+
+	// 関数スコアで全体のクエリを包む
+	if query.FunctionScore != nil {
+		esQuery["query"] = buildFunctionScoreClause(esQuery["query"], query.FunctionScore)
+	}
+
+	// 最小スコアを追加
+	if query.MinScore > 0 {
+		esQuery["min_score"] = query.MinScore
+	}
+
+	// IDのみが必要な場合はソースを転送しない
+	if query.ExcludeSource {
+		esQuery["_source"] = false
+	}
+
+	// 処理時間の内訳を取得する
+	if query.Profile {
+		esQuery["profile"] = true
+	}
+
+	// ヒットごとのスコアの計算過程を取得する
+	if query.Explain {
+		esQuery["explain"] = true
+	}
+
+	// ヒットごとのシーケンス番号とプライマリタームを取得する（条件付き更新に使う）
+	if query.SeqNoPrimaryTerm {
+		esQuery["seq_no_primary_term"] = true
+	}
+
+	// _source 以外から取得するフィールドを追加
+	if len(query.StoredFields) > 0 {
+		esQuery["stored_fields"] = query.StoredFields
+		// stored_fields を指定すると _source が返らなくなるため、明示的に要求する
+		if !query.ExcludeSource {
+			esQuery["_source"] = true
+		}
+	}
+	if len(query.DocValueFields) > 0 {
+		esQuery["docvalue_fields"] = query.DocValueFields
+	}
+
+	// ハイライトを追加
+	if query.Highlight != nil && len(query.Highlight.Fields) > 0 {
+		esQuery["highlight"] = buildHighlight(query.Highlight)
+	}
+
+	// フィールドコラプスを追加
+	if query.Collapse != nil && query.Collapse.Field != "" {
+		collapse := map[string]any{
+			"field": query.Collapse.Field,
+		}
+		if query.Collapse.InnerHitsSize > 0 {
+			innerHits := map[string]any{
+				"name": collapseInnerHitsName,
+				"size": query.Collapse.InnerHitsSize,
+			}
+			if query.ExcludeSource {
+				innerHits["_source"] = false
 			}
+			collapse["inner_hits"] = innerHits
 		}
+		esQuery["collapse"] = collapse
 	}
 
 	// ソートを追加
@@ -478,9 +1460,221 @@ func (r *Repository) buildSearchQuery(query *entity.SearchQuery) map[string]any
 		esQuery["sort"] = sort
 	}
 
+	// 前ページのカーソル以降を取得
+	if len(query.SearchAfter) > 0 {
+		esQuery["search_after"] = query.SearchAfter
+	}
+
 	return esQuery
 }
 
+// buildFunctionScoreClause はベースクエリのスコアを関数で調整する function_score クエリを構築する
+func buildFunctionScoreClause(base any, fs *entity.FunctionScore) map[string]any {
+	functions := make([]map[string]any, 0, 2)
+
+	if fvf := fs.FieldValueFactor; fvf != nil {
+		factor := map[string]any{
+			"field": fvf.Field,
+		}
+		if fvf.Factor > 0 {
+			factor["factor"] = fvf.Factor
+		}
+		if fvf.Modifier != "" {
+			factor["modifier"] = fvf.Modifier
+		}
+		if fvf.Missing != nil {
+			factor["missing"] = *fvf.Missing
+		}
+		functions = append(functions, map[string]any{"field_value_factor": factor})
+	}
+
+	if decay := fs.Decay; decay != nil {
+		params := map[string]any{
+			"scale": decay.Scale,
+		}
+		if decay.Origin != "" {
+			params["origin"] = decay.Origin
+		}
+		if decay.Offset != "" {
+			params["offset"] = decay.Offset
+		}
+		if decay.Decay > 0 {
+			params["decay"] = decay.Decay
+		}
+		functions = append(functions, map[string]any{
+			string(decay.Function): map[string]any{
+				decay.Field: params,
+			},
+		})
+	}
+
+	clause := map[string]any{
+		"query":      base,
+		"functions":  functions,
+		"score_mode": "multiply",
+	}
+	if fs.BoostMode != "" {
+		clause["boost_mode"] = fs.BoostMode
+	}
+
+	return map[string]any{"function_score": clause}
+}
+
+// buildNestedClause はネストされたオブジェクトに対する nested クエリを構築する
+// キーの順序を固定してクエリの表示結果を安定させる
+func buildNestedClause(nested *entity.NestedQuery) map[string]any {
+	must := make([]map[string]any, 0, len(nested.Must))
+	for _, field := range sortedKeys(nested.Must) {
+		must = append(must, map[string]any{
+			"match": map[string]any{
+				field: nested.Must[field],
+			},
+		})
+	}
+
+	filter := make([]map[string]any, 0, len(nested.Filter))
+	for _, field := range sortedKeys(nested.Filter) {
+		filter = append(filter, map[string]any{
+			"term": map[string]any{
+				field: nested.Filter[field],
+			},
+		})
+	}
+
+	clause := map[string]any{
+		"path": nested.Path,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"must":   must,
+				"filter": filter,
+			},
+		},
+	}
+	if nested.InnerHitsSize > 0 {
+		clause["inner_hits"] = map[string]any{
+			"name": nestedInnerHitsName,
+			"size": nested.InnerHitsSize,
+		}
+	}
+
+	return map[string]any{"nested": clause}
+}
+
+// sortedKeys はマップのキーを昇順で返す
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// buildQueryClause は検索モードに応じた全文検索句を構築する
+func buildQueryClause(query *entity.SearchQuery) map[string]any {
+	// 検索語のないクエリ（ドキュメント一覧）は全件に一致させる
+	if query.Query == "" {
+		return map[string]any{"match_all": map[string]any{}}
+	}
+
+	fields := query.Fields
+	if len(fields) == 0 {
+		fields = []string{"*"}
+	}
+
+	switch query.Mode {
+	case entity.SearchModeQueryString:
+		clause := map[string]any{
+			"query":                  query.Query,
+			"fields":                 boostFields(fields, query.FieldBoosts),
+			"allow_leading_wildcard": false,
+		}
+		if query.DefaultOperator != "" {
+			clause["default_operator"] = query.DefaultOperator
+		}
+		if query.Analyzer != "" {
+			clause["analyzer"] = query.Analyzer
+		}
+		return map[string]any{"query_string": clause}
+	case entity.SearchModeSimpleQueryString:
+		clause := map[string]any{
+			"query":  query.Query,
+			"fields": boostFields(fields, query.FieldBoosts),
+		}
+		if query.DefaultOperator != "" {
+			clause["default_operator"] = query.DefaultOperator
+		}
+		if query.Analyzer != "" {
+			clause["analyzer"] = query.Analyzer
+		}
+		return map[string]any{"simple_query_string": clause}
+	case entity.SearchModeSearchAsYouType:
+		// search_as_you_type フィールドはシングル（_2gram/_3gram）のサブフィールドと合わせて検索する
+		prefixFields := make([]string, 0, len(fields)*3)
+		for _, field := range fields {
+			prefixFields = append(prefixFields, field, field+"._2gram", field+"._3gram")
+		}
+		clause := map[string]any{
+			"query":  query.Query,
+			"type":   "bool_prefix",
+			"fields": prefixFields,
+		}
+		if query.DefaultOperator != "" {
+			clause["operator"] = query.DefaultOperator
+		}
+		if query.Analyzer != "" {
+			clause["analyzer"] = query.Analyzer
+		}
+		return map[string]any{"multi_match": clause}
+	default:
+		clause := map[string]any{
+			"query":  query.Query,
+			"fields": boostFields(fields, query.FieldBoosts),
+		}
+		if query.DefaultOperator != "" {
+			clause["operator"] = query.DefaultOperator
+		}
+		if query.Analyzer != "" {
+			clause["analyzer"] = query.Analyzer
+		}
+		return map[string]any{"multi_match": clause}
+	}
+}
+
+// boostFields は対象フィールドにブーストを付与する（"title" → "title^3"）
+// 対象フィールドに含まれないブースト対象（"*" で検索する場合など）は末尾に追加する。
+// Elasticsearch は複数のパターンに一致したフィールドのブーストを掛け合わせるため、"*" と併用しても指定した倍率になる
+func boostFields(fields []string, boosts map[string]float64) []string {
+	if len(boosts) == 0 {
+		return fields
+	}
+
+	boosted := make([]string, 0, len(fields)+len(boosts))
+	applied := make(map[string]bool, len(boosts))
+	for _, field := range fields {
+		if boost, ok := boosts[field]; ok {
+			boosted = append(boosted, field+"^"+strconv.FormatFloat(boost, 'f', -1, 64))
+			applied[field] = true
+			continue
+		}
+		boosted = append(boosted, field)
+	}
+
+	// 出力を安定させるためフィールド名順に追加する
+	extra := make([]string, 0, len(boosts))
+	for field := range boosts {
+		if !applied[field] {
+			extra = append(extra, field)
+		}
+	}
+	sort.Strings(extra)
+	for _, field := range extra {
+		boosted = append(boosted, field+"^"+strconv.FormatFloat(boosts[field], 'f', -1, 64))
+	}
+
+	return boosted
+}
+
 // buildSearchResult はElasticsearchレスポンスからSearchResultエンティティを構築する
 func (r *Repository) buildSearchResult(query *entity.SearchQuery, result map[string]any) *entity.SearchResult {
 	searchResult := entity.NewSearchResult(*query)
@@ -489,57 +1683,417 @@ func (r *Repository) buildSearchResult(query *entity.SearchQuery, result map[str
 	if hits, ok := result["hits"].(map[string]any); ok {
 		// 総ヒット数
 		if total, ok := hits["total"].(map[string]any); ok {
-			if value, ok := total["value"].(float64); ok {
-				searchResult.Total = int64(value)
+			if value, ok := getInt64(total, "value"); ok {
+				searchResult.Total = value
 			}
+			searchResult.TotalRelation = getString(total, "relation")
 		}
 
 		// 最大スコア
-		if maxScore, ok := hits["max_score"].(float64); ok {
+		if maxScore, ok := toFloat64(hits["max_score"]); ok {
 			searchResult.MaxScore = maxScore
 		}
 
 		// 個別のヒット
-		if hitsList, ok := hits["hits"].([]any); ok {
-			for _, hit := range hitsList {
-				if hitMap, ok := hit.(map[string]any); ok {
-					entityHit := entity.Hit{
-						Index:  getString(hitMap, "_index"),
-						ID:     getString(hitMap, "_id"),
-						Score:  getFloat64(hitMap, "_score"),
-						Source: getMap(hitMap, "_source"),
-					}
-					searchResult.AddHit(entityHit)
-				}
-			}
+		for _, hit := range parseHits(hits) {
+			searchResult.AddHit(hit)
 		}
 	}
 
 	// タイミング情報を抽出
-	if took, ok := result["took"].(float64); ok {
-		searchResult.Took = int64(took)
+	if took, ok := getInt64(result, "took"); ok {
+		searchResult.Took = took
 	}
 
 	if timedOut, ok := result["timed_out"].(bool); ok {
 		searchResult.TimedOut = timedOut
 	}
 
+	// プロファイル結果を要約する（レスポンスが大きいため、必要な時間のみを抽出する）
+	if profile, ok := result["profile"].(map[string]any); ok {
+		searchResult.Profile = parseProfile(profile)
+	}
+
 	return searchResult
 }
 
+// parseProfile はprofileセクションからシャードごとの処理時間の内訳を抽出する
+func parseProfile(profile map[string]any) *entity.SearchProfile {
+	parsed := &entity.SearchProfile{Shards: []entity.ShardProfile{}}
+
+	shards, _ := profile["shards"].([]any)
+	for _, shard := range shards {
+		shardMap, ok := shard.(map[string]any)
+		if !ok {
+			continue
+		}
+		shardProfile := entity.ShardProfile{
+			ID:         getString(shardMap, "id"),
+			Index:      getString(shardMap, "index"),
+			Shard:      getInt64Value(shardMap, "shard_id"),
+			FetchNanos: getInt64Value(getMap(shardMap, "fetch"), "time_in_nanos"),
+		}
+
+		searches, _ := shardMap["searches"].([]any)
+		for _, search := range searches {
+			searchMap, ok := search.(map[string]any)
+			if !ok {
+				continue
+			}
+			queries := parseQueryProfiles(searchMap["query"])
+			for _, query := range queries {
+				shardProfile.QueryNanos += query.Nanos
+			}
+			shardProfile.Queries = append(shardProfile.Queries, queries...)
+			shardProfile.RewriteNanos += getInt64Value(searchMap, "rewrite_time")
+			for _, collector := range asMaps(searchMap["collector"]) {
+				shardProfile.CollectNanos += getInt64Value(collector, "time_in_nanos")
+			}
+		}
+		for _, aggregation := range asMaps(shardMap["aggregations"]) {
+			shardProfile.AggregateNanos += getInt64Value(aggregation, "time_in_nanos")
+		}
+
+		parsed.Shards = append(parsed.Shards, shardProfile)
+	}
+
+	parsed.SummarizeFields()
+	return parsed
+}
+
+// parseQueryProfiles はクエリのプロファイル（子クエリを含む）を抽出する。内訳（breakdown）は含めない
+func parseQueryProfiles(value any) []entity.QueryProfile {
+	maps := asMaps(value)
+	if len(maps) == 0 {
+		return nil
+	}
+
+	queries := make([]entity.QueryProfile, 0, len(maps))
+	for _, query := range maps {
+		queries = append(queries, entity.QueryProfile{
+			Type:        getString(query, "type"),
+			Description: getString(query, "description"),
+			Nanos:       getInt64Value(query, "time_in_nanos"),
+			Children:    parseQueryProfiles(query["children"]),
+		})
+	}
+	return queries
+}
+
+// asMaps は配列のうちオブジェクトの要素のみを返す
+func asMaps(value any) []map[string]any {
+	list, _ := value.([]any)
+	maps := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// buildHighlight はフィールドごとのハイライト設定を構築する
+func buildHighlight(highlight *entity.Highlight) map[string]any {
+	fields := make(map[string]any, len(highlight.Fields))
+	for _, field := range highlight.Fields {
+		options := map[string]any{}
+		if field.FragmentSize != nil {
+			options["fragment_size"] = *field.FragmentSize
+		}
+		if field.NumberOfFragments != nil {
+			options["number_of_fragments"] = *field.NumberOfFragments
+		}
+		if field.Order != "" {
+			options["order"] = field.Order
+		}
+		fields[field.Field] = options
+	}
+	return map[string]any{"fields": fields}
+}
+
+// parseExplanation はヒットの_explanation（子の計算過程を含む）を抽出する
+func parseExplanation(explanation map[string]any) entity.Explanation {
+	parsed := entity.Explanation{
+		Value:       getFloat64(explanation, "value"),
+		Description: getString(explanation, "description"),
+	}
+	for _, detail := range asMaps(explanation["details"]) {
+		parsed.Details = append(parsed.Details, parseExplanation(detail))
+	}
+	return parsed
+}
+
+// parseFields はヒットのfields（stored_fields と docvalue_fields の値）を抽出する
+func parseFields(fields map[string]any) map[string][]any {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	parsed := make(map[string][]any, len(fields))
+	for field, value := range fields {
+		if values, ok := value.([]any); ok {
+			parsed[field] = values
+		}
+	}
+	return parsed
+}
+
+// parseHighlight はヒットのhighlightからフィールドごとの断片を抽出する
+func parseHighlight(highlight map[string]any) map[string][]string {
+	if len(highlight) == 0 {
+		return nil
+	}
+
+	parsed := make(map[string][]string, len(highlight))
+	for field, value := range highlight {
+		fragments, _ := value.([]any)
+		for _, fragment := range fragments {
+			if text, ok := fragment.(string); ok {
+				parsed[field] = append(parsed[field], text)
+			}
+		}
+	}
+	return parsed
+}
+
+// parseHits はhitsオブジェクトから個別のヒットを抽出する
+func parseHits(hits map[string]any) []entity.Hit {
+	hitsList, ok := hits["hits"].([]any)
+	if !ok {
+		return nil
+	}
+
+	parsed := make([]entity.Hit, 0, len(hitsList))
+	for _, hit := range hitsList {
+		hitMap, ok := hit.(map[string]any)
+		if !ok {
+			continue
+		}
+		entityHit := entity.Hit{
+			Index:   getString(hitMap, "_index"),
+			ID:      getString(hitMap, "_id"),
+			Score:   getFloat64(hitMap, "_score"),
+			Source:  getSource(hitMap),
+			Routing: getString(hitMap, "_routing"),
+		}
+		if sort, ok := hitMap["sort"].([]any); ok {
+			entityHit.Sort = sort
+		}
+		entityHit.Highlight = parseHighlight(getMap(hitMap, "highlight"))
+		entityHit.Fields = parseFields(getMap(hitMap, "fields"))
+		if explanation := getMap(hitMap, "_explanation"); explanation != nil {
+			parsed := parseExplanation(explanation)
+			entityHit.Explanation = &parsed
+		}
+		if seqNo, ok := getInt64(hitMap, "_seq_no"); ok {
+			entityHit.SeqNo = &seqNo
+		}
+		if primaryTerm, ok := getInt64(hitMap, "_primary_term"); ok {
+			entityHit.PrimaryTerm = &primaryTerm
+		}
+
+		// フィールドコラプスのインナーヒットを抽出
+		if innerHits := getMap(getMap(hitMap, "inner_hits"), collapseInnerHitsName); innerHits != nil {
+			entityHit.Collapsed = parseHits(getMap(innerHits, "hits"))
+		}
+
+		// ネストクエリのインナーヒットを抽出
+		if innerHits := getMap(getMap(hitMap, "inner_hits"), nestedInnerHitsName); innerHits != nil {
+			entityHit.Nested = parseHits(getMap(innerHits, "hits"))
+		}
+
+		parsed = append(parsed, entityHit)
+	}
+	return parsed
+}
+
+// buildBulkResult はバルクレスポンスからアイテムごとの結果を構築する
+func buildBulkResult(result map[string]any) *entity.BulkResult {
+	bulkResult := &entity.BulkResult{}
+	if took, ok := getInt64(result, "took"); ok {
+		bulkResult.Took = took
+	}
+
+	items, _ := result["items"].([]any)
+	for _, item := range items {
+		itemMap, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		// 各アイテムは {"<action>": {...}} の形式
+		for _, actionResult := range itemMap {
+			actionMap, ok := actionResult.(map[string]any)
+			if !ok {
+				continue
+			}
+			itemResult := entity.BulkItemResult{
+				Index:  getString(actionMap, "_index"),
+				ID:     getString(actionMap, "_id"),
+				Status: int(getInt64Value(actionMap, "status")),
+				Result: getString(actionMap, "result"),
+			}
+			if errorInfo := getMap(actionMap, "error"); errorInfo != nil {
+				itemResult.ErrorType = getString(errorInfo, "type")
+				itemResult.Error = getString(errorInfo, "reason")
+				if itemResult.Error == "" {
+					itemResult.Error = extractErrorReason(errorInfo)
+				}
+			}
+			// バージョン競合（create時の既存IDを含む）は実際の失敗と区別する
+			itemResult.Conflict = itemResult.Status == 409 || itemResult.ErrorType == "version_conflict_engine_exception"
+			bulkResult.AddItem(itemResult)
+		}
+	}
+
+	return bulkResult
+}
+
+// newErrorSearchResult はエラー情報のみを持つ検索結果を作成する
+func newErrorSearchResult(query *entity.SearchQuery, message string) *entity.SearchResult {
+	searchResult := entity.NewSearchResult(*query)
+	searchResult.Error = message
+	return searchResult
+}
+
+// extractErrorReason はElasticsearchのエラーオブジェクトから理由を抽出する
+func extractErrorReason(errorInfo any) string {
+	switch e := errorInfo.(type) {
+	case string:
+		return e
+	case map[string]any:
+		// 根本原因がある場合は "all shards failed" などの要約より具体的な理由を優先する
+		if rootCauses, ok := e["root_cause"].([]any); ok && len(rootCauses) > 0 {
+			if rootCause, ok := rootCauses[0].(map[string]any); ok && getString(rootCause, "reason") != "" {
+				e = rootCause
+			}
+		}
+		reason := getString(e, "reason")
+		errorType := getString(e, "type")
+		switch {
+		case errorType != "" && reason != "":
+			return errorType + ": " + reason
+		case reason != "":
+			return reason
+		case errorType != "":
+			return errorType
+		}
+	}
+	return "unknown error"
+}
+
+// dateMathEscaper は url.PathEscape がそのまま残す日付計算式の文字をエンコードする
+var dateMathEscaper = strings.NewReplacer("+", "%2B", ":", "%3A")
+
+// pathIndex はインデックス指定（カンマ区切り可）をURLのパスに埋め込める形にする
+// esapi はインデックス名をエスケープせずにパスへ連結するため、<logs-{now/d}> のような
+// 日付計算式の < { / } > などをパーセントエンコードする。それ以外の名前はそのまま返す
+// タイムゾーン指定（{now/d{yyyy.MM.dd|+12:00}}）の + と : は url.PathEscape ではエスケープされないが、
+// Elasticsearch は + を空白として復号するため、これらもエンコードする
+func pathIndex(index string) string {
+	parts := strings.Split(index, ",")
+	for i, part := range parts {
+		if entity.IsDateMathIndex(part) {
+			parts[i] = dateMathEscaper.Replace(url.PathEscape(strings.TrimSpace(part)))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// pathIndices は pathIndex を各インデックスに適用する
+func pathIndices(indices []string) []string {
+	escaped := make([]string, len(indices))
+	for i, index := range indices {
+		escaped[i] = pathIndex(index)
+	}
+	return escaped
+}
+
+// decodeErrorReason はElasticsearchのエラーレスポンスボディから理由を抽出する
+func decodeErrorReason(body io.Reader) string {
+	var result map[string]any
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return "unknown error"
+	}
+	return extractErrorReason(result["error"])
+}
+
+// responseLimitError はElasticsearchがリクエストサイズ超過（413）または過負荷（429）を返した場合のエラーを返す
+// それ以外のステータスでは nil を返す。429 の Retry-After はそのままクライアントに伝える
+func responseLimitError(res *esapi.Response) *errors.AppError {
+	switch res.StatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return errors.NewPayloadTooLargeError()
+	case http.StatusTooManyRequests:
+		return errors.NewRateLimitedError(res.Header.Get("Retry-After"))
+	default:
+		return nil
+	}
+}
+
+// setConcurrencyFields はレスポンスからシーケンス番号とプライマリタームを設定する
+func setConcurrencyFields(doc *entity.Document, result map[string]any) {
+	if seqNo, ok := getInt64(result, "_seq_no"); ok {
+		doc.SeqNo = seqNo
+	}
+	if primaryTerm, ok := getInt64(result, "_primary_term"); ok {
+		doc.PrimaryTerm = primaryTerm
+	}
+}
+
 // 型変換用のヘルパー関数
+// レスポンスは json.Number を使って解析されるため（Client.parseResponse を参照）、数値は json.Number と float64 の両方を受け付ける
 func getString(m map[string]any, key string) string {
-	if val, ok := m[key].(string); ok {
+	switch val := m[key].(type) {
+	case string:
 		return val
+	case json.Number:
+		return val.String()
 	}
 	return ""
 }
 
 func getFloat64(m map[string]any, key string) float64 {
-	if val, ok := m[key].(float64); ok {
-		return val
+	val, _ := toFloat64(m[key])
+	return val
+}
+
+// getInt64 は整数値を精度を失わずに取得する（値がない場合は false）
+func getInt64(m map[string]any, key string) (int64, bool) {
+	switch val := m[key].(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, true
+		}
+		if f, err := val.Float64(); err == nil {
+			return int64(f), true
+		}
+	case float64:
+		return int64(val), true
 	}
-	return 0.0
+	return 0, false
+}
+
+func getInt64Value(m map[string]any, key string) int64 {
+	val, _ := getInt64(m, key)
+	return val
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch val := v.(type) {
+	case json.Number:
+		f, err := val.Float64()
+		return f, err == nil
+	case float64:
+		return val, true
+	}
+	return 0, false
+}
+
+// getSource はヒットまたはドキュメント取得結果の _source を返す
+// マッピングで無効化されている、source=false で除外された、null またはオブジェクト以外の場合は nil を返す
+// （オブジェクト以外の _source は通常発生しないが、壊れた応答で検索全体を失敗させない）
+func getSource(m map[string]any) map[string]any {
+	return getMap(m, "_source")
 }
 
 func getMap(m map[string]any, key string) map[string]any {
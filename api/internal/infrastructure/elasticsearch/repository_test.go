@@ -0,0 +1,405 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/config"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// newTestRepository は handler を Elasticsearch のクラスターとして使うリポジトリを作成する
+func newTestRepository(t testing.TB, handler http.HandlerFunc) repository.ElasticsearchRepository {
+	t.Helper()
+	return NewRepository(newTestClient(t, &config.Config{}, handler))
+}
+
+// newTestClient は handler を Elasticsearch のクラスターとして使うクライアントを conf の設定で作成する
+func newTestClient(t testing.TB, conf *config.Config, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClusterClient(conf, "test", []string{server.URL})
+	if err != nil {
+		t.Fatalf("NewClusterClient() error = %v", err)
+	}
+	return client
+}
+
+// writeResponse は Elasticsearch の応答として body を返す（クライアントの製品チェックに必要なヘッダーを含む）
+func writeResponse(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
+
+func TestPathIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		index string
+		want  string
+	}{
+		{name: "plain index", index: "logs-2024.01.01", want: "logs-2024.01.01"},
+		{name: "wildcard", index: "logs-*", want: "logs-*"},
+		{name: "daily", index: "<logs-{now/d}>", want: "%3Clogs-%7Bnow%2Fd%7D%3E"},
+		{name: "previous month with format", index: "<logs-{now/M-1M{yyyy.MM}}>", want: "%3Clogs-%7Bnow%2FM-1M%7Byyyy.MM%7D%7D%3E"},
+		{name: "time zone", index: "<logs-{now/d{yyyy.MM.dd|+12:00}}>", want: "%3Clogs-%7Bnow%2Fd%7Byyyy.MM.dd%7C%2B12%3A00%7D%7D%3E"},
+		{name: "excluded", index: "-<logs-{now/d-1d}>", want: "-%3Clogs-%7Bnow%2Fd-1d%7D%3E"},
+		{name: "mixed list", index: "logs-archive, <logs-{now/d}>", want: "logs-archive,%3Clogs-%7Bnow%2Fd%7D%3E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathIndex(tt.index); got != tt.want {
+				t.Errorf("pathIndex(%q) = %q, want %q", tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathIndexDateMathRoundTrip(t *testing.T) {
+	indices := []string{
+		"<logs-{now/d}>",
+		"<logs-{now/w{yyyy.ww}}>",
+		"<logs-{now/d{yyyy.MM.dd|-05:30}}>",
+		"<logs-{now/H{yyyy.MM.dd.HH|Asia/Tokyo}}>",
+	}
+
+	for _, index := range indices {
+		t.Run(index, func(t *testing.T) {
+			escaped := pathIndex(index)
+			// パスの区切りや Elasticsearch が解釈し直す文字が残っていないこと
+			if strings.ContainsAny(escaped, "<>{}/|+:,") {
+				t.Errorf("pathIndex(%q) = %q, contains unescaped characters", index, escaped)
+			}
+			decoded, err := url.PathUnescape(escaped)
+			if err != nil {
+				t.Fatalf("PathUnescape(%q) error = %v", escaped, err)
+			}
+			if decoded != index {
+				t.Errorf("PathUnescape(pathIndex(%q)) = %q, want the original expression", index, decoded)
+			}
+		})
+	}
+}
+
+func TestBuildMultiSearchResults(t *testing.T) {
+	// 2件目のサブクエリが失敗し、4件目のクエリにはレスポンスが返らなかった _msearch のレスポンス
+	body := `{"responses": [
+		{"took": 3, "hits": {"total": {"value": 1, "relation": "eq"}, "hits": [{"_index": "articles", "_id": "1", "_score": 1.5, "_source": {"title": "Go"}}]}},
+		{"error": {"type": "index_not_found_exception", "reason": "no such index [missing]"}, "status": 404},
+		"unexpected"
+	]}`
+	var response map[string]any
+	if err := (&Client{}).parseResponse(strings.NewReader(body), &response); err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+
+	queries := []*entity.SearchQuery{
+		{Query: "go", Index: "articles"},
+		{Query: "go", Index: "missing"},
+		{Query: "go", Index: "broken"},
+		{Query: "go", Index: "dropped"},
+	}
+	results := (&Repository{}).buildMultiSearchResults(queries, response)
+
+	tests := []struct {
+		index     string
+		wantError string
+		wantHits  int
+	}{
+		{index: "articles", wantHits: 1},
+		{index: "missing", wantError: "index_not_found_exception: no such index [missing]"},
+		{index: "broken", wantError: "invalid response format"},
+		{index: "dropped", wantError: "no response returned for query"},
+	}
+
+	if len(results) != len(tests) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.index, func(t *testing.T) {
+			result := results[i]
+			if result.Query.Index != tt.index {
+				t.Errorf("result query index = %q, want %q", result.Query.Index, tt.index)
+			}
+			if result.Error != tt.wantError {
+				t.Errorf("result error = %q, want %q", result.Error, tt.wantError)
+			}
+			if len(result.Hits) != tt.wantHits {
+				t.Errorf("len(hits) = %d, want %d", len(result.Hits), tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestSearchContextAbortsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		context  func() (context.Context, context.CancelFunc)
+		wantCode errors.ErrorCode
+	}{
+		{
+			name: "deadline exceeded",
+			context: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantCode: errors.ErrCodeTimeout,
+		},
+		{
+			name: "client disconnected",
+			context: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantCode: errors.ErrCodeRequestCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 応答を返さず、クライアントが接続を閉じるまで待つクラスター
+			aborted := make(chan struct{})
+			repo := newTestRepository(t, func(w http.ResponseWriter, r *http.Request) {
+				// ボディを読み切るまでサーバーは接続の切断を検知しない
+				_, _ = io.Copy(io.Discard, r.Body)
+				select {
+				case <-r.Context().Done():
+					close(aborted)
+				case <-time.After(5 * time.Second):
+				}
+			})
+
+			ctx, cancel := tt.context()
+			defer cancel()
+			_, err := repo.Search(ctx, &entity.SearchQuery{Query: "go", Index: "articles", Size: 10})
+			if !errors.HasCode(err, tt.wantCode) {
+				t.Fatalf("Search() error = %v, want %s", err, tt.wantCode)
+			}
+
+			select {
+			case <-aborted:
+			case <-time.After(time.Second):
+				t.Error("the Elasticsearch request was not aborted")
+			}
+		})
+	}
+}
+
+func TestCreateIndexBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  map[string]any
+		settings map[string]any
+		wantKeys []string
+	}{
+		{name: "mapping and settings", mapping: map[string]any{"properties": map[string]any{}}, settings: map[string]any{"number_of_shards": 1}, wantKeys: []string{"mappings", "settings"}},
+		{name: "settings only", settings: map[string]any{"number_of_replicas": 0}, wantKeys: []string{"settings"}},
+		{name: "neither", wantKeys: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			repo := newTestRepository(t, func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				writeResponse(w, http.StatusOK, `{"acknowledged": true, "shards_acknowledged": true, "index": "articles"}`)
+			})
+			if err := repo.CreateIndex(context.Background(), "articles", tt.mapping, tt.settings); err != nil {
+				t.Fatalf("CreateIndex() error = %v", err)
+			}
+
+			keys := make([]string, 0, len(body))
+			for key := range body {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("create index body keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestParseHitsSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  any
+		omit    bool
+		wantNil bool
+	}{
+		{name: "object", source: map[string]any{"title": "Go"}},
+		{name: "empty object", source: map[string]any{}},
+		{name: "excluded", omit: true, wantNil: true},
+		{name: "null", source: nil, wantNil: true},
+		{name: "string", source: "not an object", wantNil: true},
+		{name: "array", source: []any{"a"}, wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit := map[string]any{"_index": "articles", "_id": "1", "_score": 1.0}
+			if !tt.omit {
+				hit["_source"] = tt.source
+			}
+
+			hits := parseHits(map[string]any{"hits": []any{hit}})
+			if len(hits) != 1 {
+				t.Fatalf("len(hits) = %d, want 1", len(hits))
+			}
+			if got := hits[0].Source == nil; got != tt.wantNil {
+				t.Errorf("Source = %v, want nil = %v", hits[0].Source, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestGetDocumentWithoutSource(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "source disabled", body: `{"_index": "articles", "_id": "1", "_version": 2, "found": true}`},
+		{name: "null source", body: `{"_index": "articles", "_id": "1", "_version": 2, "found": true, "_source": null}`},
+		{name: "non-object source", body: `{"_index": "articles", "_id": "1", "_version": 2, "found": true, "_source": "broken"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepository(t, func(w http.ResponseWriter, r *http.Request) {
+				writeResponse(w, http.StatusOK, tt.body)
+			})
+
+			doc, err := repo.GetDocument(context.Background(), "articles", "1")
+			if err != nil {
+				t.Fatalf("GetDocument() error = %v", err)
+			}
+			if doc.Source == nil || len(doc.Source) != 0 {
+				t.Errorf("Source = %v, want an empty non-nil map", doc.Source)
+			}
+			if doc.ID != "1" || doc.Version != 2 {
+				t.Errorf("document = %s (version %d), want 1 (version 2)", doc.ID, doc.Version)
+			}
+		})
+	}
+}
+
+func TestBuildQueryClauseAnalyzer(t *testing.T) {
+	tests := []struct {
+		mode   entity.SearchMode
+		clause string
+	}{
+		{mode: entity.SearchModeMatch, clause: "multi_match"},
+		{mode: entity.SearchModeQueryString, clause: "query_string"},
+		{mode: entity.SearchModeSimpleQueryString, clause: "simple_query_string"},
+		{mode: entity.SearchModeSearchAsYouType, clause: "multi_match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			for _, analyzer := range []string{"", "kuromoji"} {
+				query := &entity.SearchQuery{Query: "go", Mode: tt.mode, Fields: []string{"title"}, Analyzer: analyzer}
+				clause, ok := buildQueryClause(query)[tt.clause].(map[string]any)
+				if !ok {
+					t.Fatalf("buildQueryClause() has no %s clause", tt.clause)
+				}
+
+				got, exists := clause["analyzer"]
+				if analyzer == "" && exists {
+					t.Errorf("analyzer = %v, want it omitted", got)
+				}
+				if analyzer != "" && got != analyzer {
+					t.Errorf("analyzer = %v, want %s", got, analyzer)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildSearchQueryFilterOrder(t *testing.T) {
+	query := &entity.SearchQuery{
+		Query:   "go",
+		Size:    10,
+		Filters: map[string]string{"status": "published", "author": "alice", "category": "tech", "_facets": "category"},
+	}
+
+	// フィルターはマップの反復順に関わらずフィールド名順に並ぶ
+	for range 10 {
+		boolQuery := (&Repository{}).buildSearchQuery(query)["query"].(map[string]any)["bool"].(map[string]any)
+		filters := boolQuery["filter"].([]map[string]any)
+
+		var fields []string
+		for _, filter := range filters {
+			for field := range filter["term"].(map[string]any) {
+				fields = append(fields, field)
+			}
+		}
+		if got := strings.Join(fields, ","); got != "author,category,status" {
+			t.Fatalf("filter fields = %s, want author,category,status", got)
+		}
+	}
+}
+
+func TestSearchSeqNoPrimaryTerm(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		useNumber bool
+	}{
+		{name: "disabled"},
+		{name: "enabled", enabled: true},
+		{name: "enabled with json.Number", enabled: true, useNumber: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			client := newTestClient(t, &config.Config{ElasticsearchUseNumber: tt.useNumber}, func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&body)
+
+				// Elasticsearch は seq_no_primary_term を指定した場合のみヒットに _seq_no と _primary_term を含める
+				hit := `{"_index":"articles","_id":"1","_score":1.0,"_source":{"title":"Go"}}`
+				if body["seq_no_primary_term"] == true {
+					hit = `{"_index":"articles","_id":"1","_score":1.0,"_seq_no":42,"_primary_term":3,"_source":{"title":"Go"}}`
+				}
+				writeResponse(w, http.StatusOK, `{"took":1,"hits":{"total":{"value":1,"relation":"eq"},"hits":[`+hit+`]}}`)
+			})
+
+			query := &entity.SearchQuery{Query: "go", Index: "articles", Size: 10, SeqNoPrimaryTerm: tt.enabled}
+			result, err := NewRepository(client).Search(context.Background(), query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			if _, ok := body["seq_no_primary_term"]; ok != tt.enabled {
+				t.Errorf("seq_no_primary_term in the search body = %v, want %v", ok, tt.enabled)
+			}
+			if len(result.Hits) != 1 {
+				t.Fatalf("len(Hits) = %d, want 1", len(result.Hits))
+			}
+			hit := result.Hits[0]
+			if !tt.enabled {
+				if hit.SeqNo != nil || hit.PrimaryTerm != nil {
+					t.Errorf("SeqNo = %v, PrimaryTerm = %v, want nil", hit.SeqNo, hit.PrimaryTerm)
+				}
+				return
+			}
+			if hit.SeqNo == nil || *hit.SeqNo != 42 || hit.PrimaryTerm == nil || *hit.PrimaryTerm != 3 {
+				t.Errorf("SeqNo = %v, PrimaryTerm = %v, want 42 and 3", hit.SeqNo, hit.PrimaryTerm)
+			}
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// TenantPlaceholder はインデックスのプレフィックス形式中のテナントIDの置き換え位置
+const TenantPlaceholder = "{tenant}"
+
+// DefaultPrefixPattern はデフォルトのインデックスのプレフィックス形式（例: テナント "acme" のインデックス "logs" は "acme-logs"）
+const DefaultPrefixPattern = TenantPlaceholder + "-"
+
+// Repository はコンテキストのテナントに応じてインデックス名にプレフィックスを付与する ElasticsearchRepository の実装
+// 呼び出し元（ドメインサービス）はプレフィックスなしの論理的なインデックス名のみを扱い、
+// 結果に含まれるインデックス名からはプレフィックスを取り除いて返す
+type Repository struct {
+	inner   repository.ElasticsearchRepository
+	pattern string
+}
+
+// NewRepository はテナントごとにインデックスを分離するリポジトリを作成する
+// pattern は {tenant} をちょうど1つ含み、その直後はテナントIDに使えない区切り文字である必要がある
+// （例: "{tenant}-"、"t_{tenant}."）。区切り文字がない場合、テナント "a" のワイルドカードが
+// テナント "ab" のインデックスにも一致してしまうため
+func NewRepository(inner repository.ElasticsearchRepository, pattern string) (repository.ElasticsearchRepository, error) {
+	if pattern == "" {
+		pattern = DefaultPrefixPattern
+	}
+	if strings.Count(pattern, TenantPlaceholder) != 1 {
+		return nil, fmt.Errorf("tenant index prefix %q must contain %s exactly once", pattern, TenantPlaceholder)
+	}
+	_, after, _ := strings.Cut(pattern, TenantPlaceholder)
+	if after == "" || isTenantChar(after[0]) {
+		return nil, fmt.Errorf("tenant index prefix %q must have a separator after %s", pattern, TenantPlaceholder)
+	}
+	if strings.ContainsAny(pattern, `*,"\/|?<> #:`) || pattern != strings.ToLower(pattern) {
+		return nil, fmt.Errorf("tenant index prefix %q contains characters not allowed in index names", pattern)
+	}
+
+	return &Repository{
+		inner:   inner,
+		pattern: pattern,
+	}, nil
+}
+
+// isTenantChar はテナントIDに使える文字かどうかを返す
+func isTenantChar(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('0' <= c && c <= '9')
+}
+
+// prefix はコンテキストのテナントのプレフィックスを返す（テナントがない場合はアクセスを拒否する）
+func (r *Repository) prefix(ctx context.Context) (string, error) {
+	tenant := auth.Tenant(ctx)
+	if tenant == "" {
+		return "", errors.NewAppError(errors.ErrCodeForbidden, "Tenant is required")
+	}
+	return strings.Replace(r.pattern, TenantPlaceholder, tenant, 1), nil
+}
+
+// scopeIndex は論理的なインデックス名（カンマ区切り、ワイルドカード、"-" による除外を含む）を
+// テナントのインデックス名に変換する。空や "_all" はテナントの全インデックスを対象にする
+func scopeIndex(prefix, index string) string {
+	parts := strings.Split(index, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		exclude := strings.HasPrefix(part, "-")
+		part = strings.TrimPrefix(part, "-")
+		if part == "" || part == "_all" {
+			part = "*"
+		}
+		// 日付計算式は式の内側にプレフィックスを付ける（<logs-{now/d}> → <acme-logs-{now/d}>）
+		if entity.IsDateMathIndex(part) {
+			part = "<" + prefix + strings.TrimPrefix(part, "<")
+			if exclude {
+				part = "-" + part
+			}
+			parts[i] = part
+			continue
+		}
+		if exclude {
+			parts[i] = "-" + prefix + part
+		} else {
+			parts[i] = prefix + part
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// unscopeIndex はテナントのインデックス名からプレフィックスを取り除く
+func unscopeIndex(prefix, index string) string {
+	return strings.TrimPrefix(index, prefix)
+}
+
+// unscopeHits はヒット（コラプスやネストしたヒットを含む）のインデックス名からプレフィックスを取り除く
+func unscopeHits(prefix string, hits []entity.Hit) {
+	for i := range hits {
+		hits[i].Index = unscopeIndex(prefix, hits[i].Index)
+		unscopeHits(prefix, hits[i].Collapsed)
+		unscopeHits(prefix, hits[i].Nested)
+	}
+}
+
+// scopedIndex はインデックス名をテナントのものに変換する
+func (r *Repository) scopedIndex(ctx context.Context, index string) (string, error) {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	return scopeIndex(prefix, index), nil
+}
+
+// withDocument はインデックス名をテナントのものに置き換えたドキュメントで fn を実行し、
+// リポジトリが設定した値（ID やバージョンなど）を元のドキュメントに反映する
+func (r *Repository) withDocument(ctx context.Context, doc *entity.Document, fn func(doc *entity.Document) error) error {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	scoped := *doc
+	scoped.Index = scopeIndex(prefix, doc.Index)
+	err = fn(&scoped)
+	scoped.Index = doc.Index
+	*doc = scoped
+	return err
+}
+
+// ドキュメント操作
+
+// CreateDocument はテナントのインデックスにドキュメントを作成する
+func (r *Repository) CreateDocument(ctx context.Context, doc *entity.Document) error {
+	return r.withDocument(ctx, doc, func(doc *entity.Document) error {
+		return r.inner.CreateDocument(ctx, doc)
+	})
+}
+
+// GetDocument はテナントのインデックスからドキュメントを取得する
+func (r *Repository) GetDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) (*entity.Document, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := r.inner.GetDocument(ctx, scoped, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	doc.Index = index
+	return doc, nil
+}
+
+// GetDocumentRaw はテナントのインデックスからドキュメントの_sourceをストリームとして取得する
+func (r *Repository) GetDocumentRaw(ctx context.Context, index, id string, opts ...repository.DocumentOption) (io.ReadCloser, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return r.inner.GetDocumentRaw(ctx, scoped, id, opts...)
+}
+
+// UpdateDocument はテナントのインデックスのドキュメントを更新する
+func (r *Repository) UpdateDocument(ctx context.Context, doc *entity.Document) error {
+	return r.withDocument(ctx, doc, func(doc *entity.Document) error {
+		return r.inner.UpdateDocument(ctx, doc)
+	})
+}
+
+// UpdateDocumentIfMatch はテナントのインデックスのドキュメントを楽観的ロック付きで更新する
+func (r *Repository) UpdateDocumentIfMatch(ctx context.Context, doc *entity.Document, seqNo, primaryTerm int64) error {
+	return r.withDocument(ctx, doc, func(doc *entity.Document) error {
+		return r.inner.UpdateDocumentIfMatch(ctx, doc, seqNo, primaryTerm)
+	})
+}
+
+// UpsertDocument はテナントのインデックスのドキュメントを部分更新する（存在しない場合は作成する）
+func (r *Repository) UpsertDocument(ctx context.Context, doc *entity.Document) error {
+	return r.withDocument(ctx, doc, func(doc *entity.Document) error {
+		return r.inner.UpsertDocument(ctx, doc)
+	})
+}
+
+// ReplaceDocument はテナントのインデックスのドキュメントを置き換える
+func (r *Repository) ReplaceDocument(ctx context.Context, doc *entity.Document) (bool, error) {
+	var created bool
+	err := r.withDocument(ctx, doc, func(doc *entity.Document) error {
+		var err error
+		created, err = r.inner.ReplaceDocument(ctx, doc)
+		return err
+	})
+	return created, err
+}
+
+// DeleteDocument はテナントのインデックスからドキュメントを削除する
+func (r *Repository) DeleteDocument(ctx context.Context, index, id string, opts ...repository.DocumentOption) error {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	return r.inner.DeleteDocument(ctx, scoped, id, opts...)
+}
+
+// 検索操作
+
+// Search はテナントのインデックスを検索する
+func (r *Repository) Search(ctx context.Context, query *entity.SearchQuery, opts ...repository.SearchOption) (*entity.SearchResult, error) {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *query
+	scoped.Index = scopeIndex(prefix, query.Index)
+	result, err := r.inner.Search(ctx, &scoped, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.Query.Index = query.Index
+	unscopeHits(prefix, result.Hits)
+	return result, nil
+}
+
+// MultiSearch はテナントのインデックスに対して複数の検索を実行する
+func (r *Repository) MultiSearch(ctx context.Context, queries []*entity.SearchQuery) ([]*entity.SearchResult, error) {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := make([]*entity.SearchQuery, len(queries))
+	for i, query := range queries {
+		q := *query
+		q.Index = scopeIndex(prefix, query.Index)
+		scoped[i] = &q
+	}
+
+	results, err := r.inner.MultiSearch(ctx, scoped)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		if i < len(queries) {
+			result.Query.Index = queries[i].Index
+		}
+		unscopeHits(prefix, result.Hits)
+	}
+	return results, nil
+}
+
+// MoreLikeThis はテナントのインデックスから類似ドキュメントを検索する
+func (r *Repository) MoreLikeThis(ctx context.Context, query *entity.MoreLikeThisQuery) (*entity.SearchResult, error) {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *query
+	scoped.Index = scopeIndex(prefix, query.Index)
+	result, err := r.inner.MoreLikeThis(ctx, &scoped)
+	if err != nil {
+		return nil, err
+	}
+	result.Query.Index = unscopeIndex(prefix, result.Query.Index)
+	unscopeHits(prefix, result.Hits)
+	return result, nil
+}
+
+// RenderSearchQuery は検索クエリの本文を返す（インデックス名を含まないためそのまま委譲する）
+func (r *Repository) RenderSearchQuery(query *entity.SearchQuery) map[string]any {
+	return r.inner.RenderSearchQuery(query)
+}
+
+// ValidateQuery はテナントのインデックスに対してクエリを検証する
+func (r *Repository) ValidateQuery(ctx context.Context, query *entity.SearchQuery) (*entity.QueryValidation, error) {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *query
+	scoped.Index = scopeIndex(prefix, query.Index)
+	validation, err := r.inner.ValidateQuery(ctx, &scoped)
+	if err != nil {
+		return nil, err
+	}
+	for i := range validation.Explanations {
+		validation.Explanations[i].Index = unscopeIndex(prefix, validation.Explanations[i].Index)
+	}
+	return validation, nil
+}
+
+// TermsAggregation はテナントのインデックスでフィールドの値ごとのドキュメント数を集計する
+// 結果のインデックス名は呼び出し元が指定した論理的な名前のまま返す
+func (r *Repository) TermsAggregation(ctx context.Context, index, field string, size int) (*entity.TermsResult, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.inner.TermsAggregation(ctx, scoped, field, size)
+	if err != nil {
+		return nil, err
+	}
+	result.Index = index
+	return result, nil
+}
+
+// インデックス操作
+
+// CreateIndex はテナントのインデックスを作成する
+func (r *Repository) CreateIndex(ctx context.Context, index string, mapping, settings map[string]any) error {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	return r.inner.CreateIndex(ctx, scoped, mapping, settings)
+}
+
+// DeleteIndex はテナントのインデックスを削除する
+func (r *Repository) DeleteIndex(ctx context.Context, index string) error {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	return r.inner.DeleteIndex(ctx, scoped)
+}
+
+// IndexExists はテナントのインデックスが存在するかどうかを返す
+func (r *Repository) IndexExists(ctx context.Context, index string) (bool, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return false, err
+	}
+	return r.inner.IndexExists(ctx, scoped)
+}
+
+// IndexStats はテナントのインデックスの統計情報を返す
+func (r *Repository) IndexStats(ctx context.Context, index string) (map[string]any, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := r.inner.IndexStats(ctx, scoped)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := stats["index"]; ok {
+		stats["index"] = index
+	}
+	return stats, nil
+}
+
+// OpenIndex はテナントのインデックスをオープンする
+func (r *Repository) OpenIndex(ctx context.Context, index string) error {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	return r.inner.OpenIndex(ctx, scoped)
+}
+
+// CloseIndex はテナントのインデックスをクローズする
+func (r *Repository) CloseIndex(ctx context.Context, index string) error {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	return r.inner.CloseIndex(ctx, scoped)
+}
+
+// Refresh はテナントのインデックスをリフレッシュする
+func (r *Repository) Refresh(ctx context.Context, index string) error {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+	return r.inner.Refresh(ctx, scoped)
+}
+
+// ForceMerge はテナントのインデックスのセグメント数を削減する
+func (r *Repository) ForceMerge(ctx context.Context, index string, maxSegments int) (string, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return "", err
+	}
+	return r.inner.ForceMerge(ctx, scoped, maxSegments)
+}
+
+// GetFieldType はテナントのインデックスのフィールドの型を返す
+func (r *Repository) GetFieldType(ctx context.Context, index, field string) (string, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return "", err
+	}
+	return r.inner.GetFieldType(ctx, scoped, field)
+}
+
+// FieldCapabilities はテナントのインデックスのフィールドの型と集約に使えるかどうかを返す
+func (r *Repository) FieldCapabilities(ctx context.Context, index, field string) (*entity.FieldCapability, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return r.inner.FieldCapabilities(ctx, scoped, field)
+}
+
+// MaxResultWindow はテナントのインデックスの max_result_window を返す
+func (r *Repository) MaxResultWindow(ctx context.Context, index string) (int, error) {
+	scoped, err := r.scopedIndex(ctx, index)
+	if err != nil {
+		return 0, err
+	}
+	return r.inner.MaxResultWindow(ctx, scoped)
+}
+
+// ScrollDocuments はテナントのインデックスのドキュメントを順に読み出す
+func (r *Repository) ScrollDocuments(ctx context.Context, index string, query *entity.SearchQuery, batchSize int, fn func(hits []entity.Hit) error) error {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	return r.inner.ScrollDocuments(ctx, scopeIndex(prefix, index), query, batchSize, func(hits []entity.Hit) error {
+		unscopeHits(prefix, hits)
+		return fn(hits)
+	})
+}
+
+// バルク操作
+
+// BulkIndex はテナントのインデックスにドキュメントを一括登録する
+func (r *Repository) BulkIndex(ctx context.Context, documents []*entity.Document, opType entity.BulkOpType, opts ...repository.DocumentOption) (*entity.BulkResult, error) {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := make([]*entity.Document, len(documents))
+	for i, doc := range documents {
+		d := *doc
+		d.Index = scopeIndex(prefix, doc.Index)
+		scoped[i] = &d
+	}
+
+	result, err := r.inner.BulkIndex(ctx, scoped, opType, opts...)
+
+	// リポジトリが設定した値（ID など）を元のドキュメントに反映する
+	for i, doc := range documents {
+		index := doc.Index
+		*doc = *scoped[i]
+		doc.Index = index
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Items {
+		result.Items[i].Index = unscopeIndex(prefix, result.Items[i].Index)
+	}
+	return result, nil
+}
+
+// BulkDelete はテナントのインデックスからドキュメントを一括削除する
+func (r *Repository) BulkDelete(ctx context.Context, indices []string, ids []string, opts ...repository.DocumentOption) error {
+	prefix, err := r.prefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	scoped := make([]string, len(indices))
+	for i, index := range indices {
+		scoped[i] = scopeIndex(prefix, index)
+	}
+	return r.inner.BulkDelete(ctx, scoped, ids, opts...)
+}
+
+// ヘルスチェックと情報取得（クラスタ全体の操作のためテナントに関わらず委譲する）
+
+// Health はクラスタの状態を確認する
+func (r *Repository) Health(ctx context.Context) error {
+	return r.inner.Health(ctx)
+}
+
+// Info はクラスタの情報を返す
+func (r *Repository) Info(ctx context.Context) (map[string]any, error) {
+	return r.inner.Info(ctx)
+}
@@ -0,0 +1,26 @@
+package tenancy
+
+import "testing"
+
+func TestScopeIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		index string
+		want  string
+	}{
+		{name: "plain index", index: "logs", want: "acme-logs"},
+		{name: "all indices", index: "", want: "acme-*"},
+		{name: "excluded index", index: "logs-*,-logs-old", want: "acme-logs-*,-acme-logs-old"},
+		{name: "date math", index: "<logs-{now/d}>", want: "<acme-logs-{now/d}>"},
+		{name: "date math with format", index: "<logs-{now/M{yyyy.MM|+09:00}}>", want: "<acme-logs-{now/M{yyyy.MM|+09:00}}>"},
+		{name: "excluded date math", index: "logs-*, -<logs-{now/d}>", want: "acme-logs-*,-<acme-logs-{now/d}>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopeIndex("acme-", tt.index); got != tt.want {
+				t.Errorf("scopeIndex(%q) = %q, want %q", tt.index, got, tt.want)
+			}
+		})
+	}
+}
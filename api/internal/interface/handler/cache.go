@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+)
+
+// CacheHealth はヘルスチェック結果のキャッシュ名
+const CacheHealth = "health"
+
+// CacheHandler はプロセス内キャッシュの管理リクエストを処理する
+type CacheHandler struct {
+	searchUseCase usecase.SearchUseCaser
+	healthHandler *HealthHandler
+}
+
+// NewCacheHandler は新しい CacheHandler を作成する
+func NewCacheHandler(searchUseCase usecase.SearchUseCaser, healthHandler *HealthHandler) *CacheHandler {
+	return &CacheHandler{
+		searchUseCase: searchUseCase,
+		healthHandler: healthHandler,
+	}
+}
+
+// ClearCaches はプロセス内キャッシュの削除リクエストを処理する（管理者用）
+// POST /admin/cache/clear
+// POST /admin/cache/clear/{index}
+//
+// 検索のキャッシュ（max_result_window、インデックスの存在確認、マッピング上のフィールド、検索結果）とヘルスチェック結果を削除し、
+// 削除したエントリ数を返す。インデックスを指定した場合はそのインデックスのエントリのみを削除する
+func (h *CacheHandler) ClearCaches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出（省略時はすべてのインデックス）
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "clear_cache", index)
+
+	// キャッシュを削除
+	result := h.searchUseCase.ClearCaches(index)
+	evicted := h.healthHandler.ClearCache(index)
+	result.Caches[CacheHealth] = evicted
+	result.Evicted += evicted
+
+	// 成功レスポンスを返す
+	rw.WriteSuccess(result, "Caches cleared successfully")
+}
+
+// OptionsHandler はCORSプリフライトリクエストを処理する
+func (h *CacheHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.SetCORSHeaders(w)
+	w.WriteHeader(http.StatusOK)
+}
@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
 	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
 )
 
@@ -22,7 +28,10 @@ func NewDocumentHandler(documentUseCase *usecase.DocumentUseCase) *DocumentHandl
 }
 
 // CreateDocument はドキュメント作成リクエストを処理する
-// POST /documents
+// POST /documents?routing={routing}&pipeline={pipeline}&refresh={true|false|wait_for}&async={true|false}&raw={true|false}&index={index}
+//
+// Idempotency-Key ヘッダーを指定した場合、同じキーでの再送には新たに作成せず最初の結果を返す（Idempotent-Replayed: true）
+// raw=true の場合はボディをドキュメントそのものとして扱い、map に変換せずに登録する（インデックスは index パラメータで指定）
 func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -31,26 +40,222 @@ func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request)
 	utils.SetCORSHeaders(w)
 	utils.SetSecurityHeaders(w)
 
+	if r.URL.Query().Get("raw") == "true" {
+		h.createRawDocument(w, r)
+		return
+	}
+
 	// リクエストボディを解析
+	// 空のボディは {} と同じく扱い、どちらもユースケースの検証で source の欠落として400を返す
 	var req dto.CreateDocumentRequest
-	if err := utils.ParseRequestBody(r, &req); err != nil {
+	if err := utils.ParseRequestBody(r, &req); err != nil && !utils.IsEmptyBody(err) {
+		rw.WriteError(err)
+		return
+	}
+	req.Routing = resolveRouting(r, req.Routing)
+	req.Pipeline = resolvePipeline(r, req.Pipeline)
+	req.Refresh = r.URL.Query().Get("refresh")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "create_document", req.Index)
+
+	// async=true の場合はキューに積んで202を返す（登録はバックグラウンドで行う）
+	if r.URL.Query().Get("async") == "true" {
+		if r.Header.Get("Idempotency-Key") != "" {
+			rw.WriteBadRequestError("Idempotency-Key cannot be used with async=true")
+			return
+		}
+		// キューの登録は一括登録のリフレッシュ設定に従う
+		if req.Refresh != "" {
+			rw.WriteBadRequestError("refresh cannot be used with async=true")
+			return
+		}
+		queued, err := h.documentUseCase.EnqueueDocument(ctx, &req)
+		if err != nil {
+			rw.WriteError(err)
+			return
+		}
+		middleware.SetLogFields(ctx, "", queued.Index)
+		rw.WriteJSON(http.StatusAccepted, queued)
+		return
+	}
+
+	// ドキュメントを作成
+	result, replayed, err := h.documentUseCase.CreateDocumentIdempotent(ctx, &req, r.Header.Get("Idempotency-Key"))
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+	// インデックス省略時に適用されたデフォルトインデックスをログに残す
+	middleware.SetLogFields(ctx, "", result.Index)
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	// 成功レスポンスを返す
+	rw.WriteCreated(result, "Document created successfully")
+}
+
+// createRawDocument は未加工のJSONボディからドキュメントを作成する
+// ボディはサイズ上限とJSONの構造のみを検証し、数値の精度やフィールドの順序を保ったまま送信する
+func (h *DocumentHandler) createRawDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	req := dto.CreateRawDocumentRequest{
+		Index:    r.URL.Query().Get("index"),
+		Routing:  resolveRouting(r, ""),
+		Pipeline: resolvePipeline(r, ""),
+		Refresh:  r.URL.Query().Get("refresh"),
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "create_document_raw", req.Index)
+
+	// 非同期登録と冪等キーは map のソースを前提とするため併用できない
+	if r.URL.Query().Get("async") == "true" {
+		rw.WriteBadRequestError("async=true cannot be used with raw=true")
+		return
+	}
+	if r.Header.Get("Idempotency-Key") != "" {
+		rw.WriteBadRequestError("Idempotency-Key cannot be used with raw=true")
+		return
+	}
+
+	source, err := utils.ReadRawJSONBody(r)
+	if err != nil {
 		rw.WriteError(err)
 		return
 	}
+	req.Source = source
 
 	// ドキュメントを作成
-	result, err := h.documentUseCase.CreateDocument(ctx, &req)
+	result, err := h.documentUseCase.CreateRawDocument(ctx, &req)
 	if err != nil {
 		rw.WriteError(err)
 		return
 	}
+	// インデックス省略時に適用されたデフォルトインデックスをログに残す
+	middleware.SetLogFields(ctx, "", result.Index)
 
 	// 成功レスポンスを返す
 	rw.WriteCreated(result, "Document created successfully")
 }
 
+// BulkIndexDocuments はバルクインデックスリクエストを処理する
+// POST /documents/_bulk?pipeline={pipeline}&refresh={true|false|wait_for}&validate_only={true|false}
+//
+// Accept: application/x-ndjson を指定した場合は、チャンクごとの進捗と最終結果をNDJSONでストリームする
+// validate_only=true（またはボディの validate_only）の場合は登録せず、検証に失敗するドキュメントのみを返す
+func (h *DocumentHandler) BulkIndexDocuments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// リクエストボディを解析
+	var req dto.BulkIndexRequest
+	if err := utils.ParseRequestBody(r, &req); err != nil {
+		rw.WriteError(err)
+		return
+	}
+	req.Pipeline = resolvePipeline(r, req.Pipeline)
+	req.Refresh = r.URL.Query().Get("refresh")
+	if r.URL.Query().Get("validate_only") == "true" {
+		req.ValidateOnly = true
+	}
+
+	// 登録せずに検証のみを行う
+	if req.ValidateOnly {
+		// ログにインデックスと操作名を含める
+		middleware.SetLogFields(ctx, "bulk_validate", "")
+
+		result, err := h.documentUseCase.ValidateBulkDocuments(ctx, &req)
+		if err != nil {
+			rw.WriteError(err)
+			return
+		}
+		rw.WriteSuccess(result, "Bulk validation completed")
+		return
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "bulk_index", "")
+
+	// Accept: application/x-ndjson の場合は進捗をストリームする
+	if acceptsNDJSON(r) {
+		h.streamBulkIndex(w, r, &req)
+		return
+	}
+
+	// バルクインデックスを実行
+	result, err := h.documentUseCase.BulkIndexDocuments(ctx, &req)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// アイテムごとの結果を返す（一部のアイテムが失敗しても200を返す）
+	rw.WriteSuccess(result, "Bulk indexing completed")
+}
+
+// streamBulkIndex は一括登録の進捗をNDJSONでストリームする
+// チャンクの送信が完了するたびに {"type":"progress",...} を1行書き込んでフラッシュし、最後に
+// {"type":"summary",...}（通常のレスポンスと同じ集計とアイテム）または {"type":"error",...} を書き込む
+// 最初の進捗を書き込むまではヘッダーを送信しないため、検証エラーは通常のJSONエラーレスポンスになる
+func (h *DocumentHandler) streamBulkIndex(w http.ResponseWriter, r *http.Request, req *dto.BulkIndexRequest) {
+	rw := utils.NewResponseWriter(w)
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+
+	result, err := h.documentUseCase.BulkIndexDocumentsWithProgress(r.Context(), req, func(progress *dto.BulkProgressDTO) {
+		start()
+		encoder.Encode(progress)
+		controller.Flush()
+	})
+	if err != nil {
+		if !started {
+			rw.WriteError(err)
+			return
+		}
+		// ストリーム開始後はステータスを変更できないため、エラーを最後の行として書き込む
+		event := &dto.BulkStreamErrorDTO{Type: "error", Error: dto.ErrorDTO{Code: string(errors.ErrCodeInternalError), Message: err.Error()}}
+		if appErr := errors.GetAppError(err); appErr != nil {
+			event.Error = dto.ErrorDTO{Code: string(appErr.Code), Message: appErr.Message, Details: appErr.Details}
+		}
+		encoder.Encode(event)
+		return
+	}
+
+	start()
+	encoder.Encode(&dto.BulkSummaryDTO{Type: "summary", BulkIndexResponse: result})
+}
+
+// acceptsNDJSON はクライアントがNDJSONのレスポンスを要求しているかを返す
+func acceptsNDJSON(r *http.Request) bool {
+	for accept := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), "application/x-ndjson") {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDocument はドキュメント取得リクエストを処理する
-// GET /documents/{index}/{id}
+// GET /documents/{index}/{id}?raw={true|false}&routing={routing}
 func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -63,24 +268,58 @@ func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
 	index := h.getPathParam(r, "index")
 	id := h.getPathParam(r, "id")
 
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "get_document", index)
+
 	if index == "" || id == "" {
 		rw.WriteBadRequestError("Index and ID are required")
 		return
 	}
 
+	// raw=true の場合はElasticsearchのレスポンスをそのままストリームする
+	if r.URL.Query().Get("raw") == "true" {
+		h.streamRawDocument(w, r, index, id)
+		return
+	}
+
 	// ドキュメントを取得
-	result, err := h.documentUseCase.GetDocument(ctx, index, id)
+	result, err := h.documentUseCase.GetDocument(ctx, index, id, r.URL.Query().Get("routing"))
 	if err != nil {
 		rw.WriteError(err)
 		return
 	}
 
+	// 条件付きリクエスト用のETagを設定
+	w.Header().Set("ETag", formatETag(result.SeqNo, result.PrimaryTerm))
+
 	// 成功レスポンスを返す
 	rw.WriteDocument(result, "Document retrieved successfully")
 }
 
+// streamRawDocument はドキュメントの_sourceをバッファせずにクライアントへ書き込む
+func (h *DocumentHandler) streamRawDocument(w http.ResponseWriter, r *http.Request, index, id string) {
+	rw := utils.NewResponseWriter(w)
+
+	body, err := h.documentUseCase.GetDocumentRaw(r.Context(), index, id, r.URL.Query().Get("routing"))
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
+
 // UpdateDocument はドキュメント更新/作成リクエストを処理する
-// PUT /documents/{index}/{id}
+// PUT /documents/{index}/{id}?upsert={true|false}&routing={routing}&retry_on_conflict={n}&refresh={true|false|wait_for}
+//
+// 通常はドキュメントを作成（201）または丸ごと置き換える（200）。
+// If-Match 指定時は既存ドキュメントの条件付き更新（"*" の場合は存在する場合のみ置き換え、存在しなければ412）、
+// upsert=true の場合は既存ソースへのマージとなる。If-Match と upsert=true は併用できない
+// ボディの version で外部バージョンを指定でき、保存済みのバージョン以下の場合は409を返す
+// retry_on_conflict は upsert=true の場合のみ指定できる
 func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -93,6 +332,9 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 	index := h.getPathParam(r, "index")
 	id := h.getPathParam(r, "id")
 
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "update_document", index)
+
 	if index == "" || id == "" {
 		rw.WriteBadRequestError("Index and ID are required")
 		return
@@ -108,20 +350,130 @@ func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request)
 	// パスからインデックスとIDを設定
 	req.Index = index
 	req.ID = id
+	req.Routing = resolveRouting(r, req.Routing)
+	req.Refresh = r.URL.Query().Get("refresh")
+	if value := r.URL.Query().Get("retry_on_conflict"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil {
+			rw.WriteBadRequestError("retry_on_conflict must be an integer")
+			return
+		}
+		req.RetryOnConflict = &retries
+	}
+
+	// If-Match がある場合は楽観的同時実行制御で更新し、
+	// upsert=true の場合は作成または更新を一度に行う
+	ifMatch := r.Header.Get("If-Match")
+	upsert := r.URL.Query().Get("upsert") == "true"
+
+	// 存在しなければ作成する upsert は、既存の版を条件とする If-Match と両立しない
+	if ifMatch != "" && upsert {
+		rw.WriteBadRequestError("If-Match cannot be combined with upsert")
+		return
+	}
+	// 外部バージョンは通常の作成・置き換えでのみ使用できる
+	if req.Version > 0 && (ifMatch != "" || upsert) {
+		rw.WriteBadRequestError("version cannot be combined with If-Match or upsert")
+		return
+	}
+	// 競合時の再試行は部分更新でのみ行われる
+	if req.RetryOnConflict != nil && !upsert {
+		rw.WriteBadRequestError("retry_on_conflict requires upsert=true")
+		return
+	}
 
-	// ドキュメントを更新
-	result, err := h.documentUseCase.UpdateDocument(ctx, &req)
+	var (
+		result  *dto.DocumentDTO
+		created bool
+		err     error
+	)
+	if ifMatch == "*" {
+		// 既存のドキュメントがある場合のみ置き換える
+		result, err = h.documentUseCase.UpdateDocument(ctx, &req)
+		if errors.HasCode(err, errors.ErrCodeDocumentNotFound) {
+			err = errors.NewAppError(errors.ErrCodePreconditionFailed, fmt.Sprintf("Document does not exist: %s/%s", index, id))
+		}
+	} else if ifMatch != "" {
+		seqNo, primaryTerm, ok := parseETag(ifMatch)
+		if !ok {
+			rw.WriteError(errors.NewPreconditionFailedError(index, id))
+			return
+		}
+		result, err = h.documentUseCase.UpdateDocumentIfMatch(ctx, &req, seqNo, primaryTerm)
+	} else if upsert {
+		result, err = h.documentUseCase.UpsertDocument(ctx, &req)
+	} else {
+		result, created, err = h.documentUseCase.ReplaceDocument(ctx, &req)
+	}
 	if err != nil {
 		rw.WriteError(err)
 		return
 	}
 
-	// 成功レスポンスを返す
+	// 更新後のETagを設定
+	w.Header().Set("ETag", formatETag(result.SeqNo, result.PrimaryTerm))
+
+	// 新規作成の場合は201、置き換えの場合は200を返す
+	if created {
+		rw.WriteCreated(result, "Document created successfully")
+		return
+	}
 	rw.WriteDocument(result, "Document updated successfully")
 }
 
+// DiffDocument は変更案のソースと現在のドキュメントの差分を返す（書き込みは行わない）
+// POST /documents/{index}/{id}/_diff?routing={routing}
+//
+// レスポンスのETagは比較に使ったドキュメントのもので、If-Match に指定して更新すれば
+// プレビュー後に他の更新が入っていないことを保証できる
+func (h *DocumentHandler) DiffDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := h.getPathParam(r, "index")
+	id := h.getPathParam(r, "id")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "diff_document", index)
+
+	if index == "" || id == "" {
+		rw.WriteBadRequestError("Index and ID are required")
+		return
+	}
+
+	// リクエストボディを解析
+	var req dto.DiffDocumentRequest
+	if err := utils.ParseRequestBody(r, &req); err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// パスからインデックスとIDを設定
+	req.Index = index
+	req.ID = id
+	req.Routing = resolveRouting(r, req.Routing)
+
+	// 差分を計算
+	result, err := h.documentUseCase.DiffDocument(ctx, &req)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 比較に使ったドキュメントのETagを設定
+	w.Header().Set("ETag", formatETag(result.SeqNo, result.PrimaryTerm))
+
+	rw.WriteSuccess(result, "")
+}
+
 // DeleteDocument はドキュメント削除リクエストを処理する
-// DELETE /documents/{index}/{id}
+// DELETE /documents/{index}/{id}?routing={routing}&refresh={true|false|wait_for}
+// If-Match 指定時は、ドキュメントが指定したETagの版のままの場合のみ削除する（変更されていれば412）
 func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -134,6 +486,9 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request)
 	index := h.getPathParam(r, "index")
 	id := h.getPathParam(r, "id")
 
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "delete_document", index)
+
 	if index == "" || id == "" {
 		rw.WriteBadRequestError("Index and ID are required")
 		return
@@ -141,8 +496,21 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request)
 
 	// 削除リクエストを作成
 	req := &dto.DeleteDocumentRequest{
-		Index: index,
-		ID:    id,
+		Index:   index,
+		ID:      id,
+		Routing: r.URL.Query().Get("routing"),
+		Refresh: r.URL.Query().Get("refresh"),
+	}
+
+	// If-Match がある場合は楽観的同時実行制御で削除する
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" {
+		seqNo, primaryTerm, ok := parseETag(ifMatch)
+		if !ok {
+			rw.WriteError(errors.NewPreconditionFailedError(index, id))
+			return
+		}
+		req.IfSeqNo = &seqNo
+		req.IfPrimaryTerm = &primaryTerm
 	}
 
 	// ドキュメントを削除
@@ -156,12 +524,58 @@ func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request)
 	rw.WriteNoContent()
 }
 
+// resolveRouting はルーティング値を返す（?routing= クエリパラメータをボディの値より優先する）
+func resolveRouting(r *http.Request, bodyRouting string) string {
+	if routing := r.URL.Query().Get("routing"); routing != "" {
+		return routing
+	}
+	return bodyRouting
+}
+
+// resolvePipeline はインジェストパイプライン名を返す（?pipeline= クエリパラメータをボディの値より優先する）
+func resolvePipeline(r *http.Request, bodyPipeline string) string {
+	if pipeline := r.URL.Query().Get("pipeline"); pipeline != "" {
+		return pipeline
+	}
+	return bodyPipeline
+}
+
 // OptionsHandler はCORSプリフライトリクエストを処理する
 func (h *DocumentHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
 	utils.SetCORSHeaders(w)
 	w.WriteHeader(http.StatusOK)
 }
 
+// formatETag はシーケンス番号とプライマリタームからETagを生成する
+func formatETag(seqNo, primaryTerm int64) string {
+	return fmt.Sprintf(`"%d-%d"`, seqNo, primaryTerm)
+}
+
+// parseETag はETagからシーケンス番号とプライマリタームを取り出す
+// 弱いETagは強い比較に使えないため不一致として扱う
+func parseETag(etag string) (int64, int64, bool) {
+	etag = strings.TrimSpace(etag)
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 2 {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(strings.Trim(etag, `"`), "-")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	seqNo, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	primaryTerm, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return seqNo, primaryTerm, true
+}
+
 // getPathParam はリクエストからパスパラメータを抽出する
 func (h *DocumentHandler) getPathParam(r *http.Request, param string) string {
 	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
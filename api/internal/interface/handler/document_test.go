@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/entity"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/service"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// missingDocumentRepository は全てのドキュメントが存在しないものとして振る舞うリポジトリ
+// 書き込み系のメソッドは呼ばれない前提のため埋め込んだインターフェースは nil のまま
+type missingDocumentRepository struct {
+	repository.ElasticsearchRepository
+}
+
+func (missingDocumentRepository) GetDocument(_ context.Context, index, id string, _ ...repository.DocumentOption) (*entity.Document, error) {
+	return nil, errors.NewDocumentNotFoundError(index, id)
+}
+
+func TestUpdateDocumentPreconditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		ifMatch    string
+		body       string
+		wantStatus int
+	}{
+		{name: "If-Match with upsert", query: "?upsert=true", ifMatch: `"1-1"`, wantStatus: http.StatusBadRequest},
+		{name: "If-Match * with upsert", query: "?upsert=true", ifMatch: "*", wantStatus: http.StatusBadRequest},
+		{name: "version with If-Match *", ifMatch: "*", body: `{"source":{"name":"a"},"version":3}`, wantStatus: http.StatusBadRequest},
+		{name: "retry_on_conflict without upsert", query: "?retry_on_conflict=2", wantStatus: http.StatusBadRequest},
+		{name: "unsupported refresh", query: "?refresh=always", wantStatus: http.StatusBadRequest},
+		{name: "If-Match * on a missing document", ifMatch: "*", wantStatus: http.StatusPreconditionFailed},
+	}
+
+	documentService := service.NewDocumentService(missingDocumentRepository{})
+	h := NewDocumentHandler(usecase.NewDocumentUseCase(documentService, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" {
+				body = `{"source":{"name":"a"}}`
+			}
+			r := httptest.NewRequest(http.MethodPut, "/documents/articles/1"+tt.query, strings.NewReader(body))
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			h.UpdateDocument(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+// createDocumentRepository は全てのドキュメントの作成に成功するリポジトリ
+type createDocumentRepository struct {
+	repository.ElasticsearchRepository
+}
+
+func (createDocumentRepository) CreateDocument(_ context.Context, doc *entity.Document) error {
+	doc.SetID("1")
+	return nil
+}
+
+func TestCreateDocumentLogsResolvedIndex(t *testing.T) {
+	documentService := service.NewDocumentServiceWithConfig(createDocumentRepository{}, &service.DocumentConfig{DefaultIndex: "articles"})
+	h := NewDocumentHandler(usecase.NewDocumentUseCase(documentService, nil))
+
+	// インデックスを省略した作成では、適用されたデフォルトインデックスがログに残る
+	ctx, fields := middleware.WithLogFields(context.Background())
+	r := httptest.NewRequest(http.MethodPost, "/documents", strings.NewReader(`{"source":{"title":"Go"}}`)).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	h.CreateDocument(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d (body %s)", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if fields.Index() != "articles" || fields.Operation() != "create_document" {
+		t.Errorf("log fields = (%s, %s), want (create_document, articles)", fields.Operation(), fields.Index())
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+)
+
+// NotFound は未知のパスへのリクエストに JSON 形式の404を返す
+// ServeMux の "/" パターンに登録し、他のどのルートにも一致しないリクエストを受け取る
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeRouteError(w, r, errors.NewAppError(
+		errors.ErrCodeRouteNotFound,
+		fmt.Sprintf("No route found for %s %s", r.Method, r.URL.Path),
+	))
+}
+
+// MethodNotAllowed は既知のパスに未対応のメソッドでアクセスされた場合に405を返すハンドラーを作成する
+// allowed はそのパスで許可されているメソッドの一覧で、Allow ヘッダーに設定される
+func MethodNotAllowed(allowed []string) http.HandlerFunc {
+	allow := strings.Join(allowed, ", ")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		writeRouteError(w, r, errors.NewAppErrorWithDetails(
+			errors.ErrCodeMethodNotAllowed,
+			fmt.Sprintf("Method %s is not allowed for %s", r.Method, r.URL.Path),
+			"Allowed methods: "+allow,
+		))
+	}
+}
+
+// writeRouteError はルーティングエラーをリクエストID付きのエラーレスポンスとして書き込む
+func writeRouteError(w http.ResponseWriter, r *http.Request, appErr *errors.AppError) {
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	errorResponse := dto.NewErrorResponse(string(appErr.Code), appErr.Message, appErr.Details)
+	errorResponse.Error.RequestID = middleware.GetRequestID(r.Context())
+	rw.WriteJSON(appErr.HTTPStatus, errorResponse)
+}
@@ -3,27 +3,64 @@ package handler
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/repository"
 	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/elasticsearch"
 	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+	"github.com/Yuki-TU/elastic-search/api/pkg/version"
 )
 
 // HealthHandler はヘルスチェックリクエストを処理する
 type HealthHandler struct {
 	esClient *elasticsearch.Client
+	// clusters は監視対象の全クラスター（先頭は esClient）
+	clusters        []*elasticsearch.Client
+	esRepo          repository.ElasticsearchRepository
+	requiredIndices []string
+	// minIndexStatus は必須インデックスを正常とみなす最低のヘルス（"green" または "yellow"）
+	minIndexStatus string
+	// cacheTTL はチェック結果を再利用する期間（0の場合はキャッシュしない）
+	cacheTTL time.Duration
+
+	mu         sync.Mutex
+	cached     *healthResult
+	refreshing bool
+}
+
+// healthResult はElasticsearchと必須インデックスのチェック結果
+type healthResult struct {
+	status    string
+	checks    map[string]any
+	checkedAt time.Time
 }
 
 // NewHealthHandler は新しい HealthHandler を作成する
-func NewHealthHandler(esClient *elasticsearch.Client) *HealthHandler {
+// clusters の先頭はリクエストを処理するクラスターで、必須インデックスのチェックにも使う
+// minIndexStatus に "yellow" を指定するとレプリカが割り当てられていない必須インデックスも正常とみなす（それ以外は "green" のみ）
+func NewHealthHandler(clusters []*elasticsearch.Client, esRepo repository.ElasticsearchRepository, requiredIndices []string, minIndexStatus string, cacheTTL time.Duration) *HealthHandler {
+	if minIndexStatus != "yellow" {
+		minIndexStatus = "green"
+	}
 	return &HealthHandler{
-		esClient: esClient,
+		esClient:        clusters[0],
+		clusters:        clusters,
+		esRepo:          esRepo,
+		requiredIndices: requiredIndices,
+		minIndexStatus:  minIndexStatus,
+		cacheTTL:        cacheTTL,
 	}
 }
 
 // HealthCheck は基本的なヘルスチェックリクエストを処理する
-// GET /health
+// GET /health?refresh={true|false}
+//
+// 頻繁なプローブでElasticsearchに負荷をかけないよう、結果は cacheTTL の間キャッシュする。
+// 期限切れ後も期限の2倍までは前回の結果を返しつつバックグラウンドで更新し、refresh=true の場合は常に再チェックする
 func (h *HealthHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -32,46 +69,183 @@ func (h *HealthHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	utils.SetCORSHeaders(w)
 	utils.SetSecurityHeaders(w)
 
-	// ElasticSearch接続をチェック
-	esHealth := h.checkElasticsearchHealth(ctx)
-
-	// 全体的なヘルス状態
-	overallStatus := "healthy"
-	if isHealthy, ok := esHealth["is_healthy"].(bool); !ok || !isHealthy {
-		overallStatus = "unhealthy"
-	}
+	// キャッシュ済み、または新しいチェック結果を取得
+	result, cached := h.healthResult(ctx, r.URL.Query().Get("refresh") == "true")
 
 	// DTOを使用してヘルスレスポンスを作成
 	healthResponse := dto.NewHealthResponse(
-		overallStatus,
+		result.status,
 		"elasticsearch-api",
-		"1.0.0",
-		map[string]interface{}{
-			"elasticsearch": esHealth,
-		},
+		version.Version,
+		result.checks,
 	)
+	if cached {
+		healthResponse.Cached = true
+		w.Header().Set("Age", strconv.Itoa(int(time.Since(result.checkedAt).Seconds())))
+	}
 
-	if overallStatus == "healthy" {
+	// 追加クラスターのみの異常（degraded）はリクエストの処理に影響しないため200を返す
+	if result.status == "healthy" || result.status == "degraded" {
 		rw.WriteJSON(http.StatusOK, healthResponse)
 	} else {
 		rw.WriteJSON(http.StatusServiceUnavailable, healthResponse)
 	}
 }
 
+// healthResult はチェック結果を返す。キャッシュした結果を返した場合は true も返す
+func (h *HealthHandler) healthResult(ctx context.Context, forceRefresh bool) (*healthResult, bool) {
+	if h.cacheTTL <= 0 {
+		return h.check(ctx), false
+	}
+
+	if !forceRefresh {
+		h.mu.Lock()
+		cached := h.cached
+		if cached != nil {
+			age := time.Since(cached.checkedAt)
+			if age < h.cacheTTL {
+				h.mu.Unlock()
+				return cached, true
+			}
+			// 期限切れ直後は前回の結果を返し、更新はバックグラウンドで1つだけ行う
+			if age < 2*h.cacheTTL {
+				if !h.refreshing {
+					h.refreshing = true
+					go h.refresh()
+				}
+				h.mu.Unlock()
+				return cached, true
+			}
+		}
+		h.mu.Unlock()
+	}
+
+	result := h.check(ctx)
+	h.store(result)
+	return result, false
+}
+
+// refresh はリクエストとは独立したコンテキストでチェックを行い、キャッシュを更新する
+func (h *HealthHandler) refresh() {
+	result := h.check(context.Background())
+
+	h.mu.Lock()
+	h.refreshing = false
+	h.mu.Unlock()
+
+	h.store(result)
+}
+
+// ClearCache はキャッシュしたチェック結果を削除し、削除した件数（0 または 1）を返す
+// index を指定した場合は、それが必須インデックスとしてチェック対象になっている場合のみ削除する
+func (h *HealthHandler) ClearCache(index string) int {
+	if index != "" && !slices.Contains(h.requiredIndices, index) {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached == nil {
+		return 0
+	}
+	h.cached = nil
+	return 1
+}
+
+// store はより新しいチェック結果でキャッシュを更新する
+func (h *HealthHandler) store(result *healthResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached == nil || result.checkedAt.After(h.cached.checkedAt) {
+		h.cached = result
+	}
+}
+
+// check はElasticsearchと必須インデックスのヘルスをチェックする
+func (h *HealthHandler) check(ctx context.Context) *healthResult {
+	checkedAt := time.Now()
+
+	// 全クラスターの接続を並行してチェック
+	clustersHealth := h.checkClusters(ctx)
+	esHealth := clustersHealth[h.esClient.Name()]
+
+	checks := map[string]interface{}{
+		"elasticsearch": esHealth,
+	}
+
+	// 全体的なヘルス状態（追加クラスターのみの異常は degraded とする）
+	overallStatus := "healthy"
+	if !isHealthy(esHealth) {
+		overallStatus = "unhealthy"
+	}
+	if len(h.clusters) > 1 {
+		checks["clusters"] = clustersHealth
+		for _, health := range clustersHealth {
+			if !isHealthy(health) && overallStatus == "healthy" {
+				overallStatus = "degraded"
+			}
+		}
+	}
+
+	// 必須インデックスをチェック
+	if len(h.requiredIndices) > 0 {
+		indicesHealth, allHealthy := h.checkRequiredIndices(ctx)
+		checks["indices"] = indicesHealth
+		if !allHealthy {
+			overallStatus = "unhealthy"
+		}
+	}
+
+	return &healthResult{
+		status:    overallStatus,
+		checks:    checks,
+		checkedAt: checkedAt,
+	}
+}
+
 // OptionsHandler はCORSプリフライトリクエストを処理する
 func (h *HealthHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
 	utils.SetCORSHeaders(w)
 	w.WriteHeader(http.StatusOK)
 }
 
+// isHealthy はチェック結果が正常かどうかを返す
+func isHealthy(health map[string]any) bool {
+	healthy, ok := health["is_healthy"].(bool)
+	return ok && healthy
+}
+
+// checkClusters は全クラスターのヘルスを並行してチェックし、クラスター名ごとの結果を返す
+func (h *HealthHandler) checkClusters(ctx context.Context) map[string]map[string]any {
+	results := make([]map[string]any, len(h.clusters))
+
+	var wg sync.WaitGroup
+	for i, client := range h.clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkElasticsearchHealth(ctx, client)
+		}()
+	}
+	wg.Wait()
+
+	health := make(map[string]map[string]any, len(h.clusters))
+	for i, client := range h.clusters {
+		health[client.Name()] = results[i]
+	}
+	return health
+}
+
 // checkElasticsearchHealth はElasticSearchクラスターのヘルスをチェックする
-func (h *HealthHandler) checkElasticsearchHealth(ctx context.Context) map[string]any {
+func checkElasticsearchHealth(ctx context.Context, client *elasticsearch.Client) map[string]any {
 	// ヘルスチェック用にタイムアウト付きのコンテキストを作成
 	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// ヘルスチェックを実行
-	info, err := h.esClient.Info(healthCtx)
+	info, err := client.Info(healthCtx)
 	if err != nil {
 		return map[string]any{
 			"is_healthy": false,
@@ -104,3 +278,72 @@ func (h *HealthHandler) checkElasticsearchHealth(ctx context.Context) map[string
 
 	return healthInfo
 }
+
+// checkRequiredIndices は必須インデックスの存在とヘルスをチェックする
+func (h *HealthHandler) checkRequiredIndices(ctx context.Context) (map[string]any, bool) {
+	// ヘルスチェック用にタイムアウト付きのコンテキストを作成
+	healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	results := make(map[string]any, len(h.requiredIndices))
+	allHealthy := true
+
+	for _, index := range h.requiredIndices {
+		// インデックスの存在を確認
+		exists, err := h.esRepo.IndexExists(healthCtx, index)
+		if err != nil {
+			results[index] = map[string]any{
+				"is_healthy": false,
+				"exists":     false,
+				"error":      err.Error(),
+			}
+			allHealthy = false
+			continue
+		}
+
+		if !exists {
+			results[index] = map[string]any{
+				"is_healthy": false,
+				"exists":     false,
+				"status":     "missing",
+			}
+			allHealthy = false
+			continue
+		}
+
+		// インデックスのヘルスを取得
+		health, err := h.esClient.IndexHealth(healthCtx, index)
+		if err != nil {
+			results[index] = map[string]any{
+				"is_healthy": false,
+				"exists":     true,
+				"error":      err.Error(),
+			}
+			allHealthy = false
+			continue
+		}
+
+		status, _ := health["status"].(string)
+		isHealthy := indexStatusMeets(status, h.minIndexStatus)
+		if !isHealthy {
+			allHealthy = false
+		}
+
+		results[index] = map[string]any{
+			"is_healthy": isHealthy,
+			"exists":     true,
+			"status":     status,
+		}
+	}
+
+	return results, allHealthy
+}
+
+// indexStatusRanks はインデックスのヘルスを良い順に並べるための順位
+var indexStatusRanks = map[string]int{"red": 1, "yellow": 2, "green": 3}
+
+// indexStatusMeets はインデックスのヘルスが最低のヘルス以上かどうかを返す（不明なヘルスは満たさない）
+func indexStatusMeets(status, minStatus string) bool {
+	rank, ok := indexStatusRanks[status]
+	return ok && rank >= indexStatusRanks[minStatus]
+}
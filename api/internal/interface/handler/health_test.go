@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/elasticsearch"
+)
+
+func TestIndexStatusMeets(t *testing.T) {
+	tests := []struct {
+		status    string
+		minStatus string
+		want      bool
+	}{
+		{status: "green", minStatus: "green", want: true},
+		{status: "yellow", minStatus: "green", want: false},
+		{status: "red", minStatus: "green", want: false},
+		{status: "green", minStatus: "yellow", want: true},
+		{status: "yellow", minStatus: "yellow", want: true},
+		{status: "red", minStatus: "yellow", want: false},
+		{status: "", minStatus: "yellow", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+"/"+tt.minStatus, func(t *testing.T) {
+			if got := indexStatusMeets(tt.status, tt.minStatus); got != tt.want {
+				t.Errorf("indexStatusMeets(%q, %q) = %v, want %v", tt.status, tt.minStatus, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHealthHandlerDefaultsToGreen(t *testing.T) {
+	tests := map[string]string{"": "green", "green": "green", "yellow": "yellow", "red": "green"}
+
+	for minStatus, want := range tests {
+		h := NewHealthHandler([]*elasticsearch.Client{nil}, nil, nil, minStatus, 0)
+		if h.minIndexStatus != want {
+			t.Errorf("NewHealthHandler(%q) minIndexStatus = %q, want %q", minStatus, h.minIndexStatus, want)
+		}
+	}
+}
+
+func TestHealthHandlerClearCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		cached bool
+		index  string
+		want   int
+	}{
+		{name: "all indices", cached: true, want: 1},
+		{name: "required index", cached: true, index: "products", want: 1},
+		{name: "other index", cached: true, index: "logs", want: 0},
+		{name: "nothing cached", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler([]*elasticsearch.Client{nil}, nil, []string{"products"}, "green", time.Minute)
+			if tt.cached {
+				h.cached = &healthResult{status: "healthy", checkedAt: time.Now()}
+			}
+
+			if got := h.ClearCache(tt.index); got != tt.want {
+				t.Errorf("ClearCache(%q) = %d, want %d", tt.index, got, tt.want)
+			}
+			if (h.cached == nil) != (tt.want == 1 || !tt.cached) {
+				t.Errorf("cached result kept = %v", h.cached != nil)
+			}
+		})
+	}
+}
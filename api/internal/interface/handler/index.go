@@ -0,0 +1,340 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+)
+
+// IndexHandler はインデックス関連のHTTPリクエストを処理する
+type IndexHandler struct {
+	indexUseCase *usecase.IndexUseCase
+}
+
+// NewIndexHandler は新しい IndexHandler を作成する
+func NewIndexHandler(indexUseCase *usecase.IndexUseCase) *IndexHandler {
+	return &IndexHandler{
+		indexUseCase: indexUseCase,
+	}
+}
+
+// GetIndexStats はインデックス統計リクエストを処理する
+// GET /indices/{index}/_stats
+func (h *IndexHandler) GetIndexStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "index_stats", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// 統計情報を取得
+	stats, err := h.indexUseCase.GetIndexStats(ctx, index)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 成功レスポンスを返す
+	rw.WriteSuccess(stats, "Index stats retrieved successfully")
+}
+
+// CreateIndex はインデックスの作成リクエストを処理する（管理者用）
+// POST /indices
+//
+// ボディの mapping と settings（シャード数、レプリカ数、アナライザーなど）を指定して作成する
+func (h *IndexHandler) CreateIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// リクエストボディを解析
+	var req dto.CreateIndexRequest
+	if err := utils.ParseRequestBody(r, &req); err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "create_index", req.Index)
+
+	if req.Index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// インデックスを作成
+	result, err := h.indexUseCase.CreateIndex(ctx, &req)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 成功レスポンスを返す
+	rw.WriteCreated(result, "Index created successfully")
+}
+
+// OpenIndex はインデックスのオープンリクエストを処理する（管理者用）
+// POST /indices/{index}/_open
+func (h *IndexHandler) OpenIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "open_index", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// インデックスをオープン
+	result, err := h.indexUseCase.OpenIndex(ctx, index)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 成功レスポンスを返す
+	rw.WriteSuccess(result, "Index opened successfully")
+}
+
+// CloseIndex はインデックスのクローズリクエストを処理する（管理者用）
+// POST /indices/{index}/_close
+func (h *IndexHandler) CloseIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "close_index", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// インデックスをクローズ
+	result, err := h.indexUseCase.CloseIndex(ctx, index)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 成功レスポンスを返す
+	rw.WriteSuccess(result, "Index closed successfully")
+}
+
+// RefreshIndex はインデックスのリフレッシュリクエストを処理する
+// POST /indices/{index}/_refresh
+func (h *IndexHandler) RefreshIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "refresh_index", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// インデックスをリフレッシュ
+	result, err := h.indexUseCase.RefreshIndex(ctx, index)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 成功レスポンスを返す
+	rw.WriteSuccess(result, "Index refreshed successfully")
+}
+
+// DeleteIndex はインデックスの削除リクエストを処理する（管理者用）
+// DELETE /indices/{index}?dry_run={true|false}
+//
+// dry_run=true の場合は削除せず、削除されるドキュメント数とサイズを返す
+func (h *IndexHandler) DeleteIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "delete_index", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	// インデックスを削除（dry_run の場合は削除される内容のみを取得）
+	result, err := h.indexUseCase.DeleteIndex(ctx, index, dryRun)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 成功レスポンスを返す
+	if dryRun {
+		rw.WriteSuccess(result, "Dry run: index was not deleted")
+		return
+	}
+	rw.WriteSuccess(result, "Index deleted successfully")
+}
+
+// ForceMerge はインデックスのフォースマージリクエストを処理する（管理者用）
+// POST /indices/{index}/_forcemerge?max_num_segments={n}
+//
+// フォースマージはCPUとディスクI/Oを大きく消費する重い操作のため、完了を待たずに
+// 202 Accepted とタスクIDを返す。進捗はElasticsearchのタスクAPIで確認する
+func (h *IndexHandler) ForceMerge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "force_merge", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// マージ後の最大セグメント数を解析（省略時はElasticsearchに任せる）
+	maxSegments := 0
+	if value := r.URL.Query().Get("max_num_segments"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			rw.WriteValidationError("max_num_segments", "must be a positive integer")
+			return
+		}
+		maxSegments = parsed
+	}
+
+	// フォースマージを開始
+	result, err := h.indexUseCase.ForceMerge(ctx, index, maxSegments)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// 受け付けたことを返す
+	rw.WriteJSON(http.StatusAccepted, result)
+}
+
+// ExportDocuments はインデックスの全ドキュメントをNDJSONとしてストリームする
+// GET /indices/{index}/_export?q={query_string}
+func (h *IndexHandler) ExportDocuments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを抽出
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "export_documents", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// 最初のバッチを取得するまではエラーをJSONで返せるよう、ヘッダーの送信を遅らせる
+	started := false
+	encoder := json.NewEncoder(w)
+	controller := http.NewResponseController(w)
+
+	err := h.indexUseCase.ExportDocuments(ctx, index, r.URL.Query().Get("q"), func(docs []dto.ExportedDocumentDTO) error {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+
+		for _, doc := range docs {
+			if err := encoder.Encode(doc); err != nil {
+				return err
+			}
+		}
+
+		// バッチごとにクライアントへ送信してメモリに溜めない
+		controller.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			rw.WriteError(err)
+		}
+		// ストリーム開始後はステータスを変更できないため、途中で打ち切る
+		return
+	}
+
+	// 一致するドキュメントがない場合は空のNDJSONを返す
+	if !started {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// OptionsHandler はCORSプリフライトリクエストを処理する
+func (h *IndexHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.SetCORSHeaders(w)
+	w.WriteHeader(http.StatusOK)
+}
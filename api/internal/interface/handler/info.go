@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/infrastructure/elasticsearch"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+	"github.com/Yuki-TU/elastic-search/api/pkg/version"
+)
+
+// InfoHandler はクラスター情報とビルド情報のリクエストを処理する
+type InfoHandler struct {
+	esClient *elasticsearch.Client
+}
+
+// NewInfoHandler は新しい InfoHandler を作成する
+func NewInfoHandler(esClient *elasticsearch.Client) *InfoHandler {
+	return &InfoHandler{
+		esClient: esClient,
+	}
+}
+
+// Info はクラスター情報とビルド情報を返す
+// GET /info
+func (h *InfoHandler) Info(w http.ResponseWriter, r *http.Request) {
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// クラスター情報用にタイムアウト付きのコンテキストを作成
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// クラスター情報を取得
+	info, err := h.esClient.Info(ctx)
+	if err != nil {
+		rw.WriteInternalError("Failed to get cluster info", err)
+		return
+	}
+
+	cluster := map[string]any{}
+
+	// クラスター名を抽出
+	if clusterName, ok := info["cluster_name"].(string); ok {
+		cluster["cluster_name"] = clusterName
+	}
+
+	// バージョン情報を抽出
+	if ver, ok := info["version"].(map[string]any); ok {
+		if versionNumber, ok := ver["number"].(string); ok {
+			cluster["version"] = versionNumber
+		}
+		if luceneVersion, ok := ver["lucene_version"].(string); ok {
+			cluster["lucene_version"] = luceneVersion
+		}
+	}
+
+	rw.WriteJSON(http.StatusOK, map[string]any{
+		"service": map[string]any{
+			"name":       "elasticsearch-api",
+			"version":    version.Version,
+			"commit":     version.Commit,
+			"build_time": version.BuildTime,
+		},
+		"elasticsearch": cluster,
+	})
+}
+
+// OptionsHandler はCORSプリフライトリクエストを処理する
+func (h *InfoHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.SetCORSHeaders(w)
+	w.WriteHeader(http.StatusOK)
+}
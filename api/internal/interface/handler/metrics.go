@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+)
+
+// MetricsHandler はサーバー内部の状態のリクエストを処理する
+type MetricsHandler struct {
+	documentUseCase *usecase.DocumentUseCase
+}
+
+// NewMetricsHandler は新しい MetricsHandler を作成する
+func NewMetricsHandler(documentUseCase *usecase.DocumentUseCase) *MetricsHandler {
+	return &MetricsHandler{
+		documentUseCase: documentUseCase,
+	}
+}
+
+// Metrics は非同期登録のキューの深さなど、サーバー内部の状態を返す
+// GET /metrics
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// 状態は常に変化するためキャッシュさせない
+	w.Header().Set("Cache-Control", "no-store")
+
+	rw.WriteJSON(http.StatusOK, &dto.MetricsResponse{
+		AsyncIndexer: h.documentUseCase.AsyncIndexerMetrics(),
+	})
+}
+
+// OptionsHandler はCORSプリフライトリクエストを処理する
+func (h *MetricsHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.SetCORSHeaders(w)
+	w.WriteHeader(http.StatusOK)
+}
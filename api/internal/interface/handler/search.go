@@ -1,28 +1,41 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
 	"github.com/Yuki-TU/elastic-search/api/internal/application/usecase"
+	"github.com/Yuki-TU/elastic-search/api/internal/interface/middleware"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
 	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
 )
 
 // SearchHandler は検索関連のHTTPリクエストを処理する
 type SearchHandler struct {
-	searchUseCase usecase.SearchUseCaser
+	searchUseCase     usecase.SearchUseCaser
+	cacheMaxAge       time.Duration
+	sessionPreference bool
 }
 
 // NewSearchHandler は新しい SearchHandler を作成する
-func NewSearchHandler(searchUseCase usecase.SearchUseCaser) *SearchHandler {
+// cacheMaxAge は GET /search のレスポンスに設定する Cache-Control の max-age
+// sessionPreference が true の場合、preference 未指定の検索には呼び出し元から導出したセッションごとの値を使う
+func NewSearchHandler(searchUseCase usecase.SearchUseCaser, cacheMaxAge time.Duration, sessionPreference bool) *SearchHandler {
 	return &SearchHandler{
-		searchUseCase: searchUseCase,
+		searchUseCase:     searchUseCase,
+		cacheMaxAge:       cacheMaxAge,
+		sessionPreference: sessionPreference,
 	}
 }
 
 // Search は基本的な検索リクエストを処理する
-// GET /search?q={query}&index={index}&from={from}&size={size}
+// GET /search?q={query}&index={index}&from={from}&size={size}&mode={mode}&fields={fields}&default_operator={and|or}&flatten={true|false}&preference={preference}&routing={routing}&profile={true|false}&explain={true|false}&debug={true|false}
 func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -39,30 +52,121 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 	}
 
 	index := r.URL.Query().Get("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "search", index)
+
 	from, _ := strconv.Atoi(r.URL.Query().Get("from"))
 	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
 
 	// 検索リクエストを作成
 	req := &dto.SearchRequest{
-		Query: query,
-		Index: index,
-		From:  from,
-		Size:  size,
+		Query:           query,
+		Mode:            r.URL.Query().Get("mode"),
+		DefaultOperator: r.URL.Query().Get("default_operator"),
+		Analyzer:        r.URL.Query().Get("analyzer"),
+		Index:           index,
+		From:            from,
+		Size:            size,
+	}
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		req.Fields = strings.Split(fields, ",")
+	}
+
+	// シャードの選択
+	req.Preference = r.URL.Query().Get("preference")
+	req.Routing = r.URL.Query().Get("routing")
+	h.applySessionPreference(r, req)
+
+	// 複数インデックスやワイルドカード指定時のインデックスの解決方法
+	req.IgnoreUnavailable = r.URL.Query().Get("ignore_unavailable") == "true"
+	req.ExpandWildcards = r.URL.Query().Get("expand_wildcards")
+	if allowNoIndices := r.URL.Query().Get("allow_no_indices"); allowNoIndices != "" {
+		allow := allowNoIndices == "true"
+		req.AllowNoIndices = &allow
 	}
 
+	// source=false の場合はソースを返さない
+	if r.URL.Query().Get("source") == "false" {
+		source := false
+		req.Source = &source
+	}
+
+	// profile=true の場合は処理時間の内訳を返す（信頼済みの呼び出し元のみ）
+	req.Profile = r.URL.Query().Get("profile") == "true"
+
+	// explain=true の場合はヒットごとにスコアの計算過程を返す
+	req.Explain = r.URL.Query().Get("explain") == "true"
+
+	// debug=true の場合はサービスが適用したビジネスルールを返す
+	req.Debug = r.URL.Query().Get("debug") == "true"
+
+	// seq_no_primary_term=true の場合はヒットごとにシーケンス番号とプライマリタームを返す
+	req.SeqNoPrimaryTerm = r.URL.Query().Get("seq_no_primary_term") == "true"
+
+	// 前ページの next_cursor で続きを取得する
+	req.Cursor = r.URL.Query().Get("cursor")
+
 	// 検索を実行
 	result, err := h.searchUseCase.Search(ctx, req)
 	if err != nil {
 		rw.WriteError(err)
 		return
 	}
+	// インデックス省略時に適用されたデフォルトインデックスをログに残す
+	middleware.SetLogFields(ctx, "", result.Query.Index)
+
+	// サイズが上限に丸められた場合はヘッダーで通知
+	setSizeClampedHeader(w, size, result)
+
+	// flatten=true の場合はネストしたソースをドット区切りのキーに展開
+	if r.URL.Query().Get("flatten") == "true" {
+		flattenResults(result)
+	}
+
+	// 検索結果を返す（ETagとCache-Controlを付与し、If-None-Matchに一致すれば304を返す）
+	rw.WriteCacheableJSON(r, result, h.cacheMaxAge)
+}
+
+// Suggest は入力途中の語に対するサジェスト（オートコンプリート）リクエストを処理する
+// GET /search/suggest?q={query}&field={field}&index={index}&size={size}&mode={prefix|search_as_you_type}
+//
+// mode=search_as_you_type の場合は search_as_you_type としてマッピングされたフィールドを bool_prefix で検索する
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// クエリパラメータを解析
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		rw.WriteBadRequestError("Query parameter 'q' is required")
+		return
+	}
+
+	index := r.URL.Query().Get("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "suggest", index)
+
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+
+	// サジェスト検索を実行
+	result, err := h.searchUseCase.SuggestSearch(ctx, query, index, r.URL.Query().Get("field"), r.URL.Query().Get("mode"), size)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
 
 	// 検索結果を返す
 	rw.WriteSearchResult(result)
 }
 
 // AdvancedSearch はフィルターとソートを含む高度な検索リクエストを処理する
-// POST /search
+// POST /search?flatten={true|false}&profile={true|false}&debug={true|false}
 func (h *SearchHandler) AdvancedSearch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	rw := utils.NewResponseWriter(w)
@@ -78,19 +182,302 @@ func (h *SearchHandler) AdvancedSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// profile=true の場合は処理時間の内訳を返す（ボディの profile でも指定可能）
+	if r.URL.Query().Get("profile") == "true" {
+		req.Profile = true
+	}
+
+	// debug=true の場合はサービスが適用したビジネスルールを返す（ボディの debug でも指定可能）
+	if r.URL.Query().Get("debug") == "true" {
+		req.Debug = true
+	}
+
+	// seq_no_primary_term=true の場合はヒットごとにシーケンス番号とプライマリタームを返す（ボディの seq_no_primary_term でも指定可能）
+	if r.URL.Query().Get("seq_no_primary_term") == "true" {
+		req.SeqNoPrimaryTerm = true
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "advanced_search", req.Index)
+
+	// preference 未指定の場合はセッションごとの値を使う
+	h.applySessionPreference(r, &req)
+
+	// 要求されたサイズを保持
+	requestedSize := req.Size
+
 	// 高度な検索を実行
 	result, err := h.searchUseCase.AdvancedSearch(ctx, &req)
 	if err != nil {
 		rw.WriteError(err)
 		return
 	}
+	// インデックス省略時に適用されたデフォルトインデックスをログに残す
+	middleware.SetLogFields(ctx, "", result.Query.Index)
+
+	// サイズが上限に丸められた場合はヘッダーで通知
+	setSizeClampedHeader(w, requestedSize, result)
+
+	// flatten=true の場合はネストしたソースをドット区切りのキーに展開
+	if r.URL.Query().Get("flatten") == "true" {
+		flattenResults(result)
+	}
+
+	// 検索結果を返す
+	rw.WriteSearchResult(result)
+}
+
+// applySessionPreference は preference が未指定の場合にセッションごとの値を設定する
+// 同じ呼び出し元の検索は常に同じシャードコピーで実行されるため、ページ送りの間で順序が揺れなくなる
+func (h *SearchHandler) applySessionPreference(r *http.Request, req *dto.SearchRequest) {
+	if !h.sessionPreference || req.Preference != "" {
+		return
+	}
+	req.Preference = sessionPreference(r)
+}
+
+// sessionPreference はリクエストから呼び出し元ごとに安定した preference の値を導出する
+// 認証済みの場合はテナントとサブジェクト、それ以外はクライアントのアドレスとUser-Agentから求める
+func sessionPreference(r *http.Request) string {
+	ctx := r.Context()
+	session := auth.Subject(ctx)
+	if session != "" {
+		session = "subject:" + auth.Tenant(ctx) + "/" + session
+	} else {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		session = "client:" + host + "/" + r.UserAgent()
+	}
+	// Elasticsearch の組み込み値（"_local" など）と衝突しないよう、ハッシュ値を使う
+	sum := sha256.Sum256([]byte(session))
+	return "session-" + hex.EncodeToString(sum[:8])
+}
+
+// ListDocuments は検索語なしでインデックス内のドキュメントを一覧する
+// GET /documents/{index}?from={from}&size={size}&sort={field}:{asc|desc},...
+func (h *SearchHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを取得
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "list_documents", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// クエリパラメータを解析
+	from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+	sort := parseSortParam(r.URL.Query().Get("sort"))
+
+	// 一覧を取得
+	result, err := h.searchUseCase.ListDocuments(ctx, index, from, size, sort)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// サイズが上限に丸められた場合はヘッダーで通知
+	setSizeClampedHeader(w, size, result)
+
+	// 検索結果を返す
+	rw.WriteSearchResult(result)
+}
+
+// CountByField はフィールドの値ごとのドキュメント数を返す（ヒットは返さない）
+// GET /documents/{index}/_terms?field={field}&size={size}
+func (h *SearchHandler) CountByField(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// パスパラメータを取得
+	index := r.PathValue("index")
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "count_by_field", index)
+
+	if index == "" {
+		rw.WriteBadRequestError("Index is required")
+		return
+	}
+
+	// 返す値の数を解析（省略時はサービスのデフォルト）
+	size := 0
+	if value := r.URL.Query().Get("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			rw.WriteValidationError("size", "must be a positive integer")
+			return
+		}
+		size = parsed
+	}
+
+	// 集計を実行
+	result, err := h.searchUseCase.CountByField(ctx, index, r.URL.Query().Get("field"), size)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	rw.WriteJSON(http.StatusOK, result)
+}
+
+// parseSortParam は "field:order,field2:order" 形式のソート指定を解析する（順序の省略時は asc）
+func parseSortParam(param string) []dto.SortFieldDTO {
+	var sort []dto.SortFieldDTO
+	for _, item := range strings.Split(param, ",") {
+		field, order, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if field == "" {
+			continue
+		}
+		if order == "" {
+			order = "asc"
+		}
+		sort = append(sort, dto.SortFieldDTO{Field: field, Order: order})
+	}
+	return sort
+}
+
+// MoreLikeThis は任意のテキストに類似したドキュメントを検索する
+// POST /search/more_like_this
+func (h *SearchHandler) MoreLikeThis(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// リクエストボディを解析
+	var req dto.MoreLikeThisRequest
+	if err := utils.ParseRequestBody(r, &req); err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "more_like_this", req.Index)
+
+	// 要求されたサイズを保持
+	requestedSize := req.Size
+
+	// 類似検索を実行
+	result, err := h.searchUseCase.SearchMoreLikeThis(ctx, &req)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// サイズが上限に丸められた場合はヘッダーで通知
+	setSizeClampedHeader(w, requestedSize, result)
 
 	// 検索結果を返す
 	rw.WriteSearchResult(result)
 }
 
+// RenderQuery は検索を実行せずに、送信されるElasticsearchクエリを返す（デバッグ用）
+// POST /search/_render
+func (h *SearchHandler) RenderQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// リクエストボディを解析
+	var req dto.SearchRequest
+	if err := utils.ParseRequestBody(r, &req); err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "render_query", req.Index)
+
+	// クエリを構築
+	rendered, err := h.searchUseCase.RenderSearchQuery(ctx, &req)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	rw.WriteSuccess(rendered, "")
+}
+
+// ValidateQuery は検索を実行せずに、クエリが対象インデックスのマッピングに対して有効かを検証する
+// POST /search/_validate
+func (h *SearchHandler) ValidateQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rw := utils.NewResponseWriter(w)
+
+	// ヘッダーを設定
+	utils.SetCORSHeaders(w)
+	utils.SetSecurityHeaders(w)
+
+	// リクエストボディを解析
+	var req dto.SearchRequest
+	if err := utils.ParseRequestBody(r, &req); err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	// ログにインデックスと操作名を含める
+	middleware.SetLogFields(ctx, "validate_query", req.Index)
+
+	// local=true の場合はElasticsearchに問い合わせず、リクエストの検証のみを行う
+	remote := r.URL.Query().Get("local") != "true"
+
+	// クエリを検証
+	validation, err := h.searchUseCase.ValidateSearchQuery(ctx, &req, remote)
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	rw.WriteSuccess(validation, "")
+}
+
 // OptionsHandler はCORSプリフライトリクエストを処理する
 func (h *SearchHandler) OptionsHandler(w http.ResponseWriter, r *http.Request) {
 	utils.SetCORSHeaders(w)
 	w.WriteHeader(http.StatusOK)
 }
+
+// flattenResults は各ヒットのソースをドット区切りのキーに展開する
+func flattenResults(result *dto.SearchResponse) {
+	flattenHits(result.Results)
+}
+
+// flattenHits はヒットとインナーヒットのソースをフラット化する
+func flattenHits(hits []dto.HitDTO) {
+	for i := range hits {
+		if hits[i].Source != nil {
+			hits[i].Source = utils.FlattenMap(hits[i].Source)
+		}
+		flattenHits(hits[i].Collapsed)
+		flattenHits(hits[i].Nested)
+	}
+}
+
+// setSizeClampedHeader は要求サイズが上限に丸められた場合に適用サイズをヘッダーに設定する
+func setSizeClampedHeader(w http.ResponseWriter, requestedSize int, result *dto.SearchResponse) {
+	if requestedSize > result.Query.Size {
+		w.Header().Set("X-Search-Size-Clamped", strconv.Itoa(result.Query.Size))
+	}
+}
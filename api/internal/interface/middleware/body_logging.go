@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/domain/service"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+// maxBodyCaptureSize is the maximum body size captured for redaction
+const maxBodyCaptureSize = 1 << 20
+
+// redactedValue replaces the value of redacted fields
+const redactedValue = "[REDACTED]"
+
+// BodyLogConfig holds request/response body logging configuration
+type BodyLogConfig struct {
+	// Enabled logs bodies for every matching request
+	Enabled bool
+	// AllowDebugHeader enables logging per request via the X-Debug-Body header.
+	// The header is only honored for trusted callers (see TrustedCallerMiddleware),
+	// so that anonymous clients cannot fill the logs with request bodies.
+	AllowDebugHeader bool
+	// MaxBodySize is the maximum number of bytes logged per body
+	MaxBodySize int
+	// Routes limits logging to paths with these prefixes (empty means all routes)
+	Routes []string
+	// RedactFields are JSON field names whose values are replaced before logging
+	RedactFields []string
+	// LogHeaders also logs request and response headers
+	LogHeaders bool
+	// RedactHeaders are headers redacted in addition to DefaultSensitiveHeaders
+	RedactHeaders []string
+}
+
+// DefaultBodyLogConfig returns default body logging configuration
+func DefaultBodyLogConfig() *BodyLogConfig {
+	return &BodyLogConfig{
+		Enabled:          false,
+		AllowDebugHeader: false,
+		MaxBodySize:      4096,
+		Routes:           []string{},
+		RedactFields:     service.SensitiveFields(),
+	}
+}
+
+// BodyLogMiddleware logs request and response bodies with sensitive fields redacted
+func BodyLogMiddleware(logger *log.Logger, config *BodyLogConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = DefaultBodyLogConfig()
+	}
+
+	redactFields := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		redactFields[strings.ToLower(field)] = true
+	}
+	headers := NewHeaderRedactor(config.RedactHeaders)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !shouldLogBody(r, config) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Capture the request body while the handler reads it
+			reqBuf := &cappedBuffer{limit: maxBodyCaptureSize}
+			if r.Body != nil {
+				r.Body = &teeReadCloser{
+					Reader: io.TeeReader(r.Body, reqBuf),
+					Closer: r.Body,
+				}
+			}
+
+			// Capture the response body while it is written
+			ww := &bodyLogResponseWriter{
+				responseWriter: responseWriter{
+					ResponseWriter: w,
+					statusCode:     http.StatusOK,
+				},
+				body: &cappedBuffer{limit: maxBodyCaptureSize},
+			}
+
+			next.ServeHTTP(ww, r)
+
+			requestID := GetRequestID(r.Context())
+			if config.LogHeaders {
+				logger.Printf("[%s] HEADERS: %s %s - request: %s",
+					requestID, r.Method, r.URL.Path, headers.Format(r.Header))
+				logger.Printf("[%s] HEADERS: %s %s - %d - response: %s",
+					requestID, r.Method, r.URL.Path, ww.statusCode, headers.Format(w.Header()))
+			}
+			logger.Printf("[%s] BODY: %s %s - request: %s",
+				requestID, r.Method, r.URL.Path, formatBody(reqBuf, redactFields, config.MaxBodySize))
+			logger.Printf("[%s] BODY: %s %s - %d - response: %s",
+				requestID, r.Method, r.URL.Path, ww.statusCode, formatBody(ww.body, redactFields, config.MaxBodySize))
+		})
+	}
+}
+
+// shouldLogBody reports whether bodies should be logged for the request
+func shouldLogBody(r *http.Request, config *BodyLogConfig) bool {
+	enabled := config.Enabled
+	if !enabled && config.AllowDebugHeader && auth.IsTrusted(r.Context()) {
+		enabled = r.Header.Get("X-Debug-Body") == "true"
+	}
+	if !enabled {
+		return false
+	}
+
+	if len(config.Routes) == 0 {
+		return true
+	}
+	for _, route := range config.Routes {
+		if strings.HasPrefix(r.URL.Path, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBody redacts and truncates a captured body for logging
+func formatBody(buf *cappedBuffer, redactFields map[string]bool, maxSize int) string {
+	if buf.total == 0 {
+		return "<empty>"
+	}
+	if buf.truncated {
+		return "<body too large to redact>"
+	}
+
+	var data any
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		return "<non-JSON body omitted>"
+	}
+
+	redacted, err := json.Marshal(redactValue(data, redactFields))
+	if err != nil {
+		return "<unencodable body omitted>"
+	}
+
+	if maxSize > 0 && len(redacted) > maxSize {
+		return string(redacted[:maxSize]) + "...(truncated)"
+	}
+	return string(redacted)
+}
+
+// redactValue replaces the values of redacted fields recursively
+func redactValue(value any, redactFields map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if redactFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(item, redactFields)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, redactFields)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// cappedBuffer buffers up to limit bytes and records whether more were written
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	total     int
+	truncated bool
+}
+
+// Write buffers p without ever failing so the tee source is not interrupted
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if remaining := b.limit - b.Buffer.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.Buffer.Write(p[:remaining])
+			b.truncated = true
+		} else {
+			b.Buffer.Write(p)
+		}
+	} else {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// teeReadCloser reads through a TeeReader and closes the original body
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyLogResponseWriter captures the status code and response body
+type bodyLogResponseWriter struct {
+	responseWriter
+	body *cappedBuffer
+}
+
+// Write captures the response body
+func (w *bodyLogResponseWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.responseWriter.Write(p)
+}
+
+// Flush sends buffered data to the client so streamed responses are not held back
+func (w *bodyLogResponseWriter) Flush() {
+	w.responseWriter.Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *bodyLogResponseWriter) Unwrap() http.ResponseWriter {
+	return w.responseWriter.ResponseWriter
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+func TestShouldLogBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      BodyLogConfig
+		debugHeader bool
+		trusted     bool
+		path        string
+		want        bool
+	}{
+		{name: "disabled", path: "/search", want: false},
+		{name: "enabled", config: BodyLogConfig{Enabled: true}, path: "/search", want: true},
+		{name: "enabled outside routes", config: BodyLogConfig{Enabled: true, Routes: []string{"/documents"}}, path: "/search", want: false},
+		{name: "debug header from trusted caller", config: BodyLogConfig{AllowDebugHeader: true}, debugHeader: true, trusted: true, path: "/search", want: true},
+		{name: "debug header from untrusted caller", config: BodyLogConfig{AllowDebugHeader: true}, debugHeader: true, path: "/search", want: false},
+		{name: "debug header not allowed", debugHeader: true, trusted: true, path: "/search", want: false},
+		{name: "trusted caller without header", config: BodyLogConfig{AllowDebugHeader: true}, trusted: true, path: "/search", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.debugHeader {
+				r.Header.Set("X-Debug-Body", "true")
+			}
+			if tt.trusted {
+				r = r.WithContext(auth.WithTrusted(r.Context()))
+			}
+
+			if got := shouldLogBody(r, &tt.config); got != tt.want {
+				t.Errorf("shouldLogBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoder creates a writer that compresses everything written to it into w
+// using one content coding. Close must flush the remaining compressed data.
+type Encoder func(w io.Writer) io.WriteCloser
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	}
+)
+
+// RegisterEncoder makes a content coding available to the compression middleware.
+// gzip is always available; Brotli ("br") is registered when the server is built with
+// the brotli build tag.
+func RegisterEncoder(name string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(name)] = encoder
+}
+
+// AvailableEncoding reports whether an encoder is registered for the content coding
+func AvailableEncoding(name string) bool {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	_, ok := encoders[strings.ToLower(name)]
+	return ok
+}
+
+// lookupEncoder returns the registered encoder for the content coding
+func lookupEncoder(name string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	encoder, ok := encoders[name]
+	return encoder, ok
+}
+
+// CompressionConfig holds response compression configuration
+type CompressionConfig struct {
+	// Encodings lists the content codings the server may use, most preferred first.
+	// Codings without a registered encoder are ignored.
+	Encodings []string
+	// MinSize is the smallest response body, in bytes, worth compressing
+	MinSize int
+}
+
+// DefaultCompressionConfig returns default compression configuration
+func DefaultCompressionConfig() *CompressionConfig {
+	return &CompressionConfig{
+		Encodings: []string{"br", "gzip"},
+		MinSize:   1024,
+	}
+}
+
+// CompressionMiddleware compresses responses with the default configuration
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return CompressionMiddlewareWithConfig(DefaultCompressionConfig())(next)
+}
+
+// CompressionMiddlewareWithConfig compresses response bodies with the content coding
+// negotiated from the Accept-Encoding header. Among the codings the client accepts with
+// the highest quality, the one listed first in config.Encodings wins, so "br" is preferred
+// over gzip when both are offered. The chosen coding is reported in Content-Encoding.
+func CompressionMiddlewareWithConfig(config *CompressionConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = DefaultCompressionConfig()
+	}
+
+	var available []string
+	for _, name := range config.Encodings {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if AvailableEncoding(name) && !slices.Contains(available, name) {
+			available = append(available, name)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(available) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), available)
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			encoder, _ := lookupEncoder(encoding)
+			cw := &compressResponseWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				encoder:        encoder,
+				minSize:        config.MinSize,
+			}
+			defer cw.Close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the content coding for the response from the Accept-Encoding
+// header, or returns "" when the response should not be compressed
+func negotiateEncoding(header string, available []string) string {
+	if header == "" {
+		return ""
+	}
+
+	qualities := map[string]float64{}
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		qualities[name] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, name := range available {
+		quality, ok := qualities[name]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+	return best
+}
+
+// compressResponseWriter buffers the start of the response body until MinSize bytes are
+// written, then decides whether to compress it. Responses that are already encoded or
+// have no body are passed through unchanged.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+	encoder  Encoder
+	minSize  int
+
+	status      int
+	buffer      bytes.Buffer
+	decided     bool
+	passthrough bool
+	compressor  io.WriteCloser
+}
+
+// WriteHeader delays the status until the body is known to be compressed or not
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers or compresses the response body
+func (w *compressResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		if !w.compressible() {
+			w.start(false)
+		} else {
+			w.buffer.Write(data)
+			if w.buffer.Len() < w.minSize {
+				return len(data), nil
+			}
+			w.start(true)
+			return len(data), w.flushBuffer()
+		}
+	}
+
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.compressor.Write(data)
+}
+
+// compressible reports whether the response may be compressed
+func (w *compressResponseWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	return w.status >= http.StatusOK && w.status != http.StatusNoContent && w.status != http.StatusNotModified
+}
+
+// start sends the headers, switching to the compressed encoding when compress is true
+func (w *compressResponseWriter) start(compress bool) {
+	w.decided = true
+	w.passthrough = !compress
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.compressor = w.encoder(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// flushBuffer writes the buffered body through the chosen writer
+func (w *compressResponseWriter) flushBuffer() error {
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.passthrough {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.compressor.Write(w.buffer.Bytes())
+	}
+	w.buffer.Reset()
+	return err
+}
+
+// Flush compresses and sends everything written so far, so streamed responses reach the client
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.start(w.compressible())
+		w.flushBuffer()
+	}
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok && !w.passthrough {
+		flusher.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Close sends a response that stayed below MinSize uncompressed and finishes the compressed stream
+func (w *compressResponseWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 {
+			// The handler wrote nothing; let net/http send its default response
+			return nil
+		}
+		w.start(false)
+		if err := w.flushBuffer(); err != nil {
+			return err
+		}
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,17 @@
+//go:build brotli
+
+package middleware
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Brotli support requires github.com/andybalholm/brotli, so it is only compiled in with
+// the brotli build tag (go build -tags brotli). Without it, "br" is skipped during negotiation.
+func init() {
+	RegisterEncoder("br", func(w io.Writer) io.WriteCloser {
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	})
+}
@@ -0,0 +1,37 @@
+//go:build brotli
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompressionMiddlewareBrotli(t *testing.T) {
+	body := strings.Repeat(`{"title":"golang"}`, 100)
+	handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/search", nil)
+	r.Header.Set("Accept-Encoding", "gzip, br")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Encoding"); got != "br" {
+		t.Fatalf("Content-Encoding = %q, want br", got)
+	}
+	decoded, err := io.ReadAll(brotli.NewReader(w.Body))
+	if err != nil {
+		t.Fatalf("brotli decode error = %v", err)
+	}
+	if string(decoded) != body {
+		t.Errorf("decoded body = %q, want %q", decoded, body)
+	}
+}
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"compress/gzip"
+	"context"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // CORSConfig holds CORS configuration
@@ -20,8 +24,8 @@ func DefaultCORSConfig() *CORSConfig {
 	return &CORSConfig{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID"},
-		ExposeHeaders:    []string{"X-Request-ID"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Encoding", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-CSRF-Token", "X-Pretty", "X-Request-ID"},
+		ExposeHeaders:    []string{"ETag", "Idempotent-Replayed", "X-Request-ID", "X-Search-Size-Clamped"},
 		AllowCredentials: false,
 		MaxAge:           86400, // 24 hours
 	}
@@ -154,6 +158,13 @@ func SecurityMiddleware(config *SecurityConfig) func(http.Handler) http.Handler
 				w.Header().Set("Permissions-Policy", config.PermissionsPolicy)
 			}
 
+			// Responses to writes must never be cached by browsers or CDNs
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				w.Header().Set("Cache-Control", "no-store")
+			}
+
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -198,27 +209,12 @@ func SimpleRateLimitMiddleware(config *RateLimitConfig) func(http.Handler) http.
 	}
 }
 
-// CompressionMiddleware provides gzip compression
-func CompressionMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if client accepts gzip
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// For this simplified implementation, we'll just add the header
-		// In production, you'd want to use a proper compression library
-		w.Header().Set("Content-Encoding", "gzip")
-		w.Header().Set("Vary", "Accept-Encoding")
-
-		next.ServeHTTP(w, r)
-	})
-}
-
-// RequestSizeLimitMiddleware limits request body size
+// RequestSizeLimitMiddleware limits request body size (0 disables the limit)
 func RequestSizeLimitMiddleware(maxSize int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
+		if maxSize <= 0 {
+			return next
+		}
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Limit request body size
 			if r.ContentLength > maxSize {
@@ -234,19 +230,77 @@ func RequestSizeLimitMiddleware(maxSize int64) func(http.Handler) http.Handler {
 	}
 }
 
-// RequestTimeoutMiddleware sets request timeout
-func RequestTimeoutMiddleware(timeout int) func(http.Handler) http.Handler {
+// RequestDecompressionMiddleware transparently decompresses gzip-encoded request bodies.
+// maxSize limits the decompressed size so that small payloads cannot expand without bound.
+// Requests with other content encodings are rejected with 415.
+func RequestDecompressionMiddleware(maxSize int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// In a real implementation, you'd set up proper timeout handling
-			// For now, just add a timeout header
-			w.Header().Set("X-Request-Timeout", string(rune(timeout)))
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			switch encoding {
+			case "", "identity":
+				next.ServeHTTP(w, r)
+				return
+			case "gzip", "x-gzip":
+			default:
+				http.Error(w, "Unsupported content encoding: "+encoding, http.StatusUnsupportedMediaType)
+				return
+			}
+
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid gzip request body", http.StatusBadRequest)
+				return
+			}
+
+			// Replace the body with the decompressed stream
+			r.Body = http.MaxBytesReader(w, &gzipBody{Reader: gz, body: r.Body}, maxSize)
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
 
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// gzipBody closes both the gzip reader and the underlying request body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close closes the gzip reader and the original body
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// timeoutWriteGrace is the extra time a timed-out request gets to write its error response
+const timeoutWriteGrace = 5 * time.Second
+
+// RequestTimeoutMiddleware sets a deadline on the request context.
+// Downstream Elasticsearch calls receive the context, so a request that exceeds the
+// deadline or whose client disconnects aborts its in-progress cluster round trip.
+// It is applied per route group, so the connection's read and write deadlines are moved
+// to match: a long bulk import is not cut off by the server-wide READ_TIMEOUT/WRITE_TIMEOUT.
+func RequestTimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline := time.Now().Add(timeout)
+			ctx, cancel := context.WithDeadline(r.Context(), deadline)
+			defer cancel()
+
+			// Writers that cannot change deadlines keep the server-wide timeouts
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline.Add(timeoutWriteGrace))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // RecoveryMiddleware provides panic recovery
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeoutMiddlewareSetsDeadline(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	handler := RequestTimeoutMiddleware(30 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok = r.Context().Deadline()
+	}))
+
+	before := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search", nil))
+	after := time.Now()
+
+	if !ok {
+		t.Fatal("request context has no deadline")
+	}
+	if deadline.Before(before.Add(30*time.Second)) || deadline.After(after.Add(30*time.Second)) {
+		t.Errorf("deadline = %v, want 30s after the request started", deadline)
+	}
+}
+
+func TestRequestTimeoutMiddlewareExtendsWriteTimeout(t *testing.T) {
+	// サーバー全体の WriteTimeout（100ms）より長く処理するルートでも、ルートのタイムアウト内なら応答できる
+	handler := RequestTimeoutMiddleware(5 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ログミドルウェアと同じくラップした ResponseWriter を経由しても接続の期限を変更できること
+		handler.ServeHTTP(&responseWriter{ResponseWriter: w, statusCode: http.StatusOK}, r)
+	}))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v, want the response written after the server-wide WriteTimeout", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "done" {
+		t.Errorf("body = %q (error %v), want done", body, err)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DefaultSensitiveHeaders returns the headers whose values are never logged in clear
+func DefaultSensitiveHeaders() []string {
+	return []string{
+		"Authorization",
+		"Proxy-Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-API-Key",
+		"X-Auth-Token",
+		"X-CSRF-Token",
+	}
+}
+
+// HeaderRedactor formats headers for logging with the values of sensitive headers replaced
+type HeaderRedactor struct {
+	sensitive map[string]bool
+}
+
+// NewHeaderRedactor creates a HeaderRedactor for the default sensitive headers plus extra.
+// The defaults cannot be removed so credentials stay redacted whatever the configuration.
+func NewHeaderRedactor(extra []string) *HeaderRedactor {
+	sensitive := make(map[string]bool)
+	for _, name := range append(DefaultSensitiveHeaders(), extra...) {
+		if name = strings.TrimSpace(name); name != "" {
+			sensitive[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+	return &HeaderRedactor{sensitive: sensitive}
+}
+
+// IsSensitive reports whether the value of the named header must be redacted
+func (h *HeaderRedactor) IsSensitive(name string) bool {
+	return h.sensitive[http.CanonicalHeaderKey(name)]
+}
+
+// Redact returns a copy of header with the values of sensitive headers replaced
+func (h *HeaderRedactor) Redact(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		if h.IsSensitive(name) {
+			redacted[name] = []string{redactedValue}
+			continue
+		}
+		redacted[name] = append([]string(nil), values...)
+	}
+	return redacted
+}
+
+// Format returns the redacted headers as a JSON object with keys in sorted order
+func (h *HeaderRedactor) Format(header http.Header) string {
+	flattened := make(map[string]string, len(header))
+	for name, values := range h.Redact(header) {
+		flattened[name] = strings.Join(values, ", ")
+	}
+
+	encoded, err := json.Marshal(flattened)
+	if err != nil {
+		return "<unencodable headers omitted>"
+	}
+	return string(encoded)
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InFlightRequest describes a request that is still being processed
+type InFlightRequest struct {
+	RequestID string
+	Method    string
+	Path      string
+	StartedAt time.Time
+}
+
+// InFlightTracker tracks requests that are currently being processed
+type InFlightTracker struct {
+	mu       sync.Mutex
+	nextID   uint64
+	requests map[uint64]InFlightRequest
+}
+
+// NewInFlightTracker creates a new in-flight request tracker
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{
+		requests: make(map[uint64]InFlightRequest),
+	}
+}
+
+// Middleware registers each request for the duration of its handling
+func (t *InFlightTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := t.add(InFlightRequest{
+			RequestID: GetRequestID(r.Context()),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			StartedAt: time.Now(),
+		})
+		defer t.remove(id)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns the number of requests currently in flight
+func (t *InFlightTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.requests)
+}
+
+// Snapshot returns the requests currently in flight, oldest first
+func (t *InFlightTracker) Snapshot() []InFlightRequest {
+	t.mu.Lock()
+	requests := make([]InFlightRequest, 0, len(t.requests))
+	for _, req := range t.requests {
+		requests = append(requests, req)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].StartedAt.Before(requests[j].StartedAt)
+	})
+	return requests
+}
+
+// add registers a request and returns its tracking ID
+func (t *InFlightTracker) add(req InFlightRequest) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	t.requests[t.nextID] = req
+	return t.nextID
+}
+
+// remove unregisters a finished request
+func (t *InFlightTracker) remove(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.requests, id)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// logFieldsKey is the context key for the request's LogFields
+type logFieldsKey struct{}
+
+// LogFields holds request attributes resolved by handlers (such as the target index)
+// so that logging middleware can emit them after the handler returns
+type LogFields struct {
+	mu        sync.Mutex
+	index     string
+	operation string
+}
+
+// WithLogFields attaches an empty LogFields to the context
+func WithLogFields(ctx context.Context) (context.Context, *LogFields) {
+	fields := &LogFields{}
+	return context.WithValue(ctx, logFieldsKey{}, fields), fields
+}
+
+// SetLogFields records the operation and index handled by the request.
+// Empty values leave the current ones untouched; it is a no-op without WithLogFields.
+func SetLogFields(ctx context.Context, operation, index string) {
+	fields, ok := ctx.Value(logFieldsKey{}).(*LogFields)
+	if !ok {
+		return
+	}
+
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	if operation != "" {
+		fields.operation = operation
+	}
+	if index != "" {
+		fields.index = index
+	}
+}
+
+// Index returns the recorded index
+func (f *LogFields) Index() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.index
+}
+
+// Operation returns the recorded operation
+func (f *LogFields) Operation() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.operation
+}
+
+// jsonFields renders the recorded fields as additional JSON members (leading comma included)
+func (f *LogFields) jsonFields() string {
+	var b strings.Builder
+	if index := f.Index(); index != "" {
+		fmt.Fprintf(&b, `,"index":%q`, index)
+	}
+	if operation := f.Operation(); operation != "" {
+		fmt.Fprintf(&b, `,"operation":%q`, operation)
+	}
+	return b.String()
+}
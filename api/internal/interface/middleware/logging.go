@@ -5,24 +5,63 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
 // RequestIDKey is the key for request ID in context
 type RequestIDKey struct{}
 
+// LogLevel is the minimum severity emitted by LoggingMiddleware's Log* methods
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarning
+	LogLevelError
+)
+
+// ParseLogLevel parses a case-insensitive level name ("debug", "info", "warn"/"warning" or "error")
+func ParseLogLevel(level string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarning, nil
+	case "error":
+		return LogLevelError, nil
+	}
+	return LogLevelInfo, fmt.Errorf("unknown log level %q", level)
+}
+
 // LoggingMiddleware provides request logging functionality
 type LoggingMiddleware struct {
 	logger *log.Logger
+	level  LogLevel
 }
 
-// NewLoggingMiddleware creates a new logging middleware
+// NewLoggingMiddleware creates a new logging middleware that emits every level
 func NewLoggingMiddleware(logger *log.Logger) *LoggingMiddleware {
+	return NewLoggingMiddlewareWithLevel(logger, LogLevelDebug)
+}
+
+// NewLoggingMiddlewareWithLevel creates a new logging middleware that drops
+// LogDebug/LogInfo/LogWarning/LogError messages below level
+func NewLoggingMiddlewareWithLevel(logger *log.Logger, level LogLevel) *LoggingMiddleware {
 	return &LoggingMiddleware{
 		logger: logger,
+		level:  level,
 	}
 }
 
+// Enabled reports whether messages at level are emitted
+func (m *LoggingMiddleware) Enabled(level LogLevel) bool {
+	return level >= m.level
+}
+
 // generateRequestID generates a simple request ID
 func generateRequestID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
@@ -99,6 +138,9 @@ func (m *LoggingMiddleware) LogRequestWithBody(next http.Handler) http.Handler {
 
 // LogError logs errors with context
 func (m *LoggingMiddleware) LogError(requestID string, err error, message string) {
+	if !m.Enabled(LogLevelError) {
+		return
+	}
 	if requestID == "" {
 		requestID = "unknown"
 	}
@@ -107,6 +149,9 @@ func (m *LoggingMiddleware) LogError(requestID string, err error, message string
 
 // LogInfo logs informational messages
 func (m *LoggingMiddleware) LogInfo(requestID string, message string) {
+	if !m.Enabled(LogLevelInfo) {
+		return
+	}
 	if requestID == "" {
 		requestID = "unknown"
 	}
@@ -115,6 +160,9 @@ func (m *LoggingMiddleware) LogInfo(requestID string, message string) {
 
 // LogWarning logs warning messages
 func (m *LoggingMiddleware) LogWarning(requestID string, message string) {
+	if !m.Enabled(LogLevelWarning) {
+		return
+	}
 	if requestID == "" {
 		requestID = "unknown"
 	}
@@ -123,6 +171,9 @@ func (m *LoggingMiddleware) LogWarning(requestID string, message string) {
 
 // LogDebug logs debug messages
 func (m *LoggingMiddleware) LogDebug(requestID string, message string) {
+	if !m.Enabled(LogLevelDebug) {
+		return
+	}
 	if requestID == "" {
 		requestID = "unknown"
 	}
@@ -141,6 +192,16 @@ func (w *responseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Flush sends buffered data to the client so streamed (NDJSON) responses are not held back
+func (w *responseWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // GetRequestID extracts request ID from context
 func GetRequestID(ctx context.Context) string {
 	if id, ok := ctx.Value(RequestIDKey{}).(string); ok {
@@ -226,8 +287,9 @@ func StructuredLogMiddleware(logger *log.Logger) func(http.Handler) http.Handler
 			// Generate request ID
 			requestID := generateRequestID()
 
-			// Add request ID to context
+			// Add request ID and handler-resolved log fields to context
 			ctx := context.WithValue(r.Context(), RequestIDKey{}, requestID)
+			ctx, fields := WithLogFields(ctx)
 			r = r.WithContext(ctx)
 
 			// Start timer
@@ -245,11 +307,12 @@ func StructuredLogMiddleware(logger *log.Logger) func(http.Handler) http.Handler
 			// Calculate duration
 			duration := time.Since(start)
 
-			// Structured log entry
-			logger.Printf(`{"request_id":"%s","method":"%s","path":"%s","status":%d,"duration_ms":%d,"remote_addr":"%s","user_agent":"%s","timestamp":"%s"}`,
+			// Structured log entry (index/operation are included when a handler set them)
+			logger.Printf(`{"request_id":"%s","method":"%s","path":"%s"%s,"status":%d,"duration_ms":%d,"remote_addr":"%s","user_agent":"%s","timestamp":"%s"}`,
 				requestID,
 				r.Method,
 				r.URL.Path,
+				fields.jsonFields(),
 				ww.statusCode,
 				duration.Milliseconds(),
 				r.RemoteAddr,
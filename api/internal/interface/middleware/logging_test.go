@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWritersFlushToConnection(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(w http.ResponseWriter) http.ResponseWriter
+	}{
+		{
+			name: "responseWriter",
+			wrap: func(w http.ResponseWriter) http.ResponseWriter {
+				return &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			},
+		},
+		{
+			name: "bodyLogResponseWriter",
+			wrap: func(w http.ResponseWriter) http.ResponseWriter {
+				return &bodyLogResponseWriter{
+					responseWriter: responseWriter{ResponseWriter: w, statusCode: http.StatusOK},
+					body:           &cappedBuffer{limit: maxBodyCaptureSize},
+				}
+			},
+		},
+		{
+			name: "nested",
+			wrap: func(w http.ResponseWriter) http.ResponseWriter {
+				inner := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+				return &bodyLogResponseWriter{
+					responseWriter: responseWriter{ResponseWriter: inner, statusCode: http.StatusOK},
+					body:           &cappedBuffer{limit: maxBodyCaptureSize},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			w := tt.wrap(recorder)
+
+			w.Write([]byte(`{"type":"progress"}` + "\n"))
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if !recorder.Flushed {
+				t.Error("Flush did not reach the underlying writer")
+			}
+		})
+	}
+}
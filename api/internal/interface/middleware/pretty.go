@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+)
+
+// PrettyJSONMiddleware makes handlers write indented JSON when the request asks for it
+// with ?pretty=true or an X-Pretty: true header. Responses stay compact by default.
+// It must be the innermost middleware so handlers receive the marked writer directly.
+func PrettyJSONMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if utils.WantsPrettyJSON(r) {
+			w = utils.PrettyJSONWriter(w)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
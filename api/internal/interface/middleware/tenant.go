@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+	"github.com/Yuki-TU/elastic-search/api/pkg/utils"
+)
+
+// DefaultTenantHeader is the request header carrying the tenant ID when none is set by authentication
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// tenantIDPattern restricts tenant IDs to characters that are valid in index names and
+// cannot contain the separator of the index prefix, so one tenant's prefix never matches another's
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]{1,64}$`)
+
+// ValidTenantID reports whether id can be used as a tenant ID
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+// TenantConfig configures how the tenant of a request is resolved
+type TenantConfig struct {
+	// Header is the request header naming the tenant (DefaultTenantHeader when empty)
+	Header string
+	// APIKeys maps API keys sent in the X-API-Key header to the tenant they are issued for
+	APIKeys map[string]string
+	// TrustHeader lets the header alone select the tenant. Enable it only behind a gateway
+	// that authenticates callers and sets the header itself, overwriting any client value.
+	TrustHeader bool
+}
+
+// TenantMiddleware resolves the tenant of each request and stores it in the context, where
+// the tenant-scoped repository reads it to prefix index names. The tenant comes from the
+// authentication layer or from a tenant API key; the header may only repeat it, and a header
+// naming a different tenant is rejected with 403. The header alone selects the tenant only
+// when config.TrustHeader is set. Requests without a valid tenant are rejected with 403.
+// Health checks, server info and CORS preflights are exempt.
+// Responses vary by the tenant header, so it is added to Vary for shared caches.
+func TenantMiddleware(config TenantConfig) func(http.Handler) http.Handler {
+	header := config.Header
+	if header == "" {
+		header = DefaultTenantHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || r.URL.Path == "/health" || r.URL.Path == "/info" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", header)
+
+			requested := r.Header.Get(header)
+			tenant := auth.Tenant(r.Context())
+			if tenant == "" {
+				tenant = tenantForKey(r.Header.Get("X-API-Key"), config.APIKeys)
+			}
+			switch {
+			case tenant != "":
+				if requested != "" && requested != tenant {
+					writeTenantError(w, "Access to another tenant is not allowed")
+					return
+				}
+			case requested == "":
+				writeTenantError(w, "Tenant is required")
+				return
+			case !config.TrustHeader:
+				writeTenantError(w, "Tenant credentials are required")
+				return
+			case !ValidTenantID(requested):
+				writeTenantError(w, "Invalid tenant ID")
+				return
+			default:
+				tenant = requested
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithTenant(r.Context(), tenant)))
+		})
+	}
+}
+
+// tenantForKey returns the tenant the provided API key is issued for, comparing it against
+// every configured key in constant time. It returns "" when the key matches none.
+func tenantForKey(provided string, apiKeys map[string]string) string {
+	if provided == "" {
+		return ""
+	}
+	tenant := ""
+	for key, keyTenant := range apiKeys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+			tenant = keyTenant
+		}
+	}
+	return tenant
+}
+
+// writeTenantError rejects the request with 403
+func writeTenantError(w http.ResponseWriter, message string) {
+	utils.NewResponseWriter(w).WriteError(errors.NewAppError(errors.ErrCodeForbidden, message))
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	keys := map[string]string{"acme-key": "acme"}
+
+	tests := []struct {
+		name        string
+		trustHeader bool
+		apiKey      string
+		tenant      string
+		wantStatus  int
+		wantTenant  string
+	}{
+		{name: "api key", apiKey: "acme-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "api key with the same tenant header", apiKey: "acme-key", tenant: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "api key with another tenant header", apiKey: "acme-key", tenant: "globex", wantStatus: http.StatusForbidden},
+		// ゲートウェイを信頼しない設定では、ヘッダーだけで任意のテナントを選べない
+		{name: "header without credentials", tenant: "globex", wantStatus: http.StatusForbidden},
+		{name: "unknown api key", apiKey: "other", tenant: "globex", wantStatus: http.StatusForbidden},
+		{name: "trusted header", trustHeader: true, tenant: "globex", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "trusted header with an invalid tenant", trustHeader: true, tenant: "Globex", wantStatus: http.StatusForbidden},
+		{name: "no tenant", trustHeader: true, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenant string
+			handler := TenantMiddleware(TenantConfig{Header: "X-Org", APIKeys: keys, TrustHeader: tt.trustHeader})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenant = auth.Tenant(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/search?q=go", nil)
+			if tt.apiKey != "" {
+				r.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.tenant != "" {
+				r.Header.Set("X-Org", tt.tenant)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", tenant, tt.wantTenant)
+			}
+			if got := w.Header().Get("Vary"); got != "X-Org" {
+				t.Errorf("Vary = %q, want the configured tenant header", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+// TrustedSubject is the authenticated subject recorded for requests carrying a trusted API key
+const TrustedSubject = "trusted"
+
+// TrustedCallerMiddleware marks requests that send one of the configured keys in the
+// X-API-Key header as trusted, which relaxes search safeguards such as the sort field
+// allowlist and query sanitization. Requests without a valid key are served as public
+// traffic with the default, strict rules; they are never rejected.
+func TrustedCallerMiddleware(apiKeys []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-API-Key")
+			if provided != "" && isTrustedKey(provided, apiKeys) {
+				ctx := auth.WithSubject(auth.WithTrusted(r.Context()), TrustedSubject)
+				r = r.WithContext(ctx)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isTrustedKey compares the provided key against every configured key in constant time
+func isTrustedKey(provided string, apiKeys []string) bool {
+	trusted := false
+	for _, key := range apiKeys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+			trusted = true
+		}
+	}
+	return trusted
+}
@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+// subjectKey はコンテキスト内の認証済み主体のキー
+type subjectKey struct{}
+
+// WithSubject は認証済みの操作主体をコンテキストに設定する
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// Subject はコンテキストから認証済みの操作主体を取得する（未認証の場合は空文字）
+func Subject(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey{}).(string)
+	return subject
+}
@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+// tenantKey はコンテキスト内のテナントIDのキー
+type tenantKey struct{}
+
+// WithTenant はリクエストのテナントIDをコンテキストに設定する
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant はコンテキストからテナントIDを取得する（未設定の場合は空文字）
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+// trustedKey はコンテキスト内の信頼済み呼び出し元フラグのキー
+type trustedKey struct{}
+
+// WithTrusted はリクエストが信頼済みの呼び出し元（内部サービスなど）からのものであることをコンテキストに設定する
+func WithTrusted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedKey{}, true)
+}
+
+// IsTrusted はリクエストが信頼済みの呼び出し元からのものかどうかを返す（未設定の場合は false）
+func IsTrusted(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedKey{}).(bool)
+	return trusted
+}
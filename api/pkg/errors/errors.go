@@ -1,8 +1,11 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -17,6 +20,9 @@ const (
 	ErrCodeDocumentCreateFailed ErrorCode = "DOCUMENT_CREATE_FAILED"
 	ErrCodeDocumentUpdateFailed ErrorCode = "DOCUMENT_UPDATE_FAILED"
 	ErrCodeDocumentDeleteFailed ErrorCode = "DOCUMENT_DELETE_FAILED"
+	ErrCodePreconditionFailed   ErrorCode = "PRECONDITION_FAILED"
+	ErrCodeVersionConflict      ErrorCode = "VERSION_CONFLICT"
+	ErrCodeIdempotencyConflict  ErrorCode = "IDEMPOTENCY_KEY_CONFLICT"
 
 	// 検索関連のエラー
 	ErrCodeSearchFailed  ErrorCode = "SEARCH_FAILED"
@@ -35,11 +41,17 @@ const (
 	ErrCodeInvalidRequest   ErrorCode = "INVALID_REQUEST"
 	ErrCodeMissingParameter ErrorCode = "MISSING_PARAMETER"
 	ErrCodeInvalidParameter ErrorCode = "INVALID_PARAMETER"
+	ErrCodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrCodeRouteNotFound    ErrorCode = "ROUTE_NOT_FOUND"
 
 	// インフラストラクチャエラー
 	ErrCodeElasticsearchDown ErrorCode = "ELASTICSEARCH_DOWN"
 	ErrCodeConnectionFailed  ErrorCode = "CONNECTION_FAILED"
 	ErrCodeTimeout           ErrorCode = "TIMEOUT"
+	ErrCodeRequestCanceled   ErrorCode = "REQUEST_CANCELED"
+	ErrCodePayloadTooLarge   ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrCodeRateLimited       ErrorCode = "RATE_LIMITED"
+	ErrCodeQueueFull         ErrorCode = "QUEUE_FULL"
 	ErrCodeInternalError     ErrorCode = "INTERNAL_ERROR"
 
 	// 認証/認可エラー
@@ -56,7 +68,31 @@ type AppError struct {
 	Cause      error          `json:"-"`
 	Timestamp  time.Time      `json:"timestamp"`
 	Context    map[string]any `json:"context,omitempty"`
+	Fields     []FieldError   `json:"fields,omitempty"`
 	HTTPStatus int            `json:"-"`
+	RetryAfter string         `json:"-"` // 再試行までの待機時間（Retry-After ヘッダーの値）
+}
+
+// FieldError はフィールド単位のバリデーションエラーを表す
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors は複数のフィールドエラーを蓄積する
+type FieldErrors []FieldError
+
+// Add はフィールドエラーを追加する
+func (f *FieldErrors) Add(field, message string) {
+	*f = append(*f, FieldError{Field: field, Message: message})
+}
+
+// Err は蓄積したフィールドエラーをバリデーションエラーとして返す（エラーがなければ nil）
+func (f FieldErrors) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return NewFieldValidationError(f)
 }
 
 // Error は error インターフェースを実装する
@@ -113,30 +149,49 @@ func (e *AppError) WithContext(key string, value any) *AppError {
 	return e
 }
 
+// WithFields はエラーにフィールドエラーを追加する
+func (e *AppError) WithFields(fields ...FieldError) *AppError {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
 // WithHTTPStatus は HTTP ステータスコードを設定する
 func (e *AppError) WithHTTPStatus(status int) *AppError {
 	e.HTTPStatus = status
 	return e
 }
 
+// StatusClientClosedRequest はクライアントが応答を待たずに接続を閉じたことを表す非標準ステータス
+const StatusClientClosedRequest = 499
+
 // getHTTPStatusForCode はエラーコードに対応する適切な HTTP ステータスコードを返す
 func getHTTPStatusForCode(code ErrorCode) int {
 	switch code {
-	case ErrCodeDocumentNotFound, ErrCodeIndexNotFound:
+	case ErrCodeDocumentNotFound, ErrCodeIndexNotFound, ErrCodeRouteNotFound:
 		return http.StatusNotFound
-	case ErrCodeDocumentExists, ErrCodeIndexExists:
+	case ErrCodeDocumentExists, ErrCodeIndexExists, ErrCodeVersionConflict, ErrCodeIdempotencyConflict:
 		return http.StatusConflict
+	case ErrCodePreconditionFailed:
+		return http.StatusPreconditionFailed
 	case ErrCodeValidationFailed, ErrCodeInvalidRequest, ErrCodeMissingParameter,
 		ErrCodeInvalidParameter, ErrCodeInvalidQuery, ErrCodeInvalidDocument, ErrCodeInvalidMapping:
 		return http.StatusBadRequest
+	case ErrCodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
 	case ErrCodeUnauthorized, ErrCodeAuthenticationFailed:
 		return http.StatusUnauthorized
 	case ErrCodeForbidden:
 		return http.StatusForbidden
 	case ErrCodeTimeout, ErrCodeSearchTimeout:
 		return http.StatusRequestTimeout
-	case ErrCodeElasticsearchDown, ErrCodeConnectionFailed:
+	case ErrCodeRequestCanceled:
+		return StatusClientClosedRequest
+	case ErrCodeElasticsearchDown, ErrCodeConnectionFailed, ErrCodeQueueFull:
 		return http.StatusServiceUnavailable
+	case ErrCodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrCodeRateLimited:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -151,6 +206,18 @@ func NewDocumentExistsError(index, id string) *AppError {
 	return NewAppError(ErrCodeDocumentExists, fmt.Sprintf("Document already exists: %s/%s", index, id))
 }
 
+func NewPreconditionFailedError(index, id string) *AppError {
+	return NewAppError(ErrCodePreconditionFailed, fmt.Sprintf("Document has been modified: %s/%s", index, id))
+}
+
+func NewVersionConflictError(index, id string, version int64) *AppError {
+	return NewAppError(ErrCodeVersionConflict, fmt.Sprintf("Document %s/%s already has a version greater than or equal to %d", index, id, version))
+}
+
+func NewUpdateConflictError(index, id string) *AppError {
+	return NewAppError(ErrCodeVersionConflict, fmt.Sprintf("Document %s/%s was modified concurrently and retries were exhausted", index, id))
+}
+
 func NewIndexNotFoundError(index string) *AppError {
 	return NewAppError(ErrCodeIndexNotFound, fmt.Sprintf("Index not found: %s", index))
 }
@@ -163,6 +230,14 @@ func NewValidationError(field, message string) *AppError {
 	return NewAppError(ErrCodeValidationFailed, fmt.Sprintf("Validation failed for field '%s': %s", field, message))
 }
 
+func NewFieldValidationError(fields []FieldError) *AppError {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = fmt.Sprintf("%s: %s", field.Field, field.Message)
+	}
+	return NewAppError(ErrCodeValidationFailed, "Validation failed: "+strings.Join(messages, "; ")).WithFields(fields...)
+}
+
 func NewSearchError(query string, cause error) *AppError {
 	return NewAppErrorWithCause(ErrCodeSearchFailed, fmt.Sprintf("Search failed for query: %s", query), cause)
 }
@@ -179,6 +254,33 @@ func NewInternalError(message string, cause error) *AppError {
 	return NewAppErrorWithCause(ErrCodeInternalError, message, cause)
 }
 
+func NewPayloadTooLargeError() *AppError {
+	return NewAppErrorWithDetails(ErrCodePayloadTooLarge, "Request payload is too large for Elasticsearch", "Reduce the request size and retry")
+}
+
+func NewRateLimitedError(retryAfter string) *AppError {
+	err := NewAppErrorWithDetails(ErrCodeRateLimited, "Elasticsearch is rejecting requests due to load", "Back off and retry later")
+	err.RetryAfter = retryAfter
+	return err
+}
+
+func NewQueueFullError(message, retryAfter string) *AppError {
+	err := NewAppErrorWithDetails(ErrCodeQueueFull, message, "Retry later or index the document synchronously")
+	err.RetryAfter = retryAfter
+	return err
+}
+
+// IsRetryable は同じリクエストを時間をおいて再試行すれば成功しうるエラーかどうかを返す
+func (e *AppError) IsRetryable() bool {
+	return e.Code == ErrCodeRateLimited || e.Code == ErrCodeQueueFull
+}
+
+// HasCode はエラーが指定したコードの AppError かどうかをチェックする
+func HasCode(err error, code ErrorCode) bool {
+	appErr := GetAppError(err)
+	return appErr != nil && appErr.Code == code
+}
+
 // IsAppError はエラーが AppError かどうかをチェックする
 func IsAppError(err error) bool {
 	_, ok := err.(*AppError)
@@ -194,6 +296,19 @@ func GetAppError(err error) *AppError {
 }
 
 // WrapError は一般的なエラーを AppError にラップする
+// コンテキストの期限切れ・キャンセルに起因するエラーは、指定したコードに関わらず
+// TIMEOUT（408）または REQUEST_CANCELED（499）としてラップする
+//
+// Elasticsearch のサイズ超過（413）・過負荷（429）はクライアントの対処が異なるため、ラップせずにそのまま返す
 func WrapError(err error, code ErrorCode, message string) *AppError {
+	if appErr := GetAppError(err); appErr != nil && (appErr.Code == ErrCodePayloadTooLarge || appErr.Code == ErrCodeRateLimited) {
+		return appErr
+	}
+	switch {
+	case stderrors.Is(err, context.DeadlineExceeded):
+		code = ErrCodeTimeout
+	case stderrors.Is(err, context.Canceled):
+		code = ErrCodeRequestCanceled
+	}
 	return NewAppErrorWithCause(code, message, err)
 }
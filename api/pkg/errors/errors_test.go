@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestWrapErrorContextErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   ErrorCode
+		wantStatus int
+	}{
+		{name: "deadline exceeded", err: context.DeadlineExceeded, wantCode: ErrCodeTimeout, wantStatus: http.StatusRequestTimeout},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("perform request: %w", context.DeadlineExceeded), wantCode: ErrCodeTimeout, wantStatus: http.StatusRequestTimeout},
+		{name: "canceled", err: context.Canceled, wantCode: ErrCodeRequestCanceled, wantStatus: StatusClientClosedRequest},
+		{name: "wrapped canceled", err: fmt.Errorf("perform request: %w", context.Canceled), wantCode: ErrCodeRequestCanceled, wantStatus: StatusClientClosedRequest},
+		{name: "already wrapped timeout", err: NewAppErrorWithCause(ErrCodeSearchFailed, "search", context.DeadlineExceeded), wantCode: ErrCodeTimeout, wantStatus: http.StatusRequestTimeout},
+		{name: "other error", err: stderrors.New("connection reset"), wantCode: ErrCodeSearchFailed, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapError(tt.err, ErrCodeSearchFailed, "Search failed")
+			if got.Code != tt.wantCode {
+				t.Errorf("Code = %s, want %s", got.Code, tt.wantCode)
+			}
+			if got.HTTPStatus != tt.wantStatus {
+				t.Errorf("HTTPStatus = %d, want %d", got.HTTPStatus, tt.wantStatus)
+			}
+			if !stderrors.Is(got, tt.err) {
+				t.Errorf("WrapError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
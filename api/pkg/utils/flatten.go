@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+)
+
+// FlattenMap flattens nested objects into a single-level map with dotted keys.
+// Array elements are addressed by index (e.g. "tags.0"), empty objects and
+// empty arrays are kept as leaf values so the column still exists.
+// Keys are visited in sorted order, so when a literal dotted key collides with
+// a flattened path (e.g. "a.b" and {"a": {"b": ...}}) the lexically later
+// source key wins deterministically.
+func FlattenMap(m map[string]any) map[string]any {
+	result := make(map[string]any)
+	flattenValue(result, "", m)
+	return result
+}
+
+// flattenValue writes value into result under prefix, recursing into objects and arrays
+func flattenValue(result map[string]any, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			if prefix != "" {
+				result[prefix] = map[string]any{}
+			}
+			return
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			flattenValue(result, joinPath(prefix, key), v[key])
+		}
+	case []any:
+		if len(v) == 0 {
+			if prefix != "" {
+				result[prefix] = []any{}
+			}
+			return
+		}
+
+		for i, item := range v {
+			flattenValue(result, joinPath(prefix, strconv.Itoa(i)), item)
+		}
+	default:
+		result[prefix] = v
+	}
+}
+
+// joinPath joins a prefix and a key with a dot
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
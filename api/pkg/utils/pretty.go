@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+)
+
+// prettyIndent is the indentation used for pretty-printed JSON responses
+const prettyIndent = "  "
+
+// prettyWriter marks a response that ResponseWriter should encode as indented JSON
+type prettyWriter struct {
+	http.ResponseWriter
+}
+
+// PrettyJSONWriter wraps w so that a ResponseWriter created from it writes indented JSON
+func PrettyJSONWriter(w http.ResponseWriter) http.ResponseWriter {
+	return &prettyWriter{ResponseWriter: w}
+}
+
+// Flush forwards to the underlying writer so streaming handlers keep working
+func (w *prettyWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (w *prettyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WantsPrettyJSON reports whether the request asks for indented JSON,
+// either with ?pretty=true (a bare ?pretty also counts) or an X-Pretty: true header
+func WantsPrettyJSON(r *http.Request) bool {
+	if values, ok := r.URL.Query()["pretty"]; ok {
+		value := values[0]
+		return value == "" || strings.EqualFold(value, "true")
+	}
+	return strings.EqualFold(r.Header.Get("X-Pretty"), "true")
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+// ErrEmptyBody is the cause of the error ParseRequestBody returns for a missing or blank body
+var ErrEmptyBody = stderrors.New("request body is empty")
+
+// IsEmptyBody reports whether err was returned by ParseRequestBody for a missing or blank body
+func IsEmptyBody(err error) bool {
+	return stderrors.Is(err, ErrEmptyBody)
+}
+
+// ParseOptions holds JSON request body parsing configuration
+type ParseOptions struct {
+	// MaxDepth is the maximum nesting depth of objects and arrays (0 disables the check)
+	MaxDepth int
+	// DisallowUnknownFields rejects fields that do not exist in the target struct
+	DisallowUnknownFields bool
+	// MaxBodySize is the maximum number of bytes read from the body (0 disables the check).
+	// It also bounds bodies that bypass the size limit middleware, such as decompressed ones.
+	MaxBodySize int64
+}
+
+// DefaultParseOptions returns default parsing configuration
+func DefaultParseOptions() *ParseOptions {
+	return &ParseOptions{
+		MaxDepth:              32,
+		DisallowUnknownFields: false,
+		MaxBodySize:           10 << 20,
+	}
+}
+
+// parseOptions is the configuration used by ParseRequestBody
+var parseOptions = DefaultParseOptions()
+
+// SetParseOptions sets the configuration used by ParseRequestBody
+func SetParseOptions(opts *ParseOptions) {
+	if opts == nil {
+		opts = DefaultParseOptions()
+	}
+	parseOptions = opts
+}
+
+// ParseRequestBody parses JSON request body
+func ParseRequestBody(r *http.Request, v any) error {
+	return ParseRequestBodyWithOptions(r, v, parseOptions)
+}
+
+// ParseRequestBodyWithOptions parses JSON request body with the given options
+func ParseRequestBodyWithOptions(r *http.Request, v any, opts *ParseOptions) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return errors.NewAppErrorWithCause(errors.ErrCodeInvalidRequest, "Request body is empty", ErrEmptyBody)
+	}
+
+	defer r.Body.Close()
+
+	if opts == nil {
+		opts = DefaultParseOptions()
+	}
+
+	data, err := readBody(r.Body, opts.MaxBodySize)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return errors.NewAppErrorWithCause(errors.ErrCodeInvalidRequest, "Request body is empty", ErrEmptyBody)
+	}
+
+	// Check structure before decoding into the target
+	if err := validateJSONStructure(data, opts.MaxDepth); err != nil {
+		return errors.NewAppError(errors.ErrCodeInvalidRequest, "Invalid JSON format: "+err.Error())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return errors.NewAppError(errors.ErrCodeInvalidRequest, "Invalid JSON format: "+err.Error())
+	}
+
+	return nil
+}
+
+// ReadRawJSONBody reads the request body and checks its JSON structure without decoding it,
+// so that the caller can pass the original bytes through unchanged.
+// A body exceeding the request size limit is reported as PAYLOAD_TOO_LARGE.
+func ReadRawJSONBody(r *http.Request) (json.RawMessage, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, errors.NewAppErrorWithCause(errors.ErrCodeInvalidRequest, "Request body is empty", ErrEmptyBody)
+	}
+
+	defer r.Body.Close()
+
+	data, err := readBody(r.Body, parseOptions.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.NewAppErrorWithCause(errors.ErrCodeInvalidRequest, "Request body is empty", ErrEmptyBody)
+	}
+
+	if err := validateJSONStructure(data, parseOptions.MaxDepth); err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInvalidRequest, "Invalid JSON format: "+err.Error())
+	}
+	if !json.Valid(data) {
+		return nil, errors.NewAppError(errors.ErrCodeInvalidRequest, "Invalid JSON format")
+	}
+
+	return data, nil
+}
+
+// readBody reads at most limit bytes from body (no limit when limit is 0).
+// Exceeding the limit, or the limit of an http.MaxBytesReader, is reported as PAYLOAD_TOO_LARGE.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit > 0 {
+		body = io.LimitReader(body, limit+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			return nil, payloadTooLarge(maxBytesErr.Limit)
+		}
+		return nil, errors.NewAppError(errors.ErrCodeInvalidRequest, "Failed to read request body: "+err.Error())
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		return nil, payloadTooLarge(limit)
+	}
+	return data, nil
+}
+
+// payloadTooLarge returns the error for a body exceeding limit bytes
+func payloadTooLarge(limit int64) error {
+	return errors.NewAppError(errors.ErrCodePayloadTooLarge, fmt.Sprintf("Request body exceeds the limit of %d bytes", limit))
+}
+
+// jsonFrame tracks the state of an object or array while walking JSON tokens
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	keys      map[string]struct{}
+}
+
+// validateJSONStructure checks nesting depth and duplicate object keys
+func validateJSONStructure(data []byte, maxDepth int) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var stack []*jsonFrame
+
+	// markValue records that the enclosing object has consumed a value
+	markValue := func() {
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if maxDepth > 0 && len(stack) >= maxDepth {
+					return fmt.Errorf("maximum nesting depth of %d exceeded", maxDepth)
+				}
+				markValue()
+				frame := &jsonFrame{object: t == '{', expectKey: t == '{'}
+				if frame.object {
+					frame.keys = make(map[string]struct{})
+				}
+				stack = append(stack, frame)
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		case string:
+			if len(stack) > 0 {
+				top := stack[len(stack)-1]
+				if top.object && top.expectKey {
+					if _, exists := top.keys[t]; exists {
+						return fmt.Errorf("duplicate key %q", t)
+					}
+					top.keys[t] = struct{}{}
+					top.expectKey = false
+					continue
+				}
+			}
+		}
+
+		markValue()
+	}
+}
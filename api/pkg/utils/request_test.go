@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
+)
+
+func TestParseRequestBodyWithOptionsMaxBodySize(t *testing.T) {
+	body := `{"query":"laptop"}`
+
+	tests := []struct {
+		name     string
+		limit    int64
+		wrap     func(w http.ResponseWriter, r *http.Request)
+		wantCode errors.ErrorCode
+	}{
+		{name: "within limit", limit: int64(len(body))},
+		{name: "no limit", limit: 0},
+		{name: "over limit", limit: int64(len(body)) - 1, wantCode: errors.ErrCodePayloadTooLarge},
+		{
+			name:  "over middleware limit",
+			limit: 0,
+			wrap: func(w http.ResponseWriter, r *http.Request) {
+				r.Body = http.MaxBytesReader(w, r.Body, 4)
+			},
+			wantCode: errors.ErrCodePayloadTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(body))
+			if tt.wrap != nil {
+				tt.wrap(httptest.NewRecorder(), r)
+			}
+
+			var v map[string]any
+			err := ParseRequestBodyWithOptions(r, &v, &ParseOptions{MaxBodySize: tt.limit})
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("ParseRequestBodyWithOptions() error = %v", err)
+				}
+				if v["query"] != "laptop" {
+					t.Errorf("query = %v, want laptop", v["query"])
+				}
+				return
+			}
+			if !errors.HasCode(err, tt.wantCode) {
+				t.Errorf("ParseRequestBodyWithOptions() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestReadRawJSONBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode errors.ErrorCode
+	}{
+		{name: "object", body: `{"id":12345678901234567890,"title":"Go"}`},
+		{name: "empty", body: "", wantCode: errors.ErrCodeInvalidRequest},
+		{name: "whitespace", body: " \n", wantCode: errors.ErrCodeInvalidRequest},
+		{name: "invalid json", body: `{"title":}`, wantCode: errors.ErrCodeInvalidRequest},
+		{name: "too large", body: `{"title":"` + strings.Repeat("a", int(parseOptions.MaxBodySize)) + `"}`, wantCode: errors.ErrCodePayloadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/documents", strings.NewReader(tt.body))
+
+			raw, err := ReadRawJSONBody(r)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("ReadRawJSONBody() error = %v", err)
+				}
+				// The body is returned as is, without decoding numbers
+				if string(raw) != tt.body {
+					t.Errorf("ReadRawJSONBody() = %s, want %s", raw, tt.body)
+				}
+				return
+			}
+			if !errors.HasCode(err, tt.wantCode) {
+				t.Errorf("ReadRawJSONBody() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -1,28 +1,140 @@
 package utils
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
 	"github.com/Yuki-TU/elastic-search/api/pkg/errors"
 )
 
 // ResponseWriter provides utilities for writing HTTP responses
 type ResponseWriter struct {
 	writer http.ResponseWriter
+	pretty bool
 }
 
-// NewResponseWriter creates a new ResponseWriter
+// NewResponseWriter creates a new ResponseWriter.
+// JSON is written compactly unless w was wrapped with PrettyJSONWriter.
 func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
-	return &ResponseWriter{writer: w}
+	_, pretty := w.(*prettyWriter)
+	return &ResponseWriter{writer: w, pretty: pretty}
+}
+
+// newEncoder returns a JSON encoder for dst that honors the pretty-print setting
+func (rw *ResponseWriter) newEncoder(dst io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(dst)
+	if rw.pretty {
+		encoder.SetIndent("", prettyIndent)
+	}
+	return encoder
 }
 
 // WriteJSON writes a JSON response
 func (rw *ResponseWriter) WriteJSON(statusCode int, data any) error {
 	rw.writer.Header().Set("Content-Type", "application/json")
 	rw.writer.WriteHeader(statusCode)
-	return json.NewEncoder(rw.writer).Encode(data)
+	return rw.newEncoder(rw.writer).Encode(data)
+}
+
+// ETagPayloader is implemented by responses that carry values which change on every
+// request (timings, profiling output); ETagPayload returns the part of the response
+// that identifies its content
+type ETagPayloader interface {
+	ETagPayload() any
+}
+
+// WriteCacheableJSON writes a 200 JSON response that clients and CDNs may cache.
+// A weak ETag is computed from the response content (the ETagPayload when data
+// implements ETagPayloader); when it matches the request's If-None-Match header
+// a 304 Not Modified is written without a body.
+// Responses scoped to a tenant or to authenticated callers are marked private so
+// that shared caches never serve them to another caller.
+func (rw *ResponseWriter) WriteCacheableJSON(r *http.Request, data any, maxAge time.Duration) error {
+	var body bytes.Buffer
+	if err := rw.newEncoder(&body).Encode(data); err != nil {
+		return rw.WriteInternalError("Failed to encode response", err)
+	}
+
+	etag, err := computeETag(data)
+	if err != nil {
+		return rw.WriteInternalError("Failed to encode response", err)
+	}
+
+	header := rw.writer.Header()
+	header.Set("ETag", etag)
+	// The body differs when pretty-printing is requested by header,
+	// and the results differ per authenticated caller (the tenant middleware adds its header)
+	header.Add("Vary", "X-Pretty")
+	header.Add("Vary", "X-API-Key, Authorization")
+	if maxAge > 0 {
+		scope := "public"
+		if isCallerScoped(r) {
+			scope = "private"
+		}
+		header.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int(maxAge.Seconds())))
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		rw.writer.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	header.Set("Content-Type", "application/json")
+	rw.writer.WriteHeader(http.StatusOK)
+	_, err = rw.writer.Write(body.Bytes())
+	return err
+}
+
+// computeETag returns a weak ETag for the content of data.
+// The content is encoded without pretty-printing so that the ETag only changes with the data itself.
+func computeETag(data any) (string, error) {
+	if payloader, ok := data.(ETagPayloader); ok {
+		data = payloader.ETagPayload()
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// isCallerScoped reports whether the response depends on who made the request:
+// a resolved tenant, an authenticated or trusted caller, or credentials sent with the request
+func isCallerScoped(r *http.Request) bool {
+	ctx := r.Context()
+	if auth.Tenant(ctx) != "" || auth.Subject(ctx) != "" || auth.IsTrusted(ctx) {
+		return true
+	}
+	return r.Header.Get("Authorization") != "" || r.Header.Get("X-API-Key") != ""
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	target := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == target {
+			return true
+		}
+	}
+	return false
 }
 
 // WriteSuccess writes data directly without wrapper
@@ -38,6 +150,16 @@ func (rw *ResponseWriter) WriteError(err error) error {
 			appErr.Message,
 			appErr.Details,
 		)
+		for _, field := range appErr.Fields {
+			errorResponse.Error.Fields = append(errorResponse.Error.Fields, dto.FieldErrorDTO{
+				Field:   field.Field,
+				Message: field.Message,
+			})
+		}
+		errorResponse.Error.Retryable = appErr.IsRetryable()
+		if appErr.RetryAfter != "" {
+			rw.writer.Header().Set("Retry-After", appErr.RetryAfter)
+		}
 		return rw.WriteJSON(appErr.HTTPStatus, errorResponse)
 	}
 
@@ -116,7 +238,7 @@ func WriteJSONSuccess(w http.ResponseWriter, statusCode int, data any, message s
 func SetCORSHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Encoding, Authorization, Idempotency-Key, If-Match, If-None-Match, X-Pretty")
 	w.Header().Set("Access-Control-Max-Age", "86400")
 }
 
@@ -127,18 +249,3 @@ func SetSecurityHeaders(w http.ResponseWriter) {
 	w.Header().Set("X-XSS-Protection", "1; mode=block")
 	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
 }
-
-// ParseRequestBody parses JSON request body
-func ParseRequestBody(r *http.Request, v any) error {
-	if r.Body == nil {
-		return errors.NewAppError(errors.ErrCodeInvalidRequest, "Request body is empty")
-	}
-
-	defer r.Body.Close()
-
-	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
-		return errors.NewAppError(errors.ErrCodeInvalidRequest, "Invalid JSON format: "+err.Error())
-	}
-
-	return nil
-}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Yuki-TU/elastic-search/api/internal/application/dto"
+	"github.com/Yuki-TU/elastic-search/api/pkg/auth"
+)
+
+func TestWriteCacheableJSONCacheScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(r *http.Request) *http.Request
+		want    string
+	}{
+		{
+			name:    "anonymous",
+			prepare: func(r *http.Request) *http.Request { return r },
+			want:    "public, max-age=60",
+		},
+		{
+			name: "tenant",
+			prepare: func(r *http.Request) *http.Request {
+				return r.WithContext(auth.WithTenant(r.Context(), "acme"))
+			},
+			want: "private, max-age=60",
+		},
+		{
+			name: "trusted",
+			prepare: func(r *http.Request) *http.Request {
+				return r.WithContext(auth.WithTrusted(r.Context()))
+			},
+			want: "private, max-age=60",
+		},
+		{
+			name: "api key header",
+			prepare: func(r *http.Request) *http.Request {
+				r.Header.Set("X-API-Key", "secret")
+				return r
+			},
+			want: "private, max-age=60",
+		},
+		{
+			name: "authorization header",
+			prepare: func(r *http.Request) *http.Request {
+				r.Header.Set("Authorization", "Bearer token")
+				return r
+			},
+			want: "private, max-age=60",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.prepare(httptest.NewRequest(http.MethodGet, "/search?q=test", nil))
+			w := httptest.NewRecorder()
+
+			NewResponseWriter(w).WriteCacheableJSON(r, map[string]any{"total": 1}, time.Minute)
+
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+			vary := w.Header().Values("Vary")
+			if len(vary) != 2 || vary[1] != "X-API-Key, Authorization" {
+				t.Errorf("Vary = %q, want X-Pretty and the caller headers", vary)
+			}
+		})
+	}
+}
+
+func TestWriteCacheableJSONETagIgnoresTimings(t *testing.T) {
+	etag := func(result *dto.SearchResponse) string {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/search?q=test", nil)
+		NewResponseWriter(w).WriteCacheableJSON(r, result, time.Minute)
+		return w.Header().Get("ETag")
+	}
+
+	base := dto.SearchResponse{
+		Query:   dto.SearchQueryDTO{Query: "test"},
+		Results: []dto.HitDTO{{ID: "1"}},
+		Total:   1,
+		Took:    3,
+	}
+	slower := base
+	slower.Took = 250
+	slower.Profile = &dto.SearchProfileDTO{}
+	changed := base
+	changed.Total = 2
+
+	if etag(&base) != etag(&slower) {
+		t.Error("ETag changed with took and profile, want it to depend on the results only")
+	}
+	if etag(&base) == etag(&changed) {
+		t.Error("ETag did not change with the results")
+	}
+}
+
+func TestWriteCacheableJSONNotModified(t *testing.T) {
+	result := &dto.SearchResponse{Total: 1, Took: 3}
+
+	first := httptest.NewRecorder()
+	NewResponseWriter(first).WriteCacheableJSON(httptest.NewRequest(http.MethodGet, "/search", nil), result, time.Minute)
+
+	// 実行時間だけが異なる再検索でも 304 になる
+	result.Took = 40
+	r := httptest.NewRequest(http.MethodGet, "/search", nil)
+	r.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	second := httptest.NewRecorder()
+	NewResponseWriter(second).WriteCacheableJSON(r, result, time.Minute)
+
+	if second.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want %d", second.Code, http.StatusNotModified)
+	}
+	if second.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", second.Body.String())
+	}
+}
@@ -0,0 +1,13 @@
+// Package version holds build information injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/Yuki-TU/elastic-search/api/pkg/version.Version=1.2.3 -X github.com/Yuki-TU/elastic-search/api/pkg/version.Commit=$(git rev-parse --short HEAD)"
+package version
+
+var (
+	// Version is the service version
+	Version = "1.0.0"
+	// Commit is the VCS revision the binary was built from
+	Commit = "unknown"
+	// BuildTime is the time the binary was built
+	BuildTime = "unknown"
+)